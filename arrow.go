@@ -0,0 +1,569 @@
+package bonobo
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/substrait-io/substrait-go/v3/types"
+)
+
+// ArrowTypeHintKey is the key of the Arrow field metadata that records the
+// Substrait type of a field when its Arrow type alone is ambiguous. ToArrow
+// sets it for fixedchar, varchar and the timestamp and interval types whose
+// precision has no Arrow equivalent, and SchemaFromArrow reads it, so that
+// these types survive a round trip through Arrow.
+const ArrowTypeHintKey = "bonobo:substrait_type"
+
+const (
+	arrowExtensionNameKey = "ARROW:extension:name"
+	arrowUUIDExtension    = "arrow.uuid"
+)
+
+// Dictionary encodings and large offsets have no Substrait equivalent, so
+// they are recorded in the type variation reference of the Substrait type of
+// the values they store. The type carries them into schemas built from its
+// fields and the schemas of relations that pass it through. The references are
+// reserved by bonobo rather than declared by plans, so TypeToProto drops them.
+const (
+	arrowVariationMask       uint32 = 0xffff0000
+	arrowVariationBase       uint32 = 0xb0b00000
+	arrowVariationLarge      uint32 = 1 << 0
+	arrowVariationDictionary uint32 = 1 << 1
+	arrowVariationOrdered    uint32 = 1 << 2
+
+	// arrowVariationIndexShift is the offset of the bits holding the Arrow
+	// type ID of the index type of a dictionary
+	arrowVariationIndexShift = 8
+)
+
+var arrowUUIDStorage = &arrow.FixedSizeBinaryType{ByteWidth: 16}
+
+// SchemaFromArrow converts an Arrow schema to Substrait types, which ToArrow
+// converts back to the same Arrow schema. Dictionary encodings and large
+// offsets are recorded in the type variations of the Substrait types for
+// ToArrow to restore. A schema that would not survive the round trip returns
+// an error, such as one with run-end encoded, view or extension types other
+// than arrow.uuid, time zones other than UTC, 256-bit decimals, 32-bit times
+// or metadata that ToArrow does not set. SchemaFromArrowValues converts such
+// schemas instead.
+func SchemaFromArrow(schema *arrow.Schema) (*Schema, error) {
+	if schema.HasMetadata() {
+		return nil, fmt.Errorf("cannot represent arrow schema metadata in substrait")
+	}
+
+	s, err := SchemaFromArrowValues(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := s.ToArrow()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, f := range schema.Fields() {
+		back := converted.Field(i)
+		if !arrow.TypeEqual(f.Type, back.Type) {
+			return nil, fmt.Errorf("cannot represent arrow field %q of type %s in substrait, which converts back to %s", f.Name, f.Type, back.Type)
+		}
+		if !f.Equal(back) {
+			return nil, fmt.Errorf("cannot represent metadata of arrow field %q in substrait", f.Name)
+		}
+	}
+
+	return s, nil
+}
+
+// SchemaFromArrowValues converts an Arrow schema to a schema of the Substrait
+// types of its values. Dictionary encodings and large offsets are recorded
+// for ToArrow to restore as SchemaFromArrow does. Run-end encoded, view and
+// extension types are converted to the type of their values, timestamps with
+// any time zone to timestamps with a time zone, and metadata other than
+// ArrowTypeHintKey is ignored, so ToArrow may not return the same Arrow
+// schema. Types with no Substrait equivalent, such as unsigned integers,
+// return an error.
+func SchemaFromArrowValues(schema *arrow.Schema) (*Schema, error) {
+	fields := make([]Field, schema.NumFields())
+	for i, f := range schema.Fields() {
+		var err error
+		if fields[i], err = fieldFromArrow(f); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewSchema(fields), nil
+}
+
+// ToArrow converts the schema to Arrow. Substrait types without an Arrow type
+// of their own are stored as the closest Arrow type, with the Substrait type
+// recorded in the field metadata under ArrowTypeHintKey. The dictionary
+// encodings and large offsets recorded by SchemaFromArrow are restored. Map
+// keys must not be nullable, and user-defined types and other type variations
+// cannot be converted.
+func (s *Schema) ToArrow() (*arrow.Schema, error) {
+	fields := make([]arrow.Field, s.Len())
+	for i, f := range s.Fields() {
+		var err error
+		if fields[i], err = fieldToArrow(f); err != nil {
+			return nil, err
+		}
+	}
+
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// isArrowVariation reports whether ref is a type variation recording the
+// Arrow encoding of a type.
+func isArrowVariation(ref uint32) bool {
+	return ref&arrowVariationMask == arrowVariationBase
+}
+
+// withArrowVariation records the encoding in bits in the type variation of
+// typ, along with any encoding it already records.
+func withArrowVariation(typ Type, bits uint32) Type {
+	return withTypeVariation(typ, arrowVariationBase|typ.GetTypeVariationReference()|bits)
+}
+
+// withTypeVariation returns a copy of typ with the type variation ref. Every
+// Substrait type stores its variation in a TypeVariationRef field.
+func withTypeVariation(typ Type, ref uint32) Type {
+	switch t := typ.(type) {
+	case types.IntervalCompoundType:
+		return t.WithTypeVariationRef(ref)
+	case types.IntervalYearToMonthType:
+		return t.WithTypeVariationRef(ref)
+	}
+
+	out := reflect.New(reflect.TypeOf(typ).Elem())
+	out.Elem().Set(reflect.ValueOf(typ).Elem())
+	out.Elem().FieldByName("TypeVariationRef").SetUint(uint64(ref))
+	return out.Interface().(Type)
+}
+
+func fieldFromArrow(f arrow.Field) (Field, error) {
+	field, err := typeFromArrow(f.Type, f.Nullable, f.Metadata)
+	if err != nil {
+		return Field{}, fmt.Errorf("field %q: %w", f.Name, err)
+	}
+
+	field.Name = f.Name
+	return field, nil
+}
+
+// typeFromArrow converts dt to an unnamed field, whose NestedNames are the
+// names of any structs nested within dt.
+func typeFromArrow(dt arrow.DataType, nullable bool, md arrow.Metadata) (Field, error) {
+	switch dt := dt.(type) {
+	case *arrow.DictionaryType:
+		field, err := typeFromArrow(dt.ValueType, nullable, md)
+		if err != nil {
+			return Field{}, err
+		}
+
+		bits := arrowVariationDictionary | uint32(dt.IndexType.ID())<<arrowVariationIndexShift
+		if dt.Ordered {
+			bits |= arrowVariationOrdered
+		}
+		field.Type = withArrowVariation(field.Type, bits)
+		return field, nil
+	case *arrow.RunEndEncodedType:
+		return typeFromArrow(dt.Encoded(), nullable, md)
+	}
+
+	if hint, ok := md.GetValue(ArrowTypeHintKey); ok {
+		typ, err := typeFromArrowHint(hint, dt, nullable)
+		return Field{Type: typ}, err
+	}
+	if name, ok := md.GetValue(arrowExtensionNameKey); ok && name == arrowUUIDExtension && arrow.TypeEqual(dt, arrowUUIDStorage) {
+		return Field{Type: Types.UUIDType(nullable)}, nil
+	}
+
+	var typ Type
+	switch dt := dt.(type) {
+	case arrow.ExtensionType:
+		if dt.ExtensionName() == arrowUUIDExtension && arrow.TypeEqual(dt.StorageType(), arrowUUIDStorage) {
+			return Field{Type: Types.UUIDType(nullable)}, nil
+		}
+		return typeFromArrow(dt.StorageType(), nullable, arrow.Metadata{})
+	case *arrow.BooleanType:
+		typ = Types.BooleanType(nullable)
+	case *arrow.Int8Type:
+		typ = Types.Int8Type(nullable)
+	case *arrow.Int16Type:
+		typ = Types.Int16Type(nullable)
+	case *arrow.Int32Type:
+		typ = Types.Int32Type(nullable)
+	case *arrow.Int64Type:
+		typ = Types.Int64Type(nullable)
+	case *arrow.Float32Type:
+		typ = Types.FloatType(nullable)
+	case *arrow.Float64Type:
+		typ = Types.DoubleType(nullable)
+	case *arrow.StringType, *arrow.StringViewType:
+		typ = Types.StringType(nullable)
+	case *arrow.LargeStringType:
+		typ = withArrowVariation(Types.StringType(nullable), arrowVariationLarge)
+	case *arrow.BinaryType, *arrow.BinaryViewType:
+		typ = Types.BinaryType(nullable)
+	case *arrow.LargeBinaryType:
+		typ = withArrowVariation(Types.BinaryType(nullable), arrowVariationLarge)
+	case *arrow.FixedSizeBinaryType:
+		typ = Types.FixedBinaryType(int32(dt.ByteWidth), nullable)
+	case *arrow.Date32Type, *arrow.Date64Type:
+		typ = Types.DateType(nullable)
+	case *arrow.Time32Type:
+		typ = Types.TimeType(nullable)
+	case *arrow.Time64Type:
+		if dt.Unit == arrow.Nanosecond {
+			return Field{}, fmt.Errorf("cannot represent arrow type %s in substrait, whose times are in microseconds", dt)
+		}
+		typ = Types.TimeType(nullable)
+	case *arrow.TimestampType:
+		precision := arrowTimeUnitPrecision(dt.Unit)
+		if dt.TimeZone == "" {
+			typ = Types.PrecisionTimestampType(precision, nullable)
+		} else {
+			typ = Types.PrecisionTimestampTzType(precision, nullable)
+		}
+	case *arrow.MonthIntervalType:
+		typ = Types.IntervalYearType(nullable)
+	case *arrow.DayTimeIntervalType:
+		typ = Types.IntervalDayType(int32(types.PrecisionMilliSeconds), nullable)
+	case *arrow.MonthDayNanoIntervalType:
+		typ = Types.IntervalCompoundType(int32(types.PrecisionNanoSeconds), nullable)
+	case *arrow.Decimal128Type:
+		typ = Types.DecimalType(dt.Precision, dt.Scale, nullable)
+	case *arrow.Decimal256Type:
+		if dt.Precision > 38 {
+			return Field{}, fmt.Errorf("cannot represent arrow type %s in substrait, whose decimals have at most 38 digits", dt)
+		}
+		typ = Types.DecimalType(dt.Precision, dt.Scale, nullable)
+	case *arrow.StructType:
+		fields := make([]Field, dt.NumFields())
+		for i, f := range dt.Fields() {
+			var err error
+			if fields[i], err = fieldFromArrow(f); err != nil {
+				return Field{}, err
+			}
+		}
+
+		return NewStructField("", fields, nullable), nil
+	case *arrow.MapType:
+		key, err := fieldFromArrow(dt.KeyField())
+		if err != nil {
+			return Field{}, err
+		}
+		value, err := fieldFromArrow(dt.ItemField())
+		if err != nil {
+			return Field{}, err
+		}
+
+		return NewMapField("", key, value, nullable), nil
+	case arrow.ListLikeType:
+		elem, err := fieldFromArrow(dt.ElemField())
+		if err != nil {
+			return Field{}, err
+		}
+
+		field := NewListField("", elem, nullable)
+		if _, ok := dt.(*arrow.LargeListType); ok {
+			field.Type = withArrowVariation(field.Type, arrowVariationLarge)
+		}
+		return field, nil
+	default:
+		return Field{}, fmt.Errorf("cannot represent arrow type %s in substrait", dt)
+	}
+
+	return Field{Type: typ}, nil
+}
+
+// typeFromArrowHint parses a type recorded under ArrowTypeHintKey, which
+// must be stored as an Arrow type with the same values as dt.
+func typeFromArrowHint(hint string, dt arrow.DataType, nullable bool) (Type, error) {
+	name, param, hasParam := strings.Cut(hint, "<")
+	param, closed := strings.CutSuffix(param, ">")
+	n, err := strconv.ParseInt(param, 10, 32)
+	if hasParam != (closed && err == nil) || n < 0 {
+		return nil, fmt.Errorf("invalid %s metadata: %q", ArrowTypeHintKey, hint)
+	}
+	if strings.Contains(name, "timestamp") || strings.HasPrefix(name, "interval") {
+		if _, err := types.ProtoToTimePrecision(int32(n)); hasParam && err != nil {
+			return nil, fmt.Errorf("invalid %s metadata %q: %w", ArrowTypeHintKey, hint, err)
+		}
+	}
+
+	var typ Type
+	switch {
+	case name == "fixedchar" && hasParam:
+		typ = Types.FixedCharType(int32(n), nullable)
+	case name == "varchar" && hasParam:
+		typ = Types.VarCharType(int32(n), nullable)
+	case name == "timestamp" && !hasParam:
+		typ = Types.TimestampType(nullable)
+	case name == "timestamp_tz" && !hasParam:
+		typ = Types.TimestampTzType(nullable)
+	case name == "precision_timestamp" && hasParam:
+		typ = Types.PrecisionTimestampType(int32(n), nullable)
+	case name == "precision_timestamp_tz" && hasParam:
+		typ = Types.PrecisionTimestampTzType(int32(n), nullable)
+	case name == "interval_day" && hasParam:
+		typ = Types.IntervalDayType(int32(n), nullable)
+	case name == "interval_compound" && hasParam:
+		typ = Types.IntervalCompoundType(int32(n), nullable)
+	default:
+		return nil, fmt.Errorf("invalid %s metadata: %q", ArrowTypeHintKey, hint)
+	}
+
+	// The hint only names a Substrait type, so the Arrow type it is stored as
+	// must be one that converts to the same type as the hinted one would.
+	want, _, err := typeToArrow(Field{Type: typ})
+	if err != nil {
+		return nil, err
+	}
+	found, err := typeFromArrow(dt, nullable, arrow.Metadata{})
+	if err != nil {
+		return nil, err
+	}
+	expected, err := typeFromArrow(want, nullable, arrow.Metadata{})
+	if err != nil {
+		return nil, err
+	}
+	if !found.Type.Equals(expected.Type) {
+		return nil, fmt.Errorf("%s metadata %q does not match arrow type %s", ArrowTypeHintKey, hint, dt)
+	}
+
+	return typ, nil
+}
+
+func fieldToArrow(f Field) (arrow.Field, error) {
+	dt, md, err := typeToArrow(f)
+	if err != nil {
+		return arrow.Field{}, fmt.Errorf("field %q: %w", f.Name, err)
+	}
+
+	return arrow.Field{
+		Name:     f.Name,
+		Type:     dt,
+		Nullable: f.Type.GetNullability() != types.NullabilityRequired,
+		Metadata: md,
+	}, nil
+}
+
+// typeToArrow converts the type of f, naming the fields of any structs nested
+// within it by the NestedNames of f. The metadata records the Substrait type
+// when the Arrow type does not determine it, and the Arrow encoding recorded
+// in the type variation of f is restored.
+func typeToArrow(f Field) (arrow.DataType, arrow.Metadata, error) {
+	ref := f.Type.GetTypeVariationReference()
+	if ref == 0 {
+		return typeValuesToArrow(f)
+	}
+	if !isArrowVariation(ref) {
+		return nil, arrow.Metadata{}, fmt.Errorf("cannot represent type variation %d of %s in arrow", ref, f.Type)
+	}
+
+	dt, md, err := typeValuesToArrow(Field{Name: f.Name, Type: withTypeVariation(f.Type, 0), NestedNames: f.NestedNames})
+	if err != nil {
+		return nil, arrow.Metadata{}, err
+	}
+
+	if ref&arrowVariationLarge != 0 {
+		switch t := dt.(type) {
+		case *arrow.StringType:
+			dt = arrow.BinaryTypes.LargeString
+		case *arrow.BinaryType:
+			dt = arrow.BinaryTypes.LargeBinary
+		case *arrow.ListType:
+			dt = arrow.LargeListOfField(t.ElemField())
+		default:
+			return nil, arrow.Metadata{}, fmt.Errorf("cannot store %s with large offsets in arrow", f.Type)
+		}
+	}
+	if ref&arrowVariationDictionary != 0 {
+		index, ok := arrowDictionaryIndexTypes[arrow.Type(ref>>arrowVariationIndexShift&0xff)]
+		if !ok {
+			return nil, arrow.Metadata{}, fmt.Errorf("invalid arrow dictionary index type in type variation %d of %s", ref, f.Type)
+		}
+		dt = &arrow.DictionaryType{IndexType: index, ValueType: dt, Ordered: ref&arrowVariationOrdered != 0}
+	}
+
+	return dt, md, nil
+}
+
+var arrowDictionaryIndexTypes = map[arrow.Type]arrow.DataType{
+	arrow.INT8:   arrow.PrimitiveTypes.Int8,
+	arrow.INT16:  arrow.PrimitiveTypes.Int16,
+	arrow.INT32:  arrow.PrimitiveTypes.Int32,
+	arrow.INT64:  arrow.PrimitiveTypes.Int64,
+	arrow.UINT8:  arrow.PrimitiveTypes.Uint8,
+	arrow.UINT16: arrow.PrimitiveTypes.Uint16,
+	arrow.UINT32: arrow.PrimitiveTypes.Uint32,
+	arrow.UINT64: arrow.PrimitiveTypes.Uint64,
+}
+
+// typeValuesToArrow converts the type of f, which has no type variation, to
+// the Arrow type of its values.
+func typeValuesToArrow(f Field) (arrow.DataType, arrow.Metadata, error) {
+
+	switch t := f.Type.(type) {
+	case *types.BooleanType:
+		return arrow.FixedWidthTypes.Boolean, arrow.Metadata{}, nil
+	case *types.Int8Type:
+		return arrow.PrimitiveTypes.Int8, arrow.Metadata{}, nil
+	case *types.Int16Type:
+		return arrow.PrimitiveTypes.Int16, arrow.Metadata{}, nil
+	case *types.Int32Type:
+		return arrow.PrimitiveTypes.Int32, arrow.Metadata{}, nil
+	case *types.Int64Type:
+		return arrow.PrimitiveTypes.Int64, arrow.Metadata{}, nil
+	case *types.Float32Type:
+		return arrow.PrimitiveTypes.Float32, arrow.Metadata{}, nil
+	case *types.Float64Type:
+		return arrow.PrimitiveTypes.Float64, arrow.Metadata{}, nil
+	case *types.StringType:
+		return arrow.BinaryTypes.String, arrow.Metadata{}, nil
+	case *types.BinaryType:
+		return arrow.BinaryTypes.Binary, arrow.Metadata{}, nil
+	case *types.FixedBinaryType:
+		return &arrow.FixedSizeBinaryType{ByteWidth: int(t.Length)}, arrow.Metadata{}, nil
+	case *types.FixedCharType:
+		return arrow.BinaryTypes.String, arrowTypeHint("fixedchar<%d>", t.Length), nil
+	case *types.VarCharType:
+		return arrow.BinaryTypes.String, arrowTypeHint("varchar<%d>", t.Length), nil
+	case *types.DecimalType:
+		return &arrow.Decimal128Type{Precision: t.Precision, Scale: t.Scale}, arrow.Metadata{}, nil
+	case *types.UUIDType:
+		md := arrow.NewMetadata([]string{arrowExtensionNameKey}, []string{arrowUUIDExtension})
+		return arrowUUIDStorage, md, nil
+	case *types.DateType:
+		return arrow.FixedWidthTypes.Date32, arrow.Metadata{}, nil
+	case *types.TimeType:
+		return arrow.FixedWidthTypes.Time64us, arrow.Metadata{}, nil
+	case *types.TimestampType:
+		return &arrow.TimestampType{Unit: arrow.Microsecond}, arrowTypeHint("timestamp"), nil
+	case *types.TimestampTzType:
+		return &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}, arrowTypeHint("timestamp_tz"), nil
+	case *types.PrecisionTimestampType:
+		if t.Precision > types.PrecisionNanoSeconds {
+			return nil, arrow.Metadata{}, fmt.Errorf("%s cannot be represented in Arrow", t)
+		}
+		unit, exact := arrowTimeUnit(t.Precision)
+		if !exact {
+			return &arrow.TimestampType{Unit: unit}, arrowTypeHint("precision_timestamp<%d>", t.Precision), nil
+		}
+		return &arrow.TimestampType{Unit: unit}, arrow.Metadata{}, nil
+	case *types.PrecisionTimestampTzType:
+		if t.Precision > types.PrecisionNanoSeconds {
+			return nil, arrow.Metadata{}, fmt.Errorf("%s cannot be represented in Arrow", t)
+		}
+		unit, exact := arrowTimeUnit(t.Precision)
+		if !exact {
+			return &arrow.TimestampType{Unit: unit, TimeZone: "UTC"}, arrowTypeHint("precision_timestamp_tz<%d>", t.Precision), nil
+		}
+		return &arrow.TimestampType{Unit: unit, TimeZone: "UTC"}, arrow.Metadata{}, nil
+	case *types.IntervalYearType, types.IntervalYearToMonthType:
+		return arrow.FixedWidthTypes.MonthInterval, arrow.Metadata{}, nil
+	case *types.IntervalDayType:
+		if t.Precision != types.PrecisionMilliSeconds {
+			return arrow.FixedWidthTypes.MonthDayNanoInterval, arrowTypeHint("interval_day<%d>", t.Precision), nil
+		}
+		return arrow.FixedWidthTypes.DayTimeInterval, arrow.Metadata{}, nil
+	case types.IntervalCompoundType:
+		if precision := t.GetPrecisionProtoVal(); precision != int32(types.PrecisionNanoSeconds) {
+			return arrow.FixedWidthTypes.MonthDayNanoInterval, arrowTypeHint("interval_compound<%d>", precision), nil
+		}
+		return arrow.FixedWidthTypes.MonthDayNanoInterval, arrow.Metadata{}, nil
+	case *types.StructType:
+		children, err := f.StructFields()
+		if err != nil {
+			return nil, arrow.Metadata{}, err
+		}
+
+		fields := make([]arrow.Field, len(children))
+		for i, child := range children {
+			if fields[i], err = fieldToArrow(child); err != nil {
+				return nil, arrow.Metadata{}, err
+			}
+		}
+
+		return arrow.StructOf(fields...), arrow.Metadata{}, nil
+	case *types.ListType:
+		elem, err := f.ListElement()
+		if err != nil {
+			return nil, arrow.Metadata{}, err
+		}
+		elem.Name = "item"
+
+		elemField, err := fieldToArrow(elem)
+		if err != nil {
+			return nil, arrow.Metadata{}, err
+		}
+
+		return arrow.ListOfField(elemField), arrow.Metadata{}, nil
+	case *types.MapType:
+		if t.Key.GetNullability() != types.NullabilityRequired {
+			return nil, arrow.Metadata{}, fmt.Errorf("cannot represent map with nullable keys %s in arrow", t)
+		}
+
+		key, err := f.MapKey()
+		if err != nil {
+			return nil, arrow.Metadata{}, err
+		}
+		key.Name = "key"
+		keyField, err := fieldToArrow(key)
+		if err != nil {
+			return nil, arrow.Metadata{}, err
+		}
+
+		value, err := f.MapValue()
+		if err != nil {
+			return nil, arrow.Metadata{}, err
+		}
+		value.Name = "value"
+		valueField, err := fieldToArrow(value)
+		if err != nil {
+			return nil, arrow.Metadata{}, err
+		}
+
+		dt := arrow.MapOfWithMetadata(keyField.Type, keyField.Metadata, valueField.Type, valueField.Metadata)
+		dt.SetItemNullable(valueField.Nullable)
+		return dt, arrow.Metadata{}, nil
+	default:
+		return nil, arrow.Metadata{}, fmt.Errorf("cannot represent type %s in arrow", f.Type)
+	}
+}
+
+func arrowTypeHint(format string, args ...any) arrow.Metadata {
+	return arrow.NewMetadata([]string{ArrowTypeHintKey}, []string{fmt.Sprintf(format, args...)})
+}
+
+// arrowTimeUnit returns the coarsest Arrow time unit that can hold times of
+// precision p, and whether it has exactly that precision.
+func arrowTimeUnit(p types.TimePrecision) (arrow.TimeUnit, bool) {
+	switch {
+	case p <= types.PrecisionSeconds:
+		return arrow.Second, p == types.PrecisionSeconds
+	case p <= types.PrecisionMilliSeconds:
+		return arrow.Millisecond, p == types.PrecisionMilliSeconds
+	case p <= types.PrecisionMicroSeconds:
+		return arrow.Microsecond, p == types.PrecisionMicroSeconds
+	default:
+		return arrow.Nanosecond, p == types.PrecisionNanoSeconds
+	}
+}
+
+func arrowTimeUnitPrecision(unit arrow.TimeUnit) int32 {
+	switch unit {
+	case arrow.Second:
+		return int32(types.PrecisionSeconds)
+	case arrow.Millisecond:
+		return int32(types.PrecisionMilliSeconds)
+	case arrow.Microsecond:
+		return int32(types.PrecisionMicroSeconds)
+	default:
+		return int32(types.PrecisionNanoSeconds)
+	}
+}
@@ -0,0 +1,308 @@
+package bonobo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joellubi/bonobo"
+	"github.com/joellubi/bonobo/df"
+	"github.com/joellubi/bonobo/engine"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestSchemaArrowRoundTrip(t *testing.T) {
+	schema := testEventsSchema()
+
+	arrowSchema, err := schema.ToArrow()
+	require.NoError(t, err)
+
+	label, _ := arrowSchema.FieldsByName("label")
+	require.Equal(t, arrow.BinaryTypes.String, label[0].Type)
+	hint, _ := label[0].Metadata.GetValue(bonobo.ArrowTypeHintKey)
+	require.Equal(t, "varchar<64>", hint)
+
+	roundTripped, err := bonobo.SchemaFromArrow(arrowSchema)
+	require.NoError(t, err)
+	require.Equal(t, schema, roundTripped)
+
+	arrowRoundTripped, err := roundTripped.ToArrow()
+	require.NoError(t, err)
+	require.True(t, arrowSchema.Equal(arrowRoundTripped))
+}
+
+// temperatureType is an Arrow extension type that is not registered, which
+// stores temperatures in the unit it is serialized as.
+type temperatureType struct {
+	arrow.ExtensionBase
+	unit string
+}
+
+type temperatureArray struct {
+	array.ExtensionArrayBase
+}
+
+func (t *temperatureType) ArrayType() reflect.Type { return reflect.TypeOf(temperatureArray{}) }
+func (t *temperatureType) ExtensionName() string   { return "example.temperature" }
+func (t *temperatureType) Serialize() string       { return t.unit }
+
+func (t *temperatureType) Deserialize(storage arrow.DataType, data string) (arrow.ExtensionType, error) {
+	return &temperatureType{ExtensionBase: arrow.ExtensionBase{Storage: storage}, unit: data}, nil
+}
+
+func (t *temperatureType) ExtensionEquals(other arrow.ExtensionType) bool {
+	o, ok := other.(*temperatureType)
+	return ok && o.unit == t.unit && arrow.TypeEqual(o.Storage, t.Storage)
+}
+
+func TestSchemaFromArrowValues(t *testing.T) {
+	temperature := &temperatureType{ExtensionBase: arrow.ExtensionBase{Storage: arrow.PrimitiveTypes.Float64}, unit: "celsius"}
+
+	schema, err := bonobo.SchemaFromArrowValues(arrow.NewSchema(
+		[]arrow.Field{
+			{
+				Name: "category",
+				Type: &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String},
+			},
+			{Name: "name", Type: arrow.BinaryTypes.LargeString, Nullable: true},
+			{Name: "at_ts", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "America/New_York"}},
+			{Name: "scores", Type: arrow.LargeListOf(arrow.PrimitiveTypes.Int64)},
+			{
+				Name:     "amount",
+				Type:     &arrow.Decimal256Type{Precision: 20, Scale: 4},
+				Nullable: true,
+				Metadata: arrow.NewMetadata([]string{"currency"}, []string{"EUR"}),
+			},
+			{
+				Name:     "code",
+				Type:     arrow.BinaryTypes.StringView,
+				Metadata: arrow.NewMetadata([]string{bonobo.ArrowTypeHintKey}, []string{"fixedchar<3>"}),
+			},
+			{Name: "temperature", Type: temperature, Nullable: true},
+		},
+		nil,
+	))
+	require.NoError(t, err)
+	// Schemas are compared as strings, which leave out the type variations
+	// recording the dictionary encoding and large offsets
+	require.Equal(
+		t,
+		bonobo.NewSchema([]bonobo.Field{
+			{Name: "category", Type: bonobo.Types.StringType(false)},
+			{Name: "name", Type: bonobo.Types.StringType(true)},
+			{Name: "at_ts", Type: bonobo.Types.PrecisionTimestampTzType(3, false)},
+			bonobo.NewListField("scores", bonobo.Field{Type: bonobo.Types.Int64Type(true)}, false),
+			{Name: "amount", Type: bonobo.Types.DecimalType(20, 4, true)},
+			{Name: "code", Type: bonobo.Types.FixedCharType(3, false)},
+			{Name: "temperature", Type: bonobo.Types.DoubleType(true)},
+		}).String(),
+		schema.String(),
+	)
+
+	// The dictionary encoding and large offsets are restored
+	arrowSchema, err := schema.ToArrow()
+	require.NoError(t, err)
+	require.Equal(t, "dictionary<values=utf8, indices=int32, ordered=false>", arrowSchema.Field(0).Type.String())
+	require.Equal(t, arrow.BinaryTypes.LargeString, arrowSchema.Field(1).Type)
+	require.Equal(t, "large_list<item: int64, nullable>", arrowSchema.Field(3).Type.String())
+	require.Equal(t, arrow.PrimitiveTypes.Float64, arrowSchema.Field(6).Type)
+}
+
+// testArrowEncodingsSchema has dictionary encoded and large types at the top
+// level and nested in other types.
+func testArrowEncodingsSchema() *arrow.Schema {
+	return arrow.NewSchema(
+		[]arrow.Field{
+			{
+				Name: "category",
+				Type: &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int8, ValueType: arrow.BinaryTypes.String, Ordered: true},
+			},
+			{
+				Name:     "large_category",
+				Type:     &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Uint16, ValueType: arrow.BinaryTypes.LargeString},
+				Nullable: true,
+			},
+			{Name: "name", Type: arrow.BinaryTypes.LargeString, Nullable: true},
+			{Name: "payload", Type: arrow.BinaryTypes.LargeBinary},
+			{Name: "scores", Type: arrow.LargeListOf(arrow.PrimitiveTypes.Int64)},
+			{
+				Name: "tags",
+				Type: arrow.LargeListOf(&arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String}),
+			},
+			{
+				Name: "location",
+				Type: arrow.StructOf(
+					arrow.Field{Name: "city", Type: arrow.BinaryTypes.LargeString},
+					arrow.Field{Name: "zone", Type: &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int16, ValueType: arrow.PrimitiveTypes.Int32}},
+				),
+			},
+		},
+		nil,
+	)
+}
+
+func TestSchemaArrowEncodingsRoundTrip(t *testing.T) {
+	arrowSchema := testArrowEncodingsSchema()
+
+	schema, err := bonobo.SchemaFromArrow(arrowSchema)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		bonobo.NewSchema([]bonobo.Field{
+			{Name: "category", Type: bonobo.Types.StringType(false)},
+			{Name: "large_category", Type: bonobo.Types.StringType(true)},
+			{Name: "name", Type: bonobo.Types.StringType(true)},
+			{Name: "payload", Type: bonobo.Types.BinaryType(false)},
+			bonobo.NewListField("scores", bonobo.Field{Type: bonobo.Types.Int64Type(true)}, false),
+			bonobo.NewListField("tags", bonobo.Field{Type: bonobo.Types.StringType(true)}, false),
+			bonobo.NewStructField("location", []bonobo.Field{
+				{Name: "city", Type: bonobo.Types.StringType(false)},
+				{Name: "zone", Type: bonobo.Types.Int32Type(false)},
+			}, false),
+		}).String(),
+		schema.String(),
+	)
+
+	arrowRoundTripped, err := schema.ToArrow()
+	require.NoError(t, err)
+	require.True(t, arrowSchema.Equal(arrowRoundTripped), "expected: %s\nfound: %s", arrowSchema, arrowRoundTripped)
+
+	// The encodings are part of the Substrait types, so they are kept by a
+	// schema built from the fields
+	arrowRoundTripped, err = bonobo.NewSchema(schema.Fields()).ToArrow()
+	require.NoError(t, err)
+	require.True(t, arrowSchema.Equal(arrowRoundTripped), "expected: %s\nfound: %s", arrowSchema, arrowRoundTripped)
+}
+
+// arrowCatalog has a single table with the schema converted from Arrow.
+type arrowCatalog struct {
+	schema *bonobo.Schema
+}
+
+func (c *arrowCatalog) Schema(identifier engine.Identifier) (*bonobo.Schema, error) {
+	return c.schema, nil
+}
+
+func TestSchemaArrowEncodingsPlan(t *testing.T) {
+	arrowSchema := testArrowEncodingsSchema()
+
+	schema, err := bonobo.SchemaFromArrow(arrowSchema)
+	require.NoError(t, err)
+
+	frame := df.QueryContext().
+		Read(engine.NewNamedTable([]string{"flight", "readings"}, &arrowCatalog{schema: schema})).
+		Sort(df.Asc(df.Col("name"))).
+		Select(df.Col("location"), df.Col("category"), df.Col("tags"))
+
+	// Relations that pass columns through keep their encodings
+	planSchema, err := frame.Schema()
+	require.NoError(t, err)
+	planArrowSchema, err := planSchema.ToArrow()
+	require.NoError(t, err)
+	expected := arrow.NewSchema([]arrow.Field{arrowSchema.Field(6), arrowSchema.Field(0), arrowSchema.Field(5)}, nil)
+	require.True(t, expected.Equal(planArrowSchema), "expected: %s\nfound: %s", expected, planArrowSchema)
+
+	// Plans do not declare the variations recording the encodings, so they
+	// are left out of serialized types
+	planProto, err := engine.NewPlan(frame.LogicalPlan()).ToProto()
+	require.NoError(t, err)
+	planJSON, err := protojson.Marshal(planProto)
+	require.NoError(t, err)
+	require.NotContains(t, string(planJSON), "typeVariationReference")
+}
+
+func TestSchemaFromArrow(t *testing.T) {
+	temperature := &temperatureType{ExtensionBase: arrow.ExtensionBase{Storage: arrow.PrimitiveTypes.Float64}, unit: "celsius"}
+
+	for _, tc := range []struct {
+		field arrow.Field
+		err   string
+	}{
+		{arrow.Field{Name: "a", Type: arrow.PrimitiveTypes.Uint32}, `field "a": cannot represent arrow type uint32`},
+		{arrow.Field{Name: "a", Type: arrow.FixedWidthTypes.Time64ns}, "times are in microseconds"},
+		{arrow.Field{Name: "a", Type: &arrow.Decimal256Type{Precision: 50}}, "at most 38 digits"},
+		{
+			arrow.Field{Name: "a", Type: arrow.StructOf(arrow.Field{Name: "b", Type: arrow.Null})},
+			`field "a": field "b": cannot represent arrow type null`,
+		},
+		{
+			arrow.Field{
+				Name:     "a",
+				Type:     arrow.PrimitiveTypes.Int32,
+				Metadata: arrow.NewMetadata([]string{bonobo.ArrowTypeHintKey}, []string{"varchar<10>"}),
+			},
+			"does not match arrow type int32",
+		},
+		{
+			arrow.Field{
+				Name:     "a",
+				Type:     arrow.BinaryTypes.String,
+				Metadata: arrow.NewMetadata([]string{bonobo.ArrowTypeHintKey}, []string{"varchar"}),
+			},
+			"invalid bonobo:substrait_type metadata",
+		},
+		{
+			arrow.Field{Name: "a", Type: arrow.RunEndEncodedOf(arrow.PrimitiveTypes.Int32, arrow.PrimitiveTypes.Int64)},
+			"which converts back to int64",
+		},
+		{arrow.Field{Name: "a", Type: arrow.BinaryTypes.BinaryView}, "which converts back to binary"},
+		{
+			arrow.Field{Name: "a", Type: arrow.ListViewOf(arrow.PrimitiveTypes.Int64)},
+			`cannot represent arrow field "a" of type list_view<item: int64, nullable> in substrait, which converts back to list<item: int64, nullable>`,
+		},
+		{
+			arrow.Field{Name: "a", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "America/New_York"}},
+			"which converts back to timestamp[ms, tz=UTC]",
+		},
+		{arrow.Field{Name: "a", Type: &arrow.Decimal256Type{Precision: 20, Scale: 4}}, "which converts back to decimal(20, 4)"},
+		{arrow.Field{Name: "a", Type: arrow.FixedWidthTypes.Time32ms}, "which converts back to time64[us]"},
+		{arrow.Field{Name: "a", Type: arrow.FixedWidthTypes.Date64}, "which converts back to date32"},
+		{arrow.Field{Name: "a", Type: temperature}, "which converts back to float64"},
+		{
+			arrow.Field{
+				Name: "a",
+				Type: arrow.PrimitiveTypes.Int64,
+				Metadata: arrow.NewMetadata(
+					[]string{"ARROW:extension:name", "ARROW:extension:metadata"},
+					[]string{"example.distance", "meters"},
+				),
+			},
+			`cannot represent metadata of arrow field "a" in substrait`,
+		},
+		{
+			arrow.Field{
+				Name:     "a",
+				Type:     &arrow.Decimal128Type{Precision: 10, Scale: 2},
+				Metadata: arrow.NewMetadata([]string{"currency"}, []string{"EUR"}),
+			},
+			`cannot represent metadata of arrow field "a" in substrait`,
+		},
+	} {
+		_, err := bonobo.SchemaFromArrow(arrow.NewSchema([]arrow.Field{tc.field}, nil))
+		require.ErrorContains(t, err, tc.err, tc.field.String())
+	}
+
+	md := arrow.NewMetadata([]string{"origin"}, []string{"flight"})
+	_, err := bonobo.SchemaFromArrow(arrow.NewSchema([]arrow.Field{{Name: "a", Type: arrow.PrimitiveTypes.Int64}}, &md))
+	require.ErrorContains(t, err, "cannot represent arrow schema metadata in substrait")
+}
+
+func TestSchemaToArrowInvalid(t *testing.T) {
+	_, err := bonobo.NewSchema([]bonobo.Field{
+		bonobo.NewMapField(
+			"attributes",
+			bonobo.Field{Type: bonobo.Types.StringType(true)},
+			bonobo.Field{Type: bonobo.Types.Int64Type(true)},
+			false,
+		),
+	}).ToArrow()
+	require.ErrorContains(t, err, `field "attributes": cannot represent map with nullable keys`)
+
+	_, err = bonobo.NewSchema([]bonobo.Field{
+		{Name: "point", Type: bonobo.Types.UserDefinedType(1, nil, false)},
+	}).ToArrow()
+	require.ErrorContains(t, err, `field "point": cannot represent type`)
+}
@@ -2,6 +2,7 @@ package bonobo
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/substrait-io/substrait-go/v3/proto"
 	"github.com/substrait-io/substrait-go/v3/types"
@@ -12,6 +13,11 @@ type Type types.Type
 type Field struct {
 	Name string
 	Type Type
+
+	// NestedNames are the names of the fields of any structs nested within
+	// Type, in depth-first order. The fields are named by position unless
+	// there is a name for each of them.
+	NestedNames []string
 }
 
 func (f Field) String() string {
@@ -20,13 +26,11 @@ func (f Field) String() string {
 
 type Schema types.NamedStruct
 
+// NewSchema creates a schema with the provided top-level fields. The fields of
+// nested structs are named by the NestedNames of each field, or f0, f1, ... by
+// position if they are not provided.
 func NewSchema(fields []Field) *Schema {
-	fieldNames := make([]string, len(fields))
-	fieldTypes := make([]types.Type, len(fields))
-	for i, field := range fields {
-		fieldNames[i] = field.Name
-		fieldTypes[i] = field.Type
-	}
+	fieldNames, fieldTypes := flattenFields(fields)
 
 	return &Schema{
 		Names: fieldNames,
@@ -37,22 +41,77 @@ func NewSchema(fields []Field) *Schema {
 	}
 }
 
-func NewSchemaFromProto(n *proto.NamedStruct) *Schema {
-	schema := Schema(types.NewNamedStructFromProto(n))
-	return &schema
+// NewSchemaFromProto deserializes the schema n.
+func NewSchemaFromProto(n *proto.NamedStruct) (*Schema, error) {
+	typ, err := TypeFromProto(&proto.Type{Kind: &proto.Type_Struct_{Struct: n.GetStruct()}})
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{Names: n.GetNames(), Struct: *typ.(*types.StructType)}
+	if len(schema.Names) != countNestedNames(&schema.Struct) {
+		return nil, fmt.Errorf("schema has %d names for %d fields", len(schema.Names), countNestedNames(&schema.Struct))
+	}
+
+	return schema, nil
 }
 
-func (s *Schema) Fields() []Field {
-	fields := make([]Field, len(s.Names))
-	for i := range fields {
-		typ := s.Struct.Types[i]
-		fields[i] = Field{Name: s.Names[i], Type: typ}
+// ToProto serializes the schema.
+func (s *Schema) ToProto() (*proto.NamedStruct, error) {
+	typ, err := TypeToProto(&s.Struct)
+	if err != nil {
+		return nil, err
 	}
-	return fields
+
+	return &proto.NamedStruct{Names: s.Names, Struct: typ.GetStruct()}, nil
+}
+
+// Fields returns the top-level fields of the schema. Names contains the names
+// of nested struct fields as well, in depth-first order, which are assigned to
+// the NestedNames of the top-level field they are nested in.
+func (s *Schema) Fields() []Field {
+	return splitFields(s.Names, s.Struct.Types)
 }
 
 func (s *Schema) Len() int {
-	return len(s.Names)
+	return len(s.Struct.Types)
+}
+
+// FieldIndex finds the index of the top-level field with the provided name.
+func (s *Schema) FieldIndex(name string) (int, error) {
+	for i, field := range s.Fields() {
+		if field.Name == name {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("schema does not contain field: %s", name)
+}
+
+// FieldByPath finds the field at path, where the first name is a top-level
+// field of the schema and each name after it is a field of the struct
+// before it, like a.b.c.
+func (s *Schema) FieldByPath(path ...string) (Field, error) {
+	if len(path) == 0 {
+		return Field{}, fmt.Errorf("empty field path")
+	}
+
+	index, err := s.FieldIndex(path[0])
+	if err != nil {
+		return Field{}, err
+	}
+
+	field := s.Fields()[index]
+	for _, name := range path[1:] {
+		if index, err = field.StructFieldIndex(name); err != nil {
+			return Field{}, err
+		}
+		if field, err = field.StructField(index); err != nil {
+			return Field{}, err
+		}
+	}
+
+	return field, nil
 }
 
 func (s *Schema) String() string {
@@ -60,16 +119,33 @@ func (s *Schema) String() string {
 }
 
 var Types = struct {
-	BooleanType func(nullable bool) Type
-	Int8Type    func(nullable bool) Type
-	Int16Type   func(nullable bool) Type
-	Int32Type   func(nullable bool) Type
-	Int64Type   func(nullable bool) Type
-	FloatType   func(nullable bool) Type
-	DoubleType  func(nullable bool) Type
-	DateType    func(nullable bool) Type
-	StringType  func(nullable bool) Type
-	DecimalType func(p, s int32, nullable bool) Type
+	BooleanType              func(nullable bool) Type
+	Int8Type                 func(nullable bool) Type
+	Int16Type                func(nullable bool) Type
+	Int32Type                func(nullable bool) Type
+	Int64Type                func(nullable bool) Type
+	FloatType                func(nullable bool) Type
+	DoubleType               func(nullable bool) Type
+	DateType                 func(nullable bool) Type
+	TimeType                 func(nullable bool) Type
+	TimestampType            func(nullable bool) Type
+	TimestampTzType          func(nullable bool) Type
+	PrecisionTimestampType   func(precision int32, nullable bool) Type
+	PrecisionTimestampTzType func(precision int32, nullable bool) Type
+	IntervalYearType         func(nullable bool) Type
+	IntervalDayType          func(precision int32, nullable bool) Type
+	IntervalCompoundType     func(precision int32, nullable bool) Type
+	UUIDType                 func(nullable bool) Type
+	StringType               func(nullable bool) Type
+	BinaryType               func(nullable bool) Type
+	FixedBinaryType          func(length int32, nullable bool) Type
+	FixedCharType            func(length int32, nullable bool) Type
+	VarCharType              func(length int32, nullable bool) Type
+	DecimalType              func(p, s int32, nullable bool) Type
+	ListType                 func(elem Type, nullable bool) Type
+	MapType                  func(key, value Type, nullable bool) Type
+	StructType               func(fields []Type, nullable bool) Type
+	UserDefinedType          func(typeReference uint32, params []types.TypeParam, nullable bool) Type
 }{
 	BooleanType: func(nullable bool) Type {
 		return withNullability(&types.BooleanType{}, nullable)
@@ -95,12 +171,239 @@ var Types = struct {
 	DateType: func(nullable bool) Type {
 		return withNullability(&types.DateType{}, nullable)
 	},
+	TimeType: func(nullable bool) Type {
+		return withNullability(&types.TimeType{}, nullable)
+	},
+	TimestampType: func(nullable bool) Type {
+		return withNullability(&types.TimestampType{}, nullable)
+	},
+	TimestampTzType: func(nullable bool) Type {
+		return withNullability(&types.TimestampTzType{}, nullable)
+	},
+	PrecisionTimestampType: func(precision int32, nullable bool) Type {
+		return withNullability(&types.PrecisionTimestampType{Precision: types.TimePrecision(precision)}, nullable)
+	},
+	PrecisionTimestampTzType: func(precision int32, nullable bool) Type {
+		return withNullability(types.NewPrecisionTimestampTzType(types.TimePrecision(precision)), nullable)
+	},
+	IntervalYearType: func(nullable bool) Type {
+		return withNullability(&types.IntervalYearType{}, nullable)
+	},
+	IntervalDayType: func(precision int32, nullable bool) Type {
+		return withNullability(&types.IntervalDayType{Precision: types.TimePrecision(precision)}, nullable)
+	},
+	IntervalCompoundType: func(precision int32, nullable bool) Type {
+		return withNullability(types.NewIntervalCompoundType().WithPrecision(types.TimePrecision(precision)), nullable)
+	},
+	UUIDType: func(nullable bool) Type {
+		return withNullability(&types.UUIDType{}, nullable)
+	},
 	StringType: func(nullable bool) Type {
 		return withNullability(&types.StringType{}, nullable)
 	},
+	BinaryType: func(nullable bool) Type {
+		return withNullability(&types.BinaryType{}, nullable)
+	},
+	FixedBinaryType: func(length int32, nullable bool) Type {
+		return withNullability(&types.FixedBinaryType{Length: length}, nullable)
+	},
+	FixedCharType: func(length int32, nullable bool) Type {
+		return withNullability(&types.FixedCharType{Length: length}, nullable)
+	},
+	VarCharType: func(length int32, nullable bool) Type {
+		return withNullability(&types.VarCharType{Length: length}, nullable)
+	},
 	DecimalType: func(p, s int32, nullable bool) Type {
 		return withNullability(&types.DecimalType{Precision: p, Scale: s}, nullable)
 	},
+	ListType: func(elem Type, nullable bool) Type {
+		return withNullability(&types.ListType{Type: elem}, nullable)
+	},
+	MapType: func(key, value Type, nullable bool) Type {
+		return withNullability(&types.MapType{Key: key, Value: value}, nullable)
+	},
+	StructType: func(fields []Type, nullable bool) Type {
+		typs := make([]types.Type, len(fields))
+		for i, field := range fields {
+			typs[i] = field
+		}
+		return withNullability(&types.StructType{Types: typs}, nullable)
+	},
+	// The type reference is the anchor of a type declared in the extensions
+	// of the plan.
+	UserDefinedType: func(typeReference uint32, params []types.TypeParam, nullable bool) Type {
+		return withNullability(&types.UserDefinedType{TypeReference: typeReference, TypeParameters: params}, nullable)
+	},
+}
+
+// NewStructField creates a field of a struct with the provided fields. Their
+// names, and the names of any structs nested within them, become the
+// NestedNames of the field.
+func NewStructField(name string, fields []Field, nullable bool) Field {
+	names, typs := flattenFields(fields)
+	fieldTypes := make([]Type, len(typs))
+	for i, typ := range typs {
+		fieldTypes[i] = typ
+	}
+
+	return Field{
+		Name:        name,
+		Type:        Types.StructType(fieldTypes, nullable),
+		NestedNames: names,
+	}
+}
+
+// NewListField creates a field of a list of elem, whose name is ignored.
+func NewListField(name string, elem Field, nullable bool) Field {
+	return Field{
+		Name:        name,
+		Type:        Types.ListType(elem.Type, nullable),
+		NestedNames: elem.nestedNames(),
+	}
+}
+
+// NewMapField creates a field of a map from key to value, whose names are
+// ignored.
+func NewMapField(name string, key, value Field, nullable bool) Field {
+	return Field{
+		Name:        name,
+		Type:        Types.MapType(key.Type, value.Type, nullable),
+		NestedNames: append(slices.Clone(key.nestedNames()), value.nestedNames()...),
+	}
+}
+
+// StructFields returns the fields of a struct.
+func (f Field) StructFields() ([]Field, error) {
+	t, ok := f.Type.(*types.StructType)
+	if !ok {
+		return nil, fmt.Errorf("field %s is not a struct", f)
+	}
+
+	return splitFields(f.nestedNames(), t.Types), nil
+}
+
+// StructField returns the field at index i of a struct.
+func (f Field) StructField(i int) (Field, error) {
+	t, ok := f.Type.(*types.StructType)
+	if !ok {
+		return Field{}, fmt.Errorf("field %s is not a struct", f)
+	}
+	if i < 0 || i >= len(t.Types) {
+		return Field{}, fmt.Errorf("struct field index %d out of range for %s", i, f)
+	}
+
+	return splitFields(f.nestedNames(), t.Types)[i], nil
+}
+
+// StructFieldIndex finds the index of the field of a struct with the
+// provided name.
+func (f Field) StructFieldIndex(name string) (int, error) {
+	t, ok := f.Type.(*types.StructType)
+	if !ok {
+		return 0, fmt.Errorf("field %s is not a struct", f)
+	}
+
+	for i, child := range splitFields(f.nestedNames(), t.Types) {
+		if child.Name == name {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("struct %s does not contain field: %s", f, name)
+}
+
+// ListElement returns the field of the elements of a list, which is unnamed.
+func (f Field) ListElement() (Field, error) {
+	t, ok := f.Type.(*types.ListType)
+	if !ok {
+		return Field{}, fmt.Errorf("field %s is not a list", f)
+	}
+
+	return Field{Type: t.Type, NestedNames: f.nestedNames()}, nil
+}
+
+// MapKey returns the field of the keys of a map, which is unnamed.
+func (f Field) MapKey() (Field, error) {
+	t, ok := f.Type.(*types.MapType)
+	if !ok {
+		return Field{}, fmt.Errorf("field %s is not a map", f)
+	}
+
+	names := f.nestedNames()
+	return Field{Type: t.Key, NestedNames: names[:countNestedNames(t.Key)]}, nil
+}
+
+// MapValue returns the field of the values of a map, which is unnamed.
+func (f Field) MapValue() (Field, error) {
+	t, ok := f.Type.(*types.MapType)
+	if !ok {
+		return Field{}, fmt.Errorf("field %s is not a map", f)
+	}
+
+	names := f.nestedNames()
+	return Field{Type: t.Value, NestedNames: names[countNestedNames(t.Key):]}, nil
+}
+
+// nestedNames returns NestedNames if there is one for each field of the
+// structs nested within the type of f, or names by position otherwise.
+func (f Field) nestedNames() []string {
+	if len(f.NestedNames) == countNestedNames(f.Type) {
+		return f.NestedNames
+	}
+	return appendNestedNames(nil, f.Type)
+}
+
+// flattenFields returns the types of fields, and their names followed by the
+// names of the structs nested within them in the depth-first order of a
+// Substrait NamedStruct.
+func flattenFields(fields []Field) ([]string, []types.Type) {
+	names := make([]string, 0, len(fields))
+	typs := make([]types.Type, len(fields))
+	for i, field := range fields {
+		names = append(names, field.Name)
+		names = append(names, field.nestedNames()...)
+		typs[i] = field.Type
+	}
+	return names, typs
+}
+
+// splitFields pairs each of typs with its name in names, which are in the
+// depth-first order of a Substrait NamedStruct.
+func splitFields(names []string, typs []types.Type) []Field {
+	fields := make([]Field, len(typs))
+	nameIdx := 0
+	for i, typ := range typs {
+		n := countNestedNames(typ)
+		fields[i] = Field{
+			Name:        names[nameIdx],
+			Type:        typ,
+			NestedNames: names[nameIdx+1 : nameIdx+1+n],
+		}
+		nameIdx += 1 + n
+	}
+	return fields
+}
+
+func countNestedNames(typ Type) int {
+	return len(appendNestedNames(nil, typ))
+}
+
+// appendNestedNames appends a name for each field of the structs nested within
+// typ, in the depth-first order used by Substrait.
+func appendNestedNames(names []string, typ Type) []string {
+	switch t := typ.(type) {
+	case *types.StructType:
+		for i, child := range t.Types {
+			names = append(names, fmt.Sprintf("f%d", i))
+			names = appendNestedNames(names, child)
+		}
+	case *types.ListType:
+		names = appendNestedNames(names, t.Type)
+	case *types.MapType:
+		names = appendNestedNames(names, t.Key)
+		names = appendNestedNames(names, t.Value)
+	}
+	return names
 }
 
 func withNullability(typ Type, nullable bool) Type {
@@ -108,5 +411,204 @@ func withNullability(typ Type, nullable bool) Type {
 	if nullable {
 		nullability = types.NullabilityNullable
 	}
-	return typ.WithNullability(nullability)
+	return WithNullability(typ, nullability)
+}
+
+// TimestampPrecision converts the precision of a precision timestamp from
+// protobuf. Unlike types.ProtoToTimePrecision, it accepts precisions up to
+// picoseconds, as Substrait does for timestamps.
+func TimestampPrecision(p int32) (types.TimePrecision, error) {
+	if p < int32(types.PrecisionSeconds) || p > 12 {
+		return types.PrecisionUnknown, fmt.Errorf("invalid timestamp precision %d", p)
+	}
+	return types.TimePrecision(p), nil
+}
+
+// WithNullability returns a copy of typ with nullability n. Unlike the other
+// types, *types.IntervalDayType modifies itself in WithNullability.
+func WithNullability(typ Type, n types.Nullability) Type {
+	if t, ok := typ.(*types.IntervalDayType); ok {
+		out := *t
+		out.Nullability = n
+		return &out
+	}
+
+	return typ.WithNullability(n)
+}
+
+// TypeToProto serializes typ. Unlike types.TypeToProto, it supports precision
+// timestamps and compound intervals, including within nested types. The type
+// variations that record Arrow encodings are not serialized.
+func TypeToProto(typ Type) (*proto.Type, error) {
+	if typ != nil && isArrowVariation(typ.GetTypeVariationReference()) {
+		typ = withTypeVariation(typ, 0)
+	}
+
+	switch t := typ.(type) {
+	case nil:
+		return nil, fmt.Errorf("cannot serialize missing type")
+	case *types.StructType:
+		fields := make([]*proto.Type, len(t.Types))
+		for i, field := range t.Types {
+			var err error
+			if fields[i], err = TypeToProto(field); err != nil {
+				return nil, err
+			}
+		}
+
+		return &proto.Type{Kind: &proto.Type_Struct_{
+			Struct: &proto.Type_Struct{
+				Types:                  fields,
+				Nullability:            t.Nullability,
+				TypeVariationReference: t.TypeVariationRef,
+			},
+		}}, nil
+	case *types.ListType:
+		elem, err := TypeToProto(t.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		return &proto.Type{Kind: &proto.Type_List_{
+			List: &proto.Type_List{
+				Type:                   elem,
+				Nullability:            t.Nullability,
+				TypeVariationReference: t.TypeVariationRef,
+			},
+		}}, nil
+	case *types.MapType:
+		key, err := TypeToProto(t.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := TypeToProto(t.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return &proto.Type{Kind: &proto.Type_Map_{
+			Map: &proto.Type_Map{
+				Key:                    key,
+				Value:                  value,
+				Nullability:            t.Nullability,
+				TypeVariationReference: t.TypeVariationRef,
+			},
+		}}, nil
+	case *types.PrecisionTimestampType:
+		return t.ToProto(), nil
+	case *types.PrecisionTimestampTzType:
+		return t.ToProto(), nil
+	case types.IntervalCompoundType:
+		return t.ToProto(), nil
+	case types.IntervalYearToMonthType:
+		return t.ToProto(), nil
+	case *types.BooleanType, *types.Int8Type, *types.Int16Type, *types.Int32Type, *types.Int64Type,
+		*types.Float32Type, *types.Float64Type, *types.StringType, *types.BinaryType,
+		*types.DateType, *types.TimeType, *types.TimestampType, *types.TimestampTzType,
+		*types.IntervalYearType, *types.IntervalDayType, *types.UUIDType,
+		*types.FixedCharType, *types.VarCharType, *types.FixedBinaryType,
+		*types.DecimalType, *types.UserDefinedType:
+		return types.TypeToProto(t), nil
+	default:
+		return nil, fmt.Errorf("cannot serialize type %s", typ)
+	}
+}
+
+// TypeFromProto deserializes typ. Unlike types.TypeFromProto, it supports
+// precision timestamps and compound intervals, including within nested
+// types, and returns an error for types it does not recognize.
+func TypeFromProto(typ *proto.Type) (Type, error) {
+	switch t := typ.GetKind().(type) {
+	case nil:
+		return nil, fmt.Errorf("missing type")
+	case *proto.Type_Struct_:
+		fields := make([]types.Type, len(t.Struct.GetTypes()))
+		for i, field := range t.Struct.GetTypes() {
+			var err error
+			if fields[i], err = TypeFromProto(field); err != nil {
+				return nil, err
+			}
+		}
+
+		return &types.StructType{
+			Nullability:      t.Struct.GetNullability(),
+			TypeVariationRef: t.Struct.GetTypeVariationReference(),
+			Types:            fields,
+		}, nil
+	case *proto.Type_List_:
+		elem, err := TypeFromProto(t.List.GetType())
+		if err != nil {
+			return nil, err
+		}
+
+		return &types.ListType{
+			Nullability:      t.List.GetNullability(),
+			TypeVariationRef: t.List.GetTypeVariationReference(),
+			Type:             elem,
+		}, nil
+	case *proto.Type_Map_:
+		key, err := TypeFromProto(t.Map.GetKey())
+		if err != nil {
+			return nil, err
+		}
+		value, err := TypeFromProto(t.Map.GetValue())
+		if err != nil {
+			return nil, err
+		}
+
+		return &types.MapType{
+			Nullability:      t.Map.GetNullability(),
+			TypeVariationRef: t.Map.GetTypeVariationReference(),
+			Key:              key,
+			Value:            value,
+		}, nil
+	case *proto.Type_PrecisionTimestamp_:
+		precision, err := TimestampPrecision(t.PrecisionTimestamp.GetPrecision())
+		if err != nil {
+			return nil, err
+		}
+
+		return &types.PrecisionTimestampType{
+			Precision:        precision,
+			Nullability:      t.PrecisionTimestamp.GetNullability(),
+			TypeVariationRef: t.PrecisionTimestamp.GetTypeVariationReference(),
+		}, nil
+	case *proto.Type_PrecisionTimestampTz:
+		precision, err := TimestampPrecision(t.PrecisionTimestampTz.GetPrecision())
+		if err != nil {
+			return nil, err
+		}
+
+		return &types.PrecisionTimestampTzType{
+			PrecisionTimestampType: types.PrecisionTimestampType{
+				Precision:        precision,
+				Nullability:      t.PrecisionTimestampTz.GetNullability(),
+				TypeVariationRef: t.PrecisionTimestampTz.GetTypeVariationReference(),
+			},
+		}, nil
+	case *proto.Type_IntervalCompound_:
+		precision, err := types.ProtoToTimePrecision(t.IntervalCompound.GetPrecision())
+		if err != nil {
+			return nil, err
+		}
+
+		// Changing the precision or nullability resets the type variation
+		interval := types.NewIntervalCompoundType().
+			WithPrecision(precision).
+			WithNullability(t.IntervalCompound.GetNullability()).(types.IntervalCompoundType)
+
+		return interval.WithTypeVariationRef(t.IntervalCompound.GetTypeVariationReference()), nil
+	case *proto.Type_IntervalDay_:
+		if p := t.IntervalDay.Precision; p != nil {
+			if _, err := types.ProtoToTimePrecision(*p); err != nil {
+				return nil, err
+			}
+		}
+
+		return types.TypeFromProto(typ), nil
+	case *proto.Type_UserDefinedTypeReference:
+		return nil, fmt.Errorf("unsupported type: user defined type reference")
+	default:
+		return types.TypeFromProto(typ), nil
+	}
 }
@@ -8,6 +8,21 @@ import (
 type DataFrame interface {
 	Select(exprs ...engine.Expr) DataFrame
 	Filter(expr engine.Expr) DataFrame
+	Limit(offset, count int64) DataFrame
+	Sort(fields ...engine.SortField) DataFrame
+	Join(right DataFrame, joinType engine.JoinType, condition, postJoinFilter engine.Expr) DataFrame
+	JoinUsing(right DataFrame, joinType engine.JoinType, columns ...string) DataFrame
+	CrossJoin(right DataFrame) DataFrame
+	Aggregate(groupBy []engine.Expr, measures []engine.AggregateExpr) DataFrame
+	AggregateGroupingSets(groupingSets [][]engine.Expr, measures []engine.AggregateExpr) DataFrame
+	Window(partitions []engine.Expr, sorts []engine.SortField, functions []*engine.WindowFunction) DataFrame
+	Union(others ...DataFrame) DataFrame
+	Intersect(others ...DataFrame) DataFrame
+	Except(others ...DataFrame) DataFrame
+	SetOperation(op engine.SetOp, others ...DataFrame) DataFrame
+	Alias(alias string) DataFrame
+	Write(table engine.NamedTable, op engine.WriteOp, output engine.WriteOutputMode) DataFrame
+	ViewDdl(identifier engine.Identifier, op engine.DdlOp) DataFrame
 
 	Schema() (*bonobo.Schema, error)
 	LogicalPlan() engine.Relation
@@ -25,6 +40,20 @@ func (execCtx *queryContext) Read(table engine.Table) DataFrame {
 	return dataframe{plan: r, exec: execCtx}
 }
 
+// ReadOperation starts from a configured read, such as one with a
+// projection or filter pushed down into it.
+func (execCtx *queryContext) ReadOperation(read *engine.Read) DataFrame {
+	return dataframe{plan: read, exec: execCtx}
+}
+
+// TableDdl changes the definition of the table with the provided identifier,
+// such as creating it with schema or dropping it.
+func (execCtx *queryContext) TableDdl(identifier engine.Identifier, op engine.DdlOp, schema *bonobo.Schema) DataFrame {
+	d := engine.NewTableDdlOperation(identifier, op, schema)
+
+	return dataframe{plan: d, exec: execCtx}
+}
+
 type dataframe struct {
 	plan engine.Relation
 	exec *queryContext
@@ -40,15 +69,143 @@ func (df dataframe) Filter(expr engine.Expr) DataFrame {
 	return df
 }
 
+func (df dataframe) Limit(offset, count int64) DataFrame {
+	df.plan = engine.NewFetchOperation(df.plan, offset, count)
+	return df
+}
+
+func (df dataframe) Sort(fields ...engine.SortField) DataFrame {
+	df.plan = engine.NewSortOperation(df.plan, fields)
+	return df
+}
+
+func (df dataframe) Join(right DataFrame, joinType engine.JoinType, condition, postJoinFilter engine.Expr) DataFrame {
+	df.plan = engine.NewJoinOperation(df.plan, right.LogicalPlan(), joinType, condition, postJoinFilter)
+	return df
+}
+
+func (df dataframe) JoinUsing(right DataFrame, joinType engine.JoinType, columns ...string) DataFrame {
+	df.plan = engine.NewJoinUsingOperation(df.plan, right.LogicalPlan(), joinType, columns)
+	return df
+}
+
+func (df dataframe) CrossJoin(right DataFrame) DataFrame {
+	df.plan = engine.NewCrossOperation(df.plan, right.LogicalPlan())
+	return df
+}
+
+func (df dataframe) Aggregate(groupBy []engine.Expr, measures []engine.AggregateExpr) DataFrame {
+	df.plan = engine.NewAggregateOperation(df.plan, [][]engine.Expr{groupBy}, measures)
+	return df
+}
+
+func (df dataframe) AggregateGroupingSets(groupingSets [][]engine.Expr, measures []engine.AggregateExpr) DataFrame {
+	df.plan = engine.NewAggregateOperation(df.plan, groupingSets, measures)
+	return df
+}
+
+// Window appends a field for each of functions, computed over the records
+// with the same values for partitions ordered by sorts.
+func (df dataframe) Window(partitions []engine.Expr, sorts []engine.SortField, functions []*engine.WindowFunction) DataFrame {
+	df.plan = engine.NewConsistentPartitionWindowOperation(df.plan, partitions, sorts, functions)
+	return df
+}
+
+// Union outputs all records of each DataFrame, including duplicates,
+// like UNION ALL.
+func (df dataframe) Union(others ...DataFrame) DataFrame {
+	return df.SetOperation(engine.SetOpUnionAll, others...)
+}
+
+// Intersect outputs the distinct records present in every DataFrame,
+// like INTERSECT.
+func (df dataframe) Intersect(others ...DataFrame) DataFrame {
+	return df.SetOperation(engine.SetOpIntersectionMultiset, others...)
+}
+
+// Except outputs the distinct records not present in any of the others,
+// like EXCEPT.
+func (df dataframe) Except(others ...DataFrame) DataFrame {
+	return df.SetOperation(engine.SetOpMinusPrimary, others...)
+}
+
+func (df dataframe) SetOperation(op engine.SetOp, others ...DataFrame) DataFrame {
+	inputs := []engine.Relation{df.plan}
+	for _, other := range others {
+		inputs = append(inputs, other.LogicalPlan())
+	}
+
+	df.plan = engine.NewSetOperation(inputs, op)
+	return df
+}
+
+func (df dataframe) Alias(alias string) DataFrame {
+	df.plan = engine.NewTableAliasOperation(df.plan, alias)
+	return df
+}
+
+func (df dataframe) Write(table engine.NamedTable, op engine.WriteOp, output engine.WriteOutputMode) DataFrame {
+	df.plan = engine.NewWriteOperation(df.plan, table, op, output)
+	return df
+}
+
+// ViewDdl changes the definition of the view with the provided identifier to
+// the records of the DataFrame.
+func (df dataframe) ViewDdl(identifier engine.Identifier, op engine.DdlOp) DataFrame {
+	df.plan = engine.NewViewDdlOperation(identifier, op, df.plan)
+	return df
+}
+
 func (df dataframe) Schema() (*bonobo.Schema, error) { return df.plan.Schema() }
 
 func (df dataframe) LogicalPlan() engine.Relation { return df.plan }
 
+// Asc sorts expr in ascending order with nulls last.
+func Asc(expr engine.Expr) engine.SortField {
+	return engine.NewSortField(expr, engine.SortAscNullsLast)
+}
+
+// Desc sorts expr in descending order with nulls first.
+func Desc(expr engine.Expr) engine.SortField {
+	return engine.NewSortField(expr, engine.SortDescNullsFirst)
+}
+
+// Scalar is the single value returned by the subquery df.
+func Scalar(df DataFrame) engine.Expr {
+	return engine.NewScalarSubqueryExpr(df.LogicalPlan())
+}
+
+// Exists is true if the subquery df returns any rows.
+func Exists(df DataFrame) engine.Expr {
+	return engine.NewSetPredicateSubqueryExpr(engine.PredicateOpExists, df.LogicalPlan())
+}
+
+// InQuery is true if the values of needles are equal to any row of the subquery df.
+func InQuery(df DataFrame, needles ...engine.Expr) engine.Expr {
+	return engine.NewInSubqueryExpr(needles, df.LogicalPlan())
+}
+
 var (
-	ColIdx = engine.NewColumnIndexExpr
-	Lit    = engine.NewLiteralExpr
-	Col    = engine.NewColumnExpr
-	As     = engine.NewAliasExpr
+	ColIdx  = engine.NewColumnIndexExpr
+	Lit     = engine.MustLiteral
+	Null    = engine.NewNullLiteral
+	Col     = engine.NewColumnExpr
+	QCol    = engine.NewQualifiedColumnExpr
+	As      = engine.NewAliasExpr
+	Cast    = engine.NewCastExpr
+	IfThen  = engine.NewIfThenExpr
+	Switch  = engine.NewSwitchExpr
+	In      = engine.NewInListExpr
+	MultiIn = engine.NewMultiInListExpr
+	Outer   = engine.NewOuterReferenceExpr
+	Struct  = engine.NewStructExpr
+	List    = engine.NewListExpr
+	Map     = engine.NewMapExpr
+	Path    = engine.NewFieldPathExpr
 
 	Add = engine.NewAddFunctionExpr
+	Sum = engine.NewSumFunctionExpr
+
+	RowNumber = engine.NewRowNumberFunctionExpr
+	Rank      = engine.NewRankFunctionExpr
 )
@@ -0,0 +1,610 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/joellubi/bonobo"
+	"github.com/joellubi/bonobo/substrait"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/decimal128"
+	"github.com/apache/arrow/go/v17/arrow/memory"
+	"github.com/substrait-io/substrait-go/v3/proto"
+	"github.com/substrait-io/substrait-go/v3/types"
+)
+
+// recordToProto converts each row of rec into a struct literal.
+func recordToProto(rec arrow.Record, schema *bonobo.Schema, extensions *substrait.ExtensionRegistry) ([]*proto.Expression_Literal_Struct, error) {
+	rows := make([]*proto.Expression_Literal_Struct, rec.NumRows())
+	for i := range rows {
+		rows[i] = &proto.Expression_Literal_Struct{
+			Fields: make([]*proto.Expression_Literal, rec.NumCols()),
+		}
+	}
+
+	for j, col := range rec.Columns() {
+		typ := schema.Struct.Types[j]
+		for i := range rows {
+			lit, err := arrowLiteral(col, i, typ)
+			if err == nil {
+				rows[i].Fields[j], err = literalToProto(lit, extensions)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("engine: column %q, row %d: %w", rec.ColumnName(j), i, err)
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// arrowLiteral converts the value at index i of arr to a literal of typ, the
+// Substrait type of arr. Dictionary, run-end encoded and extension arrays are
+// read through to their values.
+func arrowLiteral(arr arrow.Array, i int, typ bonobo.Type) (*Literal, error) {
+	if arr.IsNull(i) {
+		if typ.GetNullability() == types.NullabilityRequired {
+			return nil, fmt.Errorf("null value of non-nullable type %s", typ)
+		}
+		return NewNullLiteral(typ), nil
+	}
+
+	switch arr := arr.(type) {
+	case *array.Dictionary:
+		return arrowLiteral(arr.Dictionary(), arr.GetValueIndex(i), typ)
+	case *array.RunEndEncoded:
+		return arrowLiteral(arr.Values(), arr.GetPhysicalIndex(i), typ)
+	case array.ExtensionArray:
+		return arrowLiteral(arr.Storage(), i, typ)
+	}
+
+	val, err := arrowValue(arr, i, typ)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Literal{val: val, typ: typ}, nil
+}
+
+// arrowValue returns the value at index i of arr as a literal value of typ,
+// with the Go type that NewLiteral accepts for typ.
+func arrowValue(arr arrow.Array, i int, typ bonobo.Type) (any, error) {
+	switch t := typ.(type) {
+	case *types.BooleanType:
+		if arr, ok := arr.(*array.Boolean); ok {
+			return arr.Value(i), nil
+		}
+	case *types.Int8Type:
+		if arr, ok := arr.(*array.Int8); ok {
+			return arr.Value(i), nil
+		}
+	case *types.Int16Type:
+		if arr, ok := arr.(*array.Int16); ok {
+			return arr.Value(i), nil
+		}
+	case *types.Int32Type:
+		if arr, ok := arr.(*array.Int32); ok {
+			return arr.Value(i), nil
+		}
+	case *types.Int64Type:
+		if arr, ok := arr.(*array.Int64); ok {
+			return arr.Value(i), nil
+		}
+	case *types.Float32Type:
+		if arr, ok := arr.(*array.Float32); ok {
+			return arr.Value(i), nil
+		}
+	case *types.Float64Type:
+		if arr, ok := arr.(*array.Float64); ok {
+			return arr.Value(i), nil
+		}
+	case *types.StringType:
+		if arr, ok := arr.(arrowStringArray); ok {
+			return arr.Value(i), nil
+		}
+	case *types.FixedCharType:
+		if arr, ok := arr.(arrowStringArray); ok {
+			return types.FixedChar(arr.Value(i)), nil
+		}
+	case *types.VarCharType:
+		if arr, ok := arr.(arrowStringArray); ok {
+			return &types.VarChar{Value: arr.Value(i), Length: uint32(t.Length)}, nil
+		}
+	case *types.BinaryType:
+		if arr, ok := arr.(arrowBinaryArray); ok {
+			return bytes.Clone(arr.Value(i)), nil
+		}
+	case *types.FixedBinaryType:
+		if arr, ok := arr.(*array.FixedSizeBinary); ok {
+			return types.FixedBinary(bytes.Clone(arr.Value(i))), nil
+		}
+	case *types.UUIDType:
+		if arr, ok := arr.(*array.FixedSizeBinary); ok {
+			return types.UUID(bytes.Clone(arr.Value(i))), nil
+		}
+	case *types.DateType:
+		switch arr := arr.(type) {
+		case *array.Date32:
+			return types.Date(arr.Value(i)), nil
+		case *array.Date64:
+			return types.Date(arrow.Date32FromTime(arr.Value(i).ToTime())), nil
+		}
+	case *types.TimeType:
+		var (
+			v    int64
+			unit arrow.TimeUnit
+		)
+		switch arr := arr.(type) {
+		case *array.Time32:
+			v, unit = int64(arr.Value(i)), arr.DataType().(*arrow.Time32Type).Unit
+		case *array.Time64:
+			v, unit = int64(arr.Value(i)), arr.DataType().(*arrow.Time64Type).Unit
+		default:
+			return nil, arrowValueError(arr, typ)
+		}
+
+		micros, err := rescaleTime(v, arrowTimeUnitPrecision(unit), types.PrecisionMicroSeconds)
+		if err != nil {
+			return nil, err
+		}
+		return types.Time(micros), nil
+	case *types.TimestampType:
+		v, err := arrowTimestamp(arr, i, types.PrecisionMicroSeconds)
+		if err != nil {
+			return nil, err
+		}
+		return types.Timestamp(v), nil
+	case *types.TimestampTzType:
+		v, err := arrowTimestamp(arr, i, types.PrecisionMicroSeconds)
+		if err != nil {
+			return nil, err
+		}
+		return types.TimestampTz(v), nil
+	case *types.PrecisionTimestampType:
+		v, err := arrowTimestamp(arr, i, t.Precision)
+		if err != nil {
+			return nil, err
+		}
+		return &types.PrecisionTimestamp{
+			PrecisionTimestamp: &proto.Expression_Literal_PrecisionTimestamp{Precision: int32(t.Precision), Value: v},
+		}, nil
+	case *types.PrecisionTimestampTzType:
+		v, err := arrowTimestamp(arr, i, t.Precision)
+		if err != nil {
+			return nil, err
+		}
+		return &types.PrecisionTimestampTz{
+			PrecisionTimestampTz: &proto.Expression_Literal_PrecisionTimestamp{Precision: int32(t.Precision), Value: v},
+		}, nil
+	case *types.IntervalYearType, types.IntervalYearToMonthType:
+		if arr, ok := arr.(*array.MonthInterval); ok {
+			return intervalYearToMonth(int32(arr.Value(i))), nil
+		}
+	case *types.IntervalDayType:
+		switch arr := arr.(type) {
+		case *array.DayTimeInterval:
+			v := arr.Value(i)
+			return intervalDayToSecond(v.Days, int64(v.Milliseconds), types.PrecisionMilliSeconds, t.Precision)
+		case *array.MonthDayNanoInterval:
+			v := arr.Value(i)
+			if v.Months != 0 {
+				return nil, fmt.Errorf("interval %s with months cannot be converted to %s", arr.ValueStr(i), typ)
+			}
+			return intervalDayToSecond(v.Days, v.Nanoseconds, types.PrecisionNanoSeconds, t.Precision)
+		}
+	case types.IntervalCompoundType:
+		if arr, ok := arr.(*array.MonthDayNanoInterval); ok {
+			v := arr.Value(i)
+			precision, err := types.ProtoToTimePrecision(t.GetPrecisionProtoVal())
+			if err != nil {
+				return nil, err
+			}
+			days, err := intervalDayToSecond(v.Days, v.Nanoseconds, types.PrecisionNanoSeconds, precision)
+			if err != nil {
+				return nil, err
+			}
+			return &proto.Expression_Literal_IntervalCompound{
+				IntervalYearToMonth: intervalYearToMonth(v.Months),
+				IntervalDayToSecond: days,
+			}, nil
+		}
+	case *types.DecimalType:
+		var num decimal128.Num
+		switch arr := arr.(type) {
+		case *array.Decimal128:
+			num = arr.Value(i)
+		case *array.Decimal256:
+			if !arr.Value(i).FitsInPrecision(t.Precision) {
+				return nil, fmt.Errorf("decimal %s does not fit in %s", arr.ValueStr(i), typ)
+			}
+			num = decimal128.FromBigInt(arr.Value(i).BigInt())
+		default:
+			return nil, arrowValueError(arr, typ)
+		}
+
+		return &types.Decimal{Value: decimalBytes(num), Precision: t.Precision, Scale: t.Scale}, nil
+	case *types.StructType:
+		if arr, ok := arr.(*array.Struct); ok && arr.NumField() == len(t.Types) {
+			fields := make(StructValue, len(t.Types))
+			for k, fieldType := range t.Types {
+				var err error
+				if fields[k], err = arrowLiteral(arr.Field(k), i, fieldType); err != nil {
+					return nil, err
+				}
+			}
+			return fields, nil
+		}
+	case *types.ListType:
+		if arr, ok := arr.(array.ListLike); ok {
+			start, end := arr.ValueOffsets(i)
+			elems := make(ListValue, 0, end-start)
+			for j := start; j < end; j++ {
+				elem, err := arrowLiteral(arr.ListValues(), int(j), t.Type)
+				if err != nil {
+					return nil, err
+				}
+				elems = append(elems, elem)
+			}
+			return elems, nil
+		}
+	case *types.MapType:
+		if arr, ok := arr.(*array.Map); ok {
+			start, end := arr.ValueOffsets(i)
+			entries := make(MapValue, 0, end-start)
+			for j := start; j < end; j++ {
+				key, err := arrowLiteral(arr.Keys(), int(j), t.Key)
+				if err != nil {
+					return nil, err
+				}
+				value, err := arrowLiteral(arr.Items(), int(j), t.Value)
+				if err != nil {
+					return nil, err
+				}
+				entries = append(entries, MapLiteralEntry{Key: key, Value: value})
+			}
+			return entries, nil
+		}
+	}
+
+	return nil, arrowValueError(arr, typ)
+}
+
+type arrowStringArray interface {
+	Value(i int) string
+}
+
+type arrowBinaryArray interface {
+	Value(i int) []byte
+}
+
+func arrowValueError(arr arrow.Array, typ bonobo.Type) error {
+	return fmt.Errorf("cannot convert arrow array %s to %s", arr.DataType(), typ)
+}
+
+// arrowTimestamp returns the timestamp at index i of arr, counted in units of
+// precision.
+func arrowTimestamp(arr arrow.Array, i int, precision types.TimePrecision) (int64, error) {
+	ts, ok := arr.(*array.Timestamp)
+	if !ok {
+		return 0, fmt.Errorf("cannot convert arrow array %s to a timestamp", arr.DataType())
+	}
+
+	unit := ts.DataType().(*arrow.TimestampType).Unit
+	return rescaleTime(int64(ts.Value(i)), arrowTimeUnitPrecision(unit), precision)
+}
+
+func intervalYearToMonth(months int32) *types.IntervalYearToMonth {
+	return &types.IntervalYearToMonth{Years: months / 12, Months: months % 12}
+}
+
+// intervalDayToSecond creates an interval of days and subseconds, which are
+// counted in units of from, with subseconds of the given precision.
+func intervalDayToSecond(days int32, subseconds int64, from, precision types.TimePrecision) (*types.IntervalDayToSecond, error) {
+	unit := int64(1)
+	for range from {
+		unit *= 10
+	}
+
+	frac, err := rescaleTime(subseconds%unit, from, precision)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.IntervalDayToSecond{
+		Days:          days,
+		Seconds:       int32(subseconds / unit),
+		Subseconds:    frac,
+		PrecisionMode: &proto.Expression_Literal_IntervalDayToSecond_Precision{Precision: int32(precision)},
+	}, nil
+}
+
+// rescaleTime converts v from units of precision from to units of precision
+// to, returning an error if it cannot be represented exactly.
+func rescaleTime(v int64, from, to types.TimePrecision) (int64, error) {
+	out := v
+	for p := from; p < to; p++ {
+		out *= 10
+	}
+	for p := from; p > to; p-- {
+		if out%10 != 0 {
+			return 0, fmt.Errorf("time %d with precision %d cannot be represented with precision %d", v, from, to)
+		}
+		out /= 10
+	}
+
+	return out, nil
+}
+
+// arrowTimeUnitPrecision returns the precision of times in units of unit.
+func arrowTimeUnitPrecision(unit arrow.TimeUnit) types.TimePrecision {
+	return types.TimePrecision(3 * unit)
+}
+
+// recordFromProto builds a record with the given schema from rows of literals.
+func recordFromProto(schema *bonobo.Schema, rows [][]*proto.Expression_Literal, extensions *substrait.ExtensionRegistry) (arrow.Record, error) {
+	arrowSchema, err := schema.ToArrow()
+	if err != nil {
+		return nil, err
+	}
+
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, arrowSchema)
+	defer bldr.Release()
+
+	for i, row := range rows {
+		if len(row) != schema.Len() {
+			return nil, fmt.Errorf("engine: virtual table row %d has %d values, expected %d", i, len(row), schema.Len())
+		}
+
+		for j, exprLiteral := range row {
+			lit, err := literalFromProto(exprLiteral, extensions)
+			if err == nil {
+				err = appendLiteral(bldr.Field(j), lit)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("engine: virtual table row %d, column %q: %w", i, schema.Fields()[j].Name, err)
+			}
+		}
+	}
+
+	return bldr.NewRecord(), nil
+}
+
+// appendLiteral appends the value of lit to bldr, which must build the Arrow
+// type that bonobo.Schema.ToArrow converts the type of lit to.
+func appendLiteral(bldr array.Builder, lit *Literal) error {
+	if lit.val == nil {
+		bldr.AppendNull()
+		return nil
+	}
+
+	switch b := bldr.(type) {
+	case *array.BooleanBuilder:
+		if v, ok := lit.val.(bool); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Int8Builder:
+		if v, ok := lit.val.(int8); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Int16Builder:
+		if v, ok := lit.val.(int16); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Int32Builder:
+		if v, ok := lit.val.(int32); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Int64Builder:
+		if v, ok := lit.val.(int64); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Float32Builder:
+		if v, ok := lit.val.(float32); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Float64Builder:
+		if v, ok := lit.val.(float64); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.StringBuilder:
+		switch v := lit.val.(type) {
+		case string:
+			b.Append(v)
+			return nil
+		case types.FixedChar:
+			b.Append(string(v))
+			return nil
+		case *types.VarChar:
+			b.Append(v.GetValue())
+			return nil
+		}
+	case *array.BinaryBuilder:
+		if v, ok := lit.val.([]byte); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.FixedSizeBinaryBuilder:
+		switch v := lit.val.(type) {
+		case types.FixedBinary:
+			b.Append(v)
+			return nil
+		case types.UUID:
+			b.Append(v)
+			return nil
+		}
+	case *array.Date32Builder:
+		if v, ok := lit.val.(types.Date); ok {
+			b.Append(arrow.Date32(v))
+			return nil
+		}
+	case *array.Time64Builder:
+		if v, ok := lit.val.(types.Time); ok {
+			unit := b.Type().(*arrow.Time64Type).Unit
+			t, err := rescaleTime(int64(v), types.PrecisionMicroSeconds, arrowTimeUnitPrecision(unit))
+			if err != nil {
+				return err
+			}
+			b.Append(arrow.Time64(t))
+			return nil
+		}
+	case *array.TimestampBuilder:
+		if v, precision, ok := literalTimestamp(lit.val); ok {
+			unit := b.Type().(*arrow.TimestampType).Unit
+			ts, err := rescaleTime(v, precision, arrowTimeUnitPrecision(unit))
+			if err != nil {
+				return err
+			}
+			b.Append(arrow.Timestamp(ts))
+			return nil
+		}
+	case *array.MonthIntervalBuilder:
+		if v, ok := lit.val.(*types.IntervalYearToMonth); ok {
+			b.Append(arrow.MonthInterval(v.GetYears()*12 + v.GetMonths()))
+			return nil
+		}
+	case *array.DayTimeIntervalBuilder:
+		if v, ok := lit.val.(*types.IntervalDayToSecond); ok {
+			millis, err := intervalSubseconds(v, types.PrecisionMilliSeconds)
+			if err != nil {
+				return err
+			}
+			b.Append(arrow.DayTimeInterval{Days: v.GetDays(), Milliseconds: int32(millis)})
+			return nil
+		}
+	case *array.MonthDayNanoIntervalBuilder:
+		var (
+			months   int32
+			interval *types.IntervalDayToSecond
+		)
+		switch v := lit.val.(type) {
+		case *types.IntervalDayToSecond:
+			interval = v
+		case *proto.Expression_Literal_IntervalCompound:
+			months = v.GetIntervalYearToMonth().GetYears()*12 + v.GetIntervalYearToMonth().GetMonths()
+			interval = v.GetIntervalDayToSecond()
+		default:
+			return literalValueError(bldr, lit)
+		}
+
+		nanos, err := intervalSubseconds(interval, types.PrecisionNanoSeconds)
+		if err != nil {
+			return err
+		}
+		b.Append(arrow.MonthDayNanoInterval{Months: months, Days: interval.GetDays(), Nanoseconds: nanos})
+		return nil
+	case *array.Decimal128Builder:
+		if v, ok := lit.val.(*types.Decimal); ok {
+			num, err := decimalFromBytes(v.GetValue())
+			if err != nil {
+				return err
+			}
+			b.Append(num)
+			return nil
+		}
+	case *array.StructBuilder:
+		if v, ok := lit.val.(StructValue); ok && len(v) == b.NumField() {
+			b.Append(true)
+			for k, field := range v {
+				if err := appendLiteral(b.FieldBuilder(k), field); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case *array.MapBuilder:
+		if v, ok := lit.val.(MapValue); ok {
+			b.Append(true)
+			for _, entry := range v {
+				if err := appendLiteral(b.KeyBuilder(), entry.Key); err != nil {
+					return err
+				}
+				if err := appendLiteral(b.ItemBuilder(), entry.Value); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case *array.ListBuilder:
+		if v, ok := lit.val.(ListValue); ok {
+			b.Append(true)
+			for _, elem := range v {
+				if err := appendLiteral(b.ValueBuilder(), elem); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	return literalValueError(bldr, lit)
+}
+
+func literalValueError(bldr array.Builder, lit *Literal) error {
+	return fmt.Errorf("literal %s does not match column type %s", lit, bldr.Type())
+}
+
+// literalTimestamp returns the value and precision of a timestamp literal
+// value, or false if val is not a timestamp.
+func literalTimestamp(val any) (int64, types.TimePrecision, bool) {
+	switch v := val.(type) {
+	case types.Timestamp:
+		return int64(v), types.PrecisionMicroSeconds, true
+	case types.TimestampTz:
+		return int64(v), types.PrecisionMicroSeconds, true
+	case *types.PrecisionTimestamp:
+		return v.PrecisionTimestamp.GetValue(), types.TimePrecision(v.PrecisionTimestamp.GetPrecision()), true
+	case *types.PrecisionTimestampTz:
+		return v.PrecisionTimestampTz.GetValue(), types.TimePrecision(v.PrecisionTimestampTz.GetPrecision()), true
+	default:
+		return 0, types.PrecisionUnknown, false
+	}
+}
+
+// intervalSubseconds returns the seconds and subseconds of interval in units
+// of precision.
+func intervalSubseconds(interval *types.IntervalDayToSecond, precision types.TimePrecision) (int64, error) {
+	from, subseconds, err := intervalDaySubseconds(interval)
+	if err != nil {
+		return 0, err
+	}
+
+	seconds, err := rescaleTime(int64(interval.GetSeconds()), types.PrecisionSeconds, precision)
+	if err != nil {
+		return 0, err
+	}
+	frac, err := rescaleTime(subseconds, from, precision)
+	if err != nil {
+		return 0, err
+	}
+
+	return seconds + frac, nil
+}
+
+// decimalBytes encodes num as the 16 byte little-endian two's complement
+// value used by Substrait decimal literals.
+func decimalBytes(num decimal128.Num) []byte {
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint64(b[:8], num.LowBits())
+	binary.LittleEndian.PutUint64(b[8:], uint64(num.HighBits()))
+	return b
+}
+
+func decimalFromBytes(b []byte) (decimal128.Num, error) {
+	if len(b) != 16 {
+		return decimal128.Num{}, fmt.Errorf("decimal literal must be 16 bytes, found %d", len(b))
+	}
+
+	lo := binary.LittleEndian.Uint64(b[:8])
+	hi := int64(binary.LittleEndian.Uint64(b[8:]))
+	return decimal128.New(hi, lo), nil
+}
@@ -2,14 +2,12 @@ package engine
 
 import (
 	"errors"
-	"fmt"
 
 	"github.com/joellubi/bonobo"
 	"github.com/joellubi/bonobo/substrait"
 
 	"github.com/apache/arrow/go/v17/arrow"
 	"github.com/substrait-io/substrait-go/v3/proto"
-	"github.com/substrait-io/substrait-go/v3/types"
 )
 
 var (
@@ -67,7 +65,9 @@ func (t *namedTable) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Re
 	// serialize the plan and just omit the schema
 	schema, err := t.Schema()
 	if err == nil {
-		baseSchema = types.NamedStruct(*schema).ToProto()
+		if baseSchema, err = schema.ToProto(); err != nil {
+			return nil, err
+		}
 	}
 
 	return &proto.Rel{
@@ -84,6 +84,9 @@ func (t *namedTable) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Re
 	}, nil
 }
 
+// NewVirtualTable creates a table whose rows are inlined into the plan.
+// A nil record produces an empty table with no columns. The table does
+// not take ownership of rec.
 func NewVirtualTable(rec arrow.Record) *virtualTable {
 	return &virtualTable{rec: rec}
 }
@@ -92,25 +95,48 @@ type virtualTable struct {
 	rec arrow.Record
 }
 
+// Record returns the arrow record backing the table, which may be nil.
+func (t *virtualTable) Record() arrow.Record {
+	return t.rec
+}
+
 func (t *virtualTable) Schema() (*bonobo.Schema, error) {
 	if t.rec == nil {
 		return bonobo.NewSchema(nil), nil
 	}
 
-	return nil, fmt.Errorf("engine: virtual table from arrow record is not yet implemented")
+	return bonobo.SchemaFromArrowValues(t.rec.Schema())
 }
 
 func (t *virtualTable) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	var (
+		baseSchema *proto.NamedStruct
+		values     []*proto.Expression_Literal_Struct
+	)
+
 	if t.rec != nil {
-		return nil, fmt.Errorf("engine: virtual table from arrow record is not yet implemented")
+		schema, err := t.Schema()
+		if err != nil {
+			return nil, err
+		}
+
+		values, err = recordToProto(t.rec, schema, extensions)
+		if err != nil {
+			return nil, err
+		}
+
+		if baseSchema, err = schema.ToProto(); err != nil {
+			return nil, err
+		}
 	}
 
 	return &proto.Rel{
 		RelType: &proto.Rel_Read{
 			Read: &proto.ReadRel{
+				BaseSchema: baseSchema,
 				ReadType: &proto.ReadRel_VirtualTable_{
 					VirtualTable: &proto.ReadRel_VirtualTable{
-						Values: nil,
+						Values: values,
 					},
 				},
 			},
@@ -2,13 +2,13 @@ package engine
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/joellubi/bonobo"
 	"github.com/joellubi/bonobo/substrait"
 
 	"github.com/substrait-io/substrait-go/v3/proto"
+	"github.com/substrait-io/substrait-go/v3/types"
 )
 
 type Expr interface {
@@ -18,6 +18,15 @@ type Expr interface {
 	Field(input Relation) (bonobo.Field, error)
 }
 
+// AggregateExpr computes a single value from all of the records in a group,
+// such as a measure of an Aggregate relation.
+type AggregateExpr interface {
+	fmt.Stringer
+	ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.AggregateFunction, error)
+
+	Field(input Relation) (bonobo.Field, error)
+}
+
 type ExprList []Expr
 
 func (exprs ExprList) String() string {
@@ -33,29 +42,28 @@ func NewColumnExpr(name string) *Column {
 	return &Column{name: name}
 }
 
+// NewQualifiedColumnExpr references the column with the provided name that
+// belongs to the table identified by qualifier, such as t in t.col. The
+// qualifier may be a table alias or a suffix of a table's identifier.
+func NewQualifiedColumnExpr(qualifier, name string) *Column {
+	return &Column{qualifier: qualifier, name: name}
+}
+
 type Column struct {
-	name string
+	qualifier string
+	name      string
 }
 
 func (expr *Column) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
-	var structField *proto.Expression_ReferenceSegment_StructField
+	if path, ok := expr.structFieldPath(input); ok {
+		return path.ToProto(input, extensions)
+	}
 
 	// We cannot represent a named column ref
 	// without knowing the underlying schema
-	schema, err := input.Schema()
+	index, err := expr.index(input)
 	if err != nil {
-		return nil, fmt.Errorf("input schema required to serialize Column expr: %w", err)
-	}
-
-	for i, name := range schema.Names {
-		if name == expr.name {
-			structField = &proto.Expression_ReferenceSegment_StructField{Field: int32(i)}
-			break
-		}
-	}
-
-	if structField == nil {
-		return nil, fmt.Errorf("cannot marshal Column expr to proto: input does not contain field: %s", expr.name)
+		return nil, fmt.Errorf("cannot marshal Column expr to proto: %w", err)
 	}
 
 	return &proto.Expression{
@@ -64,7 +72,7 @@ func (expr *Column) ToProto(input Relation, extensions *substrait.ExtensionRegis
 				ReferenceType: &proto.Expression_FieldReference_DirectReference{
 					DirectReference: &proto.Expression_ReferenceSegment{
 						ReferenceType: &proto.Expression_ReferenceSegment_StructField_{
-							StructField: structField,
+							StructField: &proto.Expression_ReferenceSegment_StructField{Field: int32(index)},
 						},
 					},
 				},
@@ -74,23 +82,114 @@ func (expr *Column) ToProto(input Relation, extensions *substrait.ExtensionRegis
 }
 
 func (expr *Column) Field(input Relation) (bonobo.Field, error) {
-	inputSchema, err := input.Schema()
+	if path, ok := expr.structFieldPath(input); ok {
+		// The field keeps the name of the struct field, like a column
+		field, _, err := path.resolve(input)
+		return field, err
+	}
+
+	index, err := expr.index(input)
 	if err != nil {
 		return bonobo.Field{}, err
 	}
 
-	for _, field := range inputSchema.Fields() {
-		if field.Name == expr.name {
-			return field, nil
-		}
+	inputSchema, err := input.Schema()
+	if err != nil {
+		return bonobo.Field{}, err
 	}
-	return bonobo.Field{}, fmt.Errorf("no column named %s", expr.name)
+
+	return inputSchema.Fields()[index], nil
 }
 
 func (expr *Column) String() string {
+	if expr.qualifier != "" {
+		return fmt.Sprintf("#%s.%s", expr.qualifier, expr.name)
+	}
 	return fmt.Sprintf("#%s", expr.name)
 }
 
+// structFieldPath reinterprets a qualified reference that does not match a
+// field of input as a reference to a field of a struct column, so that a.b
+// refers to field b of column a when there is no table a. Leading parts of the
+// qualifier may still identify the table, as in t.a.b.
+func (expr *Column) structFieldPath(input Relation) (*FieldPath, bool) {
+	if expr.qualifier == "" {
+		return nil, false
+	}
+	if _, err := expr.index(input); err == nil {
+		return nil, false
+	}
+
+	parts := append(strings.Split(expr.qualifier, "."), expr.name)
+	for i := len(parts) - 2; i >= 0; i-- {
+		col := NewColumnExpr(parts[i])
+		if i > 0 {
+			col = NewQualifiedColumnExpr(strings.Join(parts[:i], "."), parts[i])
+		}
+		if _, err := col.index(input); err != nil {
+			continue
+		}
+
+		segments := make([]PathSegment, 0, len(parts)-i-1)
+		for _, name := range parts[i+1:] {
+			segments = append(segments, NamedStructFieldSegment(name))
+		}
+		return NewFieldPathExpr(col, segments...), true
+	}
+
+	return nil, false
+}
+
+// index finds the position of the referenced column in the schema of input.
+// The reference must match exactly one field, so a name shared by the fields
+// of several tables must be qualified.
+func (expr *Column) index(input Relation) (int, error) {
+	inputSchema, err := input.Schema()
+	if err != nil {
+		return 0, fmt.Errorf("input schema required to resolve Column expr: %w", err)
+	}
+
+	if expr.qualifier == "" {
+		index := -1
+		for i, field := range inputSchema.Fields() {
+			if field.Name != expr.name {
+				continue
+			}
+			if index >= 0 {
+				return 0, fmt.Errorf("ambiguous reference to field: %s", expr.name)
+			}
+			index = i
+		}
+
+		if index < 0 {
+			return 0, fmt.Errorf("input does not contain field: %s", expr.name)
+		}
+		return index, nil
+	}
+
+	qualifiers, err := fieldQualifiers(input)
+	if err != nil {
+		return 0, err
+	}
+
+	index := -1
+	for i, field := range inputSchema.Fields() {
+		if field.Name != expr.name || !matchesQualifier(qualifiers[i], expr.qualifier) {
+			continue
+		}
+		if index >= 0 {
+			return 0, fmt.Errorf("ambiguous reference to field: %s.%s", expr.qualifier, expr.name)
+		}
+		index = i
+	}
+
+	if index < 0 {
+		return 0, fmt.Errorf("input does not contain field: %s.%s", expr.qualifier, expr.name)
+	}
+
+	return index, nil
+}
+
 func NewColumnIndexExpr(index int) *ColumnIndex {
 	return &ColumnIndex{index: index}
 }
@@ -124,7 +223,7 @@ func (expr *ColumnIndex) Field(input Relation) (bonobo.Field, error) {
 	}
 
 	fields := inputSchema.Fields()
-	if expr.index >= len(fields) {
+	if expr.index < 0 || expr.index >= len(fields) {
 		return bonobo.Field{}, fmt.Errorf("column index %d out of range for input with %d fields", expr.index, len(fields))
 	}
 
@@ -135,166 +234,592 @@ func (expr *ColumnIndex) String() string {
 	return fmt.Sprintf("#%d", expr.index)
 }
 
-func NewLiteralExpr(val any) *Literal {
-	var typ bonobo.Type
-
-	switch v := val.(type) {
-	case bool:
-		typ = bonobo.Types.BooleanType(false)
-	case int8:
-		typ = bonobo.Types.Int8Type(false)
-	case int16:
-		typ = bonobo.Types.Int16Type(false)
-	case int32:
-		typ = bonobo.Types.Int32Type(false)
-	case int64:
-		typ = bonobo.Types.Int64Type(false)
-	case int:
-		typ = bonobo.Types.Int64Type(false)
-		val = int64(v)
-	case float32:
-		typ = bonobo.Types.FloatType(false)
-	case float64:
-		typ = bonobo.Types.DoubleType(false)
-	case string:
-		typ = bonobo.Types.StringType(false)
-	default:
-		panic(fmt.Sprintf("invalid literal type: %T", v))
-	}
-
-	return &Literal{val: val, typ: typ}
-}
-
-type Literal struct {
-	val any
-	typ bonobo.Type
-}
-
-func (expr *Literal) Field(input Relation) (bonobo.Field, error) {
-	return bonobo.Field{Name: expr.Name(), Type: expr.typ}, nil
-}
-
-func (expr *Literal) String() string {
-	return fmt.Sprintf("%s::%s", expr.Name(), expr.typ)
-}
-
-func (expr *Literal) Name() string {
-	switch v := expr.val.(type) {
-	case bool:
-		return strconv.FormatBool(v)
-	case int8:
-		return strconv.FormatInt(int64(v), 10)
-	case int16:
-		return strconv.FormatInt(int64(v), 10)
-	case int32:
-		return strconv.FormatInt(int64(v), 10)
-	case int64:
-		return strconv.FormatInt(v, 10)
-	case float32:
-		return strconv.FormatFloat(float64(v), 'f', -1, 32)
-	case float64:
-		return strconv.FormatFloat(v, 'f', -1, 64)
-	case string:
-		return v
-	default:
-		panic(fmt.Sprintf("invalid literal type: %T", v))
-	}
-}
-
-func (expr *Literal) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
-	var exprLiteral *proto.Expression_Literal
-
-	switch v := expr.val.(type) {
-	case bool:
-		exprLiteral = &proto.Expression_Literal{
-			LiteralType: &proto.Expression_Literal_Boolean{
-				Boolean: v,
+func NewAliasExpr(expr Expr, name string) *Alias {
+	return &Alias{child: expr, alias: name}
+}
+
+type Alias struct {
+	child Expr
+	alias string
+}
+
+// Field implements Expr.
+func (expr *Alias) Field(input Relation) (bonobo.Field, error) {
+	field, err := expr.child.Field(input)
+	if err != nil {
+		return bonobo.Field{}, err
+	}
+
+	field.Name = expr.alias
+	return field, nil
+}
+
+// String implements Expr.
+func (expr *Alias) String() string {
+	return fmt.Sprintf("%s AS %s", expr.child.String(), expr.alias)
+}
+
+// ToProto implements Expr.
+func (expr *Alias) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
+	return expr.child.ToProto(input, extensions)
+}
+
+// CastFailureBehavior is how a Cast handles values that cannot be
+// converted to the target type.
+type CastFailureBehavior proto.Expression_Cast_FailureBehavior
+
+const (
+	CastFailureUnspecified    = CastFailureBehavior(proto.Expression_Cast_FAILURE_BEHAVIOR_UNSPECIFIED)
+	CastFailureReturnNull     = CastFailureBehavior(proto.Expression_Cast_FAILURE_BEHAVIOR_RETURN_NULL)
+	CastFailureThrowException = CastFailureBehavior(proto.Expression_Cast_FAILURE_BEHAVIOR_THROW_EXCEPTION)
+)
+
+func (b CastFailureBehavior) String() string {
+	name, _ := strings.CutPrefix(proto.Expression_Cast_FailureBehavior(b).String(), "FAILURE_BEHAVIOR_")
+	return name
+}
+
+// NewCastExpr converts the result of expr to typ. The output type is typ as
+// provided, so it should be nullable if expr may be null or if failures
+// return null.
+func NewCastExpr(expr Expr, typ bonobo.Type, failure CastFailureBehavior) *Cast {
+	return &Cast{child: expr, typ: typ, failure: failure}
+}
+
+type Cast struct {
+	child   Expr
+	typ     bonobo.Type
+	failure CastFailureBehavior
+}
+
+// Field implements Expr.
+func (expr *Cast) Field(input Relation) (bonobo.Field, error) {
+	if _, err := expr.child.Field(input); err != nil {
+		return bonobo.Field{}, err
+	}
+
+	return bonobo.Field{Name: expr.String(), Type: expr.typ}, nil
+}
+
+// String implements Expr.
+func (expr *Cast) String() string {
+	s := fmt.Sprintf("cast(%s AS %s)", expr.child, expr.typ)
+	if expr.failure != CastFailureUnspecified {
+		s += fmt.Sprintf(" ON FAILURE %s", expr.failure)
+	}
+	return s
+}
+
+// ToProto implements Expr.
+func (expr *Cast) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
+	child, err := expr.child.ToProto(input, extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	typ, err := bonobo.TypeToProto(expr.typ)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.Expression{
+		RexType: &proto.Expression_Cast_{
+			Cast: &proto.Expression_Cast{
+				Type:            typ,
+				Input:           child,
+				FailureBehavior: proto.Expression_Cast_FailureBehavior(expr.failure),
 			},
+		},
+	}, nil
+}
+
+// IfClause is a condition and the result of an IfThen when it is the first
+// condition to be true.
+type IfClause struct {
+	If   Expr
+	Then Expr
+}
+
+// NewIfThenExpr evaluates to the result of the first clause whose condition
+// is true, or to els if none are. If els is nil, the result is null when no
+// condition is true.
+func NewIfThenExpr(clauses []IfClause, els Expr) *IfThen {
+	return &IfThen{clauses: clauses, els: els}
+}
+
+type IfThen struct {
+	clauses []IfClause
+	els     Expr
+}
+
+// Field implements Expr.
+func (expr *IfThen) Field(input Relation) (bonobo.Field, error) {
+	typ, err := expr.resultType(input)
+	if err != nil {
+		return bonobo.Field{}, err
+	}
+
+	return bonobo.Field{Name: expr.String(), Type: typ}, nil
+}
+
+// resultType validates the conditions and unifies the types of the results.
+func (expr *IfThen) resultType(input Relation) (bonobo.Type, error) {
+	if len(expr.clauses) == 0 {
+		return nil, fmt.Errorf("engine: if-then expression must have at least one clause")
+	}
+
+	results := make([]bonobo.Type, 0, len(expr.clauses)+1)
+	for _, clause := range expr.clauses {
+		cond, err := clause.If.Field(input)
+		if err != nil {
+			return nil, err
 		}
-	case int8:
-		exprLiteral = &proto.Expression_Literal{
-			LiteralType: &proto.Expression_Literal_I8{
-				I8: int32(v),
-			},
+		if _, ok := cond.Type.(*types.BooleanType); !ok {
+			return nil, fmt.Errorf("engine: if-then condition %s must be boolean, found %s", clause.If, cond.Type)
 		}
-	case int16:
-		exprLiteral = &proto.Expression_Literal{
-			LiteralType: &proto.Expression_Literal_I16{
-				I16: int32(v),
-			},
+
+		result, err := clause.Then.Field(input)
+		if err != nil {
+			return nil, err
 		}
-	case int32:
-		exprLiteral = &proto.Expression_Literal{
-			LiteralType: &proto.Expression_Literal_I32{
-				I32: v,
-			},
+		results = append(results, result.Type)
+	}
+
+	if expr.els != nil {
+		result, err := expr.els.Field(input)
+		if err != nil {
+			return nil, err
 		}
-	case int64:
-		exprLiteral = &proto.Expression_Literal{
-			LiteralType: &proto.Expression_Literal_I64{
-				I64: v,
-			},
+		results = append(results, result.Type)
+	}
+
+	typ, err := unifyTypes(results...)
+	if err != nil {
+		return nil, fmt.Errorf("engine: if-then results: %w", err)
+	}
+
+	if expr.els == nil {
+		typ = bonobo.WithNullability(typ, types.NullabilityNullable)
+	}
+
+	return typ, nil
+}
+
+// String implements Expr.
+func (expr *IfThen) String() string {
+	var bldr strings.Builder
+	bldr.WriteString("CASE")
+	for _, clause := range expr.clauses {
+		fmt.Fprintf(&bldr, " WHEN %s THEN %s", clause.If, clause.Then)
+	}
+	if expr.els != nil {
+		fmt.Fprintf(&bldr, " ELSE %s", expr.els)
+	}
+	bldr.WriteString(" END")
+	return bldr.String()
+}
+
+// ToProto implements Expr.
+func (expr *IfThen) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
+	if _, err := expr.resultType(input); err != nil {
+		return nil, err
+	}
+
+	ifs := make([]*proto.Expression_IfThen_IfClause, len(expr.clauses))
+	for i, clause := range expr.clauses {
+		cond, err := clause.If.ToProto(input, extensions)
+		if err != nil {
+			return nil, err
 		}
-	case float32:
-		exprLiteral = &proto.Expression_Literal{
-			LiteralType: &proto.Expression_Literal_Fp32{
-				Fp32: v,
-			},
+
+		result, err := clause.Then.ToProto(input, extensions)
+		if err != nil {
+			return nil, err
 		}
-	case float64:
-		exprLiteral = &proto.Expression_Literal{
-			LiteralType: &proto.Expression_Literal_Fp64{
-				Fp64: v,
-			},
+
+		ifs[i] = &proto.Expression_IfThen_IfClause{If: cond, Then: result}
+	}
+
+	var els *proto.Expression
+	if expr.els != nil {
+		var err error
+		els, err = expr.els.ToProto(input, extensions)
+		if err != nil {
+			return nil, err
 		}
-	case string:
-		exprLiteral = &proto.Expression_Literal{
-			LiteralType: &proto.Expression_Literal_String_{
-				String_: v,
+	}
+
+	return &proto.Expression{
+		RexType: &proto.Expression_IfThen_{
+			IfThen: &proto.Expression_IfThen{
+				Ifs:  ifs,
+				Else: els,
 			},
+		},
+	}, nil
+}
+
+// SwitchCase is a value to compare to the match of a Switch and its result
+// if they are equal.
+type SwitchCase struct {
+	Value *Literal
+	Then  Expr
+}
+
+// NewSwitchExpr evaluates to the result of the first case whose value is
+// equal to match, or to els if none are. If els is nil, the result is null
+// when no value is equal.
+func NewSwitchExpr(match Expr, cases []SwitchCase, els Expr) *Switch {
+	return &Switch{match: match, cases: cases, els: els}
+}
+
+type Switch struct {
+	match Expr
+	cases []SwitchCase
+	els   Expr
+}
+
+// Field implements Expr.
+func (expr *Switch) Field(input Relation) (bonobo.Field, error) {
+	typ, err := expr.resultType(input)
+	if err != nil {
+		return bonobo.Field{}, err
+	}
+
+	return bonobo.Field{Name: expr.String(), Type: typ}, nil
+}
+
+// resultType checks the case values against the match and unifies the
+// types of the results.
+func (expr *Switch) resultType(input Relation) (bonobo.Type, error) {
+	if len(expr.cases) == 0 {
+		return nil, fmt.Errorf("engine: switch expression must have at least one case")
+	}
+
+	match, err := expr.match.Field(input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]bonobo.Type, 0, len(expr.cases)+1)
+	for _, c := range expr.cases {
+		value, err := c.Value.Field(input)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := unifyTypes(match.Type, value.Type); err != nil {
+			return nil, fmt.Errorf("engine: switch value %s does not match %s: %w", c.Value, expr.match, err)
+		}
+
+		result, err := c.Then.Field(input)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result.Type)
+	}
+
+	if expr.els != nil {
+		result, err := expr.els.Field(input)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result.Type)
+	}
+
+	typ, err := unifyTypes(results...)
+	if err != nil {
+		return nil, fmt.Errorf("engine: switch results: %w", err)
+	}
+
+	if expr.els == nil {
+		typ = bonobo.WithNullability(typ, types.NullabilityNullable)
+	}
+
+	return typ, nil
+}
+
+// String implements Expr.
+func (expr *Switch) String() string {
+	var bldr strings.Builder
+	fmt.Fprintf(&bldr, "CASE %s", expr.match)
+	for _, c := range expr.cases {
+		fmt.Fprintf(&bldr, " WHEN %s THEN %s", c.Value, c.Then)
+	}
+	if expr.els != nil {
+		fmt.Fprintf(&bldr, " ELSE %s", expr.els)
+	}
+	bldr.WriteString(" END")
+	return bldr.String()
+}
+
+// ToProto implements Expr.
+func (expr *Switch) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
+	if _, err := expr.resultType(input); err != nil {
+		return nil, err
+	}
+
+	match, err := expr.match.ToProto(input, extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	ifs := make([]*proto.Expression_SwitchExpression_IfValue, len(expr.cases))
+	for i, c := range expr.cases {
+		value, err := c.Value.ToProto(input, extensions)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := c.Then.ToProto(input, extensions)
+		if err != nil {
+			return nil, err
+		}
+
+		ifs[i] = &proto.Expression_SwitchExpression_IfValue{If: value.GetLiteral(), Then: result}
+	}
+
+	var els *proto.Expression
+	if expr.els != nil {
+		els, err = expr.els.ToProto(input, extensions)
+		if err != nil {
+			return nil, err
 		}
-	default:
-		panic(fmt.Sprintf("invalid literal type: %T", v))
 	}
 
 	return &proto.Expression{
-		RexType: &proto.Expression_Literal_{
-			Literal: exprLiteral,
+		RexType: &proto.Expression_SwitchExpression_{
+			SwitchExpression: &proto.Expression_SwitchExpression{
+				Match: match,
+				Ifs:   ifs,
+				Else:  els,
+			},
 		},
 	}, nil
 }
 
-func NewAliasExpr(expr Expr, name string) *Alias {
-	return &Alias{child: expr, alias: name}
+// NewInListExpr is true if value is equal to any of options, like
+// value IN (options...).
+func NewInListExpr(value Expr, options ...Expr) *InList {
+	return &InList{value: value, options: options}
 }
 
-type Alias struct {
-	child Expr
-	alias string
+type InList struct {
+	value   Expr
+	options []Expr
 }
 
 // Field implements Expr.
-func (expr *Alias) Field(input Relation) (bonobo.Field, error) {
-	field, err := expr.child.Field(input)
+func (expr *InList) Field(input Relation) (bonobo.Field, error) {
+	typ, err := expr.resultType(input)
 	if err != nil {
 		return bonobo.Field{}, err
 	}
 
-	field.Name = expr.alias
-	return field, nil
+	return bonobo.Field{Name: expr.String(), Type: typ}, nil
+}
+
+// resultType checks the options against the value. The result is null
+// if the value or any option is null.
+func (expr *InList) resultType(input Relation) (bonobo.Type, error) {
+	if len(expr.options) == 0 {
+		return nil, fmt.Errorf("engine: in list expression must have at least one option")
+	}
+
+	typ, err := listOptionsType(input, expr.value, expr.options)
+	if err != nil {
+		return nil, err
+	}
+
+	return bonobo.Types.BooleanType(typ.GetNullability() == types.NullabilityNullable), nil
 }
 
 // String implements Expr.
-func (expr *Alias) String() string {
-	return fmt.Sprintf("%s AS %s", expr.child.String(), expr.alias)
+func (expr *InList) String() string {
+	return fmt.Sprintf("%s IN (%s)", expr.value, ExprList(expr.options))
 }
 
 // ToProto implements Expr.
-func (expr *Alias) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
-	return expr.child.ToProto(input, extensions)
+func (expr *InList) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
+	if _, err := expr.resultType(input); err != nil {
+		return nil, err
+	}
+
+	value, err := expr.value.ToProto(input, extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := exprsToProto(input, extensions, expr.options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.Expression{
+		RexType: &proto.Expression_SingularOrList_{
+			SingularOrList: &proto.Expression_SingularOrList{
+				Value:   value,
+				Options: options,
+			},
+		},
+	}, nil
+}
+
+// NewMultiInListExpr is true if values are equal to all of the expressions
+// of any of options, like (values...) IN ((options[0]...), ...).
+func NewMultiInListExpr(values []Expr, options ...[]Expr) *MultiInList {
+	return &MultiInList{values: values, options: options}
+}
+
+type MultiInList struct {
+	values  []Expr
+	options [][]Expr
+}
+
+// Field implements Expr.
+func (expr *MultiInList) Field(input Relation) (bonobo.Field, error) {
+	typ, err := expr.resultType(input)
+	if err != nil {
+		return bonobo.Field{}, err
+	}
+
+	return bonobo.Field{Name: expr.String(), Type: typ}, nil
+}
+
+// resultType checks that each option has a matching expression for each
+// value. The result is null if any value or option is null.
+func (expr *MultiInList) resultType(input Relation) (bonobo.Type, error) {
+	if len(expr.values) == 0 {
+		return nil, fmt.Errorf("engine: multi in list expression must have at least one value")
+	}
+	if len(expr.options) == 0 {
+		return nil, fmt.Errorf("engine: multi in list expression must have at least one option")
+	}
+
+	nullable := false
+	for i := range expr.values {
+		column := make([]Expr, len(expr.options))
+		for j, option := range expr.options {
+			if len(option) != len(expr.values) {
+				return nil, fmt.Errorf("engine: multi in list option (%s) has %d expressions, expected %d", ExprList(option), len(option), len(expr.values))
+			}
+			column[j] = option[i]
+		}
+
+		typ, err := listOptionsType(input, expr.values[i], column)
+		if err != nil {
+			return nil, err
+		}
+		nullable = nullable || typ.GetNullability() == types.NullabilityNullable
+	}
+
+	return bonobo.Types.BooleanType(nullable), nil
+}
+
+// String implements Expr.
+func (expr *MultiInList) String() string {
+	options := make([]string, len(expr.options))
+	for i, option := range expr.options {
+		options[i] = fmt.Sprintf("(%s)", ExprList(option))
+	}
+
+	return fmt.Sprintf("(%s) IN (%s)", ExprList(expr.values), strings.Join(options, ", "))
+}
+
+// ToProto implements Expr.
+func (expr *MultiInList) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
+	if _, err := expr.resultType(input); err != nil {
+		return nil, err
+	}
+
+	values, err := exprsToProto(input, extensions, expr.values)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([]*proto.Expression_MultiOrList_Record, len(expr.options))
+	for i, option := range expr.options {
+		fields, err := exprsToProto(input, extensions, option)
+		if err != nil {
+			return nil, err
+		}
+		options[i] = &proto.Expression_MultiOrList_Record{Fields: fields}
+	}
+
+	return &proto.Expression{
+		RexType: &proto.Expression_MultiOrList_{
+			MultiOrList: &proto.Expression_MultiOrList{
+				Value:   values,
+				Options: options,
+			},
+		},
+	}, nil
+}
+
+// listOptionsType unifies the type of value with the types of options.
+func listOptionsType(input Relation, value Expr, options []Expr) (bonobo.Type, error) {
+	field, err := value.Field(input)
+	if err != nil {
+		return nil, err
+	}
+
+	typs := []bonobo.Type{field.Type}
+	for _, option := range options {
+		field, err := option.Field(input)
+		if err != nil {
+			return nil, err
+		}
+		typs = append(typs, field.Type)
+	}
+
+	typ, err := unifyTypes(typs...)
+	if err != nil {
+		return nil, fmt.Errorf("engine: in list options for %s: %w", value, err)
+	}
+
+	return typ, nil
+}
+
+func exprsToProto(input Relation, extensions *substrait.ExtensionRegistry, exprs []Expr) ([]*proto.Expression, error) {
+	result := make([]*proto.Expression, len(exprs))
+	for i, expr := range exprs {
+		var err error
+		result[i], err = expr.ToProto(input, extensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// unifyTypes returns the type that can hold a value of any of typs. The
+// types must be equal ignoring nullability, and the result is nullable if
+// any of them are.
+func unifyTypes(typs ...bonobo.Type) (bonobo.Type, error) {
+	if len(typs) == 0 {
+		return nil, fmt.Errorf("no types to unify")
+	}
+
+	nullability := types.NullabilityRequired
+	unified := bonobo.WithNullability(typs[0], nullability)
+	for _, typ := range typs {
+		if !bonobo.WithNullability(typ, types.NullabilityRequired).Equals(unified) {
+			return nil, fmt.Errorf("incompatible types %s and %s", unified, typ)
+		}
+		if typ.GetNullability() == types.NullabilityNullable {
+			nullability = types.NullabilityNullable
+		}
+	}
+
+	return bonobo.WithNullability(unified, nullability), nil
 }
 
 var _ Expr = (*Column)(nil)
 var _ Expr = (*ColumnIndex)(nil)
 var _ Expr = (*Literal)(nil)
 var _ Expr = (*Alias)(nil)
+var _ Expr = (*Cast)(nil)
+var _ Expr = (*IfThen)(nil)
+var _ Expr = (*Switch)(nil)
+var _ Expr = (*InList)(nil)
+var _ Expr = (*MultiInList)(nil)
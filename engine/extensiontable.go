@@ -0,0 +1,200 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/joellubi/bonobo"
+	"github.com/joellubi/bonobo/substrait"
+
+	"github.com/substrait-io/substrait-go/v3/proto"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+var DefaultExtensionTableCodecs = NewExtensionTableCodecRegistry()
+
+// ExtensionTableCodec converts the detail of an ExtensionTable to and from
+// the google.protobuf.Any it is serialized as.
+type ExtensionTableCodec interface {
+	// TypeURL is the type URL of the payloads handled by the codec.
+	TypeURL() string
+	Encode(detail any) (*anypb.Any, error)
+	Decode(payload *anypb.Any) (any, error)
+}
+
+func NewExtensionTableCodecRegistry() *ExtensionTableCodecRegistry {
+	return &ExtensionTableCodecRegistry{codecs: make(map[string]ExtensionTableCodec)}
+}
+
+type ExtensionTableCodecRegistry struct {
+	mu     sync.RWMutex
+	codecs map[string]ExtensionTableCodec
+}
+
+// Register adds codec to the registry, replacing any codec
+// previously registered for the same type URL.
+func (r *ExtensionTableCodecRegistry) Register(codec ExtensionTableCodec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.codecs[codec.TypeURL()] = codec
+}
+
+func (r *ExtensionTableCodecRegistry) Codec(typeURL string) (ExtensionTableCodec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	codec, ok := r.codecs[typeURL]
+	return codec, ok
+}
+
+// NewProtoMessageCodec creates a codec for details that are protobuf
+// messages of the same type as template.
+func NewProtoMessageCodec(template protobuf.Message) ExtensionTableCodec {
+	return &protoMessageCodec{template: template}
+}
+
+type protoMessageCodec struct {
+	template protobuf.Message
+}
+
+func (c *protoMessageCodec) TypeURL() string {
+	return "type.googleapis.com/" + string(c.template.ProtoReflect().Descriptor().FullName())
+}
+
+func (c *protoMessageCodec) Encode(detail any) (*anypb.Any, error) {
+	msg, ok := detail.(protobuf.Message)
+	if !ok {
+		return nil, fmt.Errorf("engine: expected protobuf message for %s, found %T", c.TypeURL(), detail)
+	}
+
+	return anypb.New(msg)
+}
+
+func (c *protoMessageCodec) Decode(payload *anypb.Any) (any, error) {
+	msg := c.template.ProtoReflect().New().Interface()
+	if err := payload.UnmarshalTo(msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// NewExtensionTable creates a table whose detail is serialized by the codec
+// registered for typeURL. A detail of type *anypb.Any is serialized as is,
+// which is how payloads without a registered codec are preserved, and must
+// have typeURL as its type URL.
+func NewExtensionTable(typeURL string, detail any, schema *bonobo.Schema) (*ExtensionTable, error) {
+	if payload, ok := detail.(*anypb.Any); ok && payload.GetTypeUrl() != typeURL {
+		return nil, fmt.Errorf("engine: extension table %s has detail with type %s", typeURL, payload.GetTypeUrl())
+	}
+
+	return &ExtensionTable{
+		typeURL: typeURL,
+		detail:  detail,
+		schema:  schema,
+		codecs:  DefaultExtensionTableCodecs,
+	}, nil
+}
+
+type ExtensionTable struct {
+	typeURL string
+	detail  any
+	schema  *bonobo.Schema
+
+	codecs *ExtensionTableCodecRegistry
+}
+
+func (t *ExtensionTable) TypeURL() string {
+	return t.typeURL
+}
+
+// Detail returns the decoded detail of the table, or the original
+// *anypb.Any if no codec was registered when it was deserialized.
+func (t *ExtensionTable) Detail() any {
+	return t.detail
+}
+
+func (t *ExtensionTable) Schema() (*bonobo.Schema, error) {
+	if t.schema == nil {
+		return nil, fmt.Errorf("%w: extension table %s has no schema", ErrUnknownSchema, t.typeURL)
+	}
+
+	return t.schema, nil
+}
+
+func (t *ExtensionTable) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	var baseSchema *proto.NamedStruct
+	if t.schema != nil {
+		var err error
+		if baseSchema, err = t.schema.ToProto(); err != nil {
+			return nil, err
+		}
+	}
+
+	detail, err := t.encodeDetail()
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.Rel{
+		RelType: &proto.Rel_Read{
+			Read: &proto.ReadRel{
+				BaseSchema: baseSchema,
+				ReadType: &proto.ReadRel_ExtensionTable_{
+					ExtensionTable: &proto.ReadRel_ExtensionTable{
+						Detail: detail,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (t *ExtensionTable) encodeDetail() (*anypb.Any, error) {
+	if payload, ok := t.detail.(*anypb.Any); ok {
+		return payload, nil
+	}
+
+	codec, ok := t.codecs.Codec(t.typeURL)
+	if !ok {
+		return nil, fmt.Errorf("engine: no extension table codec registered for %s", t.typeURL)
+	}
+
+	payload, err := codec.Encode(t.detail)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.GetTypeUrl() != t.typeURL {
+		return nil, fmt.Errorf("engine: extension table codec for %s encoded payload with type %s", t.typeURL, payload.GetTypeUrl())
+	}
+
+	return payload, nil
+}
+
+func extensionTableFromProto(schema *bonobo.Schema, tbl *proto.ReadRel_ExtensionTable, codecs *ExtensionTableCodecRegistry) (*ExtensionTable, error) {
+	payload := tbl.GetDetail()
+	if payload == nil {
+		return nil, fmt.Errorf("extension table has no detail")
+	}
+
+	var detail any = payload
+	if codec, ok := codecs.Codec(payload.GetTypeUrl()); ok {
+		var err error
+		detail, err = codec.Decode(payload)
+		if err != nil {
+			return nil, fmt.Errorf("decoding extension table detail %s: %w", payload.GetTypeUrl(), err)
+		}
+	}
+
+	return &ExtensionTable{
+		typeURL: payload.GetTypeUrl(),
+		detail:  detail,
+		schema:  schema,
+		codecs:  codecs,
+	}, nil
+}
+
+var _ Table = (*ExtensionTable)(nil)
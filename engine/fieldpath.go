@@ -0,0 +1,297 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joellubi/bonobo"
+	"github.com/joellubi/bonobo/substrait"
+
+	"github.com/substrait-io/substrait-go/v3/proto"
+	"github.com/substrait-io/substrait-go/v3/types"
+)
+
+type pathSegmentKind int
+
+const (
+	segmentStructField pathSegmentKind = iota
+	segmentListElement
+	segmentMapKey
+	segmentSubscript
+)
+
+// PathSegment selects a part of a nested value: a field of a struct, an
+// element of a list or the value of a key in a map.
+type PathSegment struct {
+	kind pathSegmentKind
+
+	// index is the position of a struct field, or the offset of a list element
+	index int
+	// name identifies a struct field by name if it is not empty
+	name string
+	key  *Literal
+}
+
+// StructFieldSegment selects the field at index of a struct.
+func StructFieldSegment(index int) PathSegment {
+	return PathSegment{kind: segmentStructField, index: index}
+}
+
+// NamedStructFieldSegment selects the field of a struct with the provided name.
+func NamedStructFieldSegment(name string) PathSegment {
+	return PathSegment{kind: segmentStructField, name: name}
+}
+
+// ListElementSegment selects the element of a list at offset, starting
+// from 0.
+func ListElementSegment(offset int) PathSegment {
+	return PathSegment{kind: segmentListElement, index: offset}
+}
+
+// MapKeySegment selects the value of a map for key.
+func MapKeySegment(key *Literal) PathSegment {
+	return PathSegment{kind: segmentMapKey, key: key}
+}
+
+// SubscriptSegment selects the value of a map for key, or the element of a
+// list at the integer position key, counting from 1 as in SQL. Which of them
+// it selects is resolved from the type of the value it is applied to.
+func SubscriptSegment(key *Literal) PathSegment {
+	return PathSegment{kind: segmentSubscript, key: key}
+}
+
+func (seg PathSegment) String() string {
+	switch seg.kind {
+	case segmentListElement:
+		return fmt.Sprintf("[%d]", seg.index)
+	case segmentMapKey, segmentSubscript:
+		return fmt.Sprintf("[%s]", seg.key)
+	default:
+		if seg.name != "" {
+			return "." + seg.name
+		}
+		return fmt.Sprintf(".#%d", seg.index)
+	}
+}
+
+// NewFieldPathExpr selects a part of the nested value of root by following
+// each of segments in turn, like root.field[0]['key'].
+func NewFieldPathExpr(root Expr, segments ...PathSegment) *FieldPath {
+	if path, ok := root.(*FieldPath); ok {
+		return &FieldPath{
+			root:     path.root,
+			segments: append(append([]PathSegment{}, path.segments...), segments...),
+		}
+	}
+
+	return &FieldPath{root: root, segments: segments}
+}
+
+// FieldPath is an expression referencing a part of a nested value, such as a
+// field of a struct column. The result is null if any value along the path
+// is null, or if a list element or map key is not present.
+type FieldPath struct {
+	root     Expr
+	segments []PathSegment
+}
+
+// Field implements Expr.
+func (expr *FieldPath) Field(input Relation) (bonobo.Field, error) {
+	field, segments, err := expr.resolve(input)
+	if err != nil {
+		return bonobo.Field{}, err
+	}
+
+	field.Name = fieldPathString(expr.root, segments)
+	return field, nil
+}
+
+// resolve follows the segments through the type of root, returning the
+// field that is referenced and the segments with the index of each struct
+// field resolved and each subscript replaced by a list element or map key.
+func (expr *FieldPath) resolve(input Relation) (bonobo.Field, []PathSegment, error) {
+	field, err := expr.root.Field(input)
+	if err != nil {
+		return bonobo.Field{}, nil, err
+	}
+
+	nullable := field.Type.GetNullability() == types.NullabilityNullable
+	resolved := make([]PathSegment, len(expr.segments))
+	for i, seg := range expr.segments {
+		if seg.kind == segmentSubscript {
+			if seg, err = resolveSubscript(field, seg.key); err != nil {
+				return bonobo.Field{}, nil, fmt.Errorf("engine: field path %s: %w", expr, err)
+			}
+		}
+
+		switch seg.kind {
+		case segmentStructField:
+			if seg.name != "" {
+				seg.index, err = field.StructFieldIndex(seg.name)
+				if err != nil {
+					return bonobo.Field{}, nil, fmt.Errorf("engine: field path %s: %w", expr, err)
+				}
+			}
+
+			field, err = field.StructField(seg.index)
+		case segmentListElement:
+			// The list may not have an element at the offset
+			nullable = true
+			field, err = field.ListElement()
+		case segmentMapKey:
+			var key bonobo.Field
+			if key, err = field.MapKey(); err == nil {
+				_, err = unifyTypes(key.Type, seg.key.typ)
+			}
+			if err != nil {
+				break
+			}
+
+			// The map may not contain the key
+			nullable = true
+			field, err = field.MapValue()
+		}
+		if err != nil {
+			return bonobo.Field{}, nil, fmt.Errorf("engine: field path %s: %w", expr, err)
+		}
+
+		resolved[i] = seg
+		nullable = nullable || field.Type.GetNullability() == types.NullabilityNullable
+	}
+
+	if nullable {
+		field.Type = bonobo.WithNullability(field.Type, types.NullabilityNullable)
+	}
+
+	return field, resolved, nil
+}
+
+// resolveSubscript selects the value of key if field is a map, or the
+// element at the position key, counting from 1, if it is a list.
+func resolveSubscript(field bonobo.Field, key *Literal) (PathSegment, error) {
+	switch field.Type.(type) {
+	case *types.MapType:
+		return MapKeySegment(key), nil
+	case *types.ListType:
+		position, ok := key.val.(int64)
+		if !ok || position < 1 {
+			return PathSegment{}, fmt.Errorf("list subscript must be an integer of at least 1, found %s", key)
+		}
+		return ListElementSegment(int(position - 1)), nil
+	default:
+		return PathSegment{}, fmt.Errorf("%s is not a list or map", field.Type)
+	}
+}
+
+// String implements Expr.
+func (expr *FieldPath) String() string {
+	return fieldPathString(expr.root, expr.segments)
+}
+
+func fieldPathString(root Expr, segments []PathSegment) string {
+	var b strings.Builder
+	b.WriteString(root.String())
+	for _, seg := range segments {
+		b.WriteString(seg.String())
+	}
+	return b.String()
+}
+
+// ToProto implements Expr. The segments extend the reference of root if it is
+// a field reference, and otherwise reference the result of the root
+// expression.
+func (expr *FieldPath) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
+	_, segments, err := expr.resolve(input)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := expr.root.ToProto(input, extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	var segment *proto.Expression_ReferenceSegment
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment, err = segments[i].toProto(segment, input, extensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if selection := root.GetSelection(); selection != nil && selection.GetDirectReference() != nil {
+		last := selection.GetDirectReference()
+		for child := referenceSegmentChild(last); child != nil; child = referenceSegmentChild(last) {
+			last = child
+		}
+		setReferenceSegmentChild(last, segment)
+
+		return root, nil
+	}
+
+	return &proto.Expression{
+		RexType: &proto.Expression_Selection{
+			Selection: &proto.Expression_FieldReference{
+				ReferenceType: &proto.Expression_FieldReference_DirectReference{DirectReference: segment},
+				RootType:      &proto.Expression_FieldReference_Expression{Expression: root},
+			},
+		},
+	}, nil
+}
+
+// toProto creates the reference segment for seg followed by child. The index
+// of a struct field must be resolved by name, and a subscript resolved to a
+// list element or map key, before it is serialized.
+func (seg PathSegment) toProto(child *proto.Expression_ReferenceSegment, input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression_ReferenceSegment, error) {
+	switch seg.kind {
+	case segmentListElement:
+		return &proto.Expression_ReferenceSegment{
+			ReferenceType: &proto.Expression_ReferenceSegment_ListElement_{
+				ListElement: &proto.Expression_ReferenceSegment_ListElement{Offset: int32(seg.index), Child: child},
+			},
+		}, nil
+	case segmentMapKey:
+		key, err := seg.key.ToProto(input, extensions)
+		if err != nil {
+			return nil, err
+		}
+
+		return &proto.Expression_ReferenceSegment{
+			ReferenceType: &proto.Expression_ReferenceSegment_MapKey_{
+				MapKey: &proto.Expression_ReferenceSegment_MapKey{MapKey: key.GetLiteral(), Child: child},
+			},
+		}, nil
+	default:
+		return &proto.Expression_ReferenceSegment{
+			ReferenceType: &proto.Expression_ReferenceSegment_StructField_{
+				StructField: &proto.Expression_ReferenceSegment_StructField{Field: int32(seg.index), Child: child},
+			},
+		}, nil
+	}
+}
+
+func referenceSegmentChild(seg *proto.Expression_ReferenceSegment) *proto.Expression_ReferenceSegment {
+	switch s := seg.GetReferenceType().(type) {
+	case *proto.Expression_ReferenceSegment_StructField_:
+		return s.StructField.GetChild()
+	case *proto.Expression_ReferenceSegment_ListElement_:
+		return s.ListElement.GetChild()
+	case *proto.Expression_ReferenceSegment_MapKey_:
+		return s.MapKey.GetChild()
+	default:
+		return nil
+	}
+}
+
+func setReferenceSegmentChild(seg, child *proto.Expression_ReferenceSegment) {
+	switch s := seg.GetReferenceType().(type) {
+	case *proto.Expression_ReferenceSegment_StructField_:
+		s.StructField.Child = child
+	case *proto.Expression_ReferenceSegment_ListElement_:
+		s.ListElement.Child = child
+	case *proto.Expression_ReferenceSegment_MapKey_:
+		s.MapKey.Child = child
+	}
+}
+
+var _ Expr = (*FieldPath)(nil)
@@ -8,7 +8,6 @@ import (
 	"github.com/joellubi/bonobo/substrait"
 
 	"github.com/substrait-io/substrait-go/v3/proto"
-	"github.com/substrait-io/substrait-go/v3/types"
 )
 
 var DefaultFunctionRepository = substrait.NewFunctionRepository()
@@ -17,6 +16,16 @@ func init() {
 	// TODO: Improve
 	DefaultFunctionRepository.RegisterImplementation("https://github.com/substrait-io/substrait/blob/main/extensions/functions_arithmetic.yaml", "add", &addI8Impl{})
 	DefaultFunctionRepository.RegisterImplementation("https://github.com/substrait-io/substrait/blob/main/extensions/functions_arithmetic.yaml", "add", &addI64Impl{})
+
+	for _, uri := range []string{
+		"https://github.com/substrait-io/substrait/blob/main/extensions/functions_arithmetic.yaml",
+		"https://github.com/substrait-io/substrait/blob/main/extensions/functions_boolean.yaml",
+		"https://github.com/substrait-io/substrait/blob/main/extensions/functions_comparison.yaml",
+	} {
+		if err := substrait.RegisterImplementationsFromURI(DefaultFunctionRepository, uri); err != nil {
+			panic(err)
+		}
+	}
 }
 
 func NewFunctionExpr(uri, name string, args ...Expr) *Function {
@@ -28,6 +37,14 @@ func NewFunctionExpr(uri, name string, args ...Expr) *Function {
 	}
 }
 
+func NewSumFunctionExpr(arg Expr) *AggregateFunction {
+	return NewAggregateFunctionExpr(
+		"https://github.com/substrait-io/substrait/blob/main/extensions/functions_arithmetic.yaml",
+		"sum",
+		arg,
+	)
+}
+
 func NewAddFunctionExpr(left, right Expr) *Function {
 	return NewFunctionExpr(
 		"https://github.com/substrait-io/substrait/blob/main/extensions/functions_arithmetic.yaml",
@@ -63,13 +80,9 @@ type Function struct {
 
 // Field implements Expr.
 func (f *Function) Field(input Relation) (bonobo.Field, error) {
-	args := make([]bonobo.Type, len(f.args))
-	for i, arg := range f.args {
-		field, err := arg.Field(input)
-		if err != nil {
-			return bonobo.Field{}, err
-		}
-		args[i] = field.Type
+	args, err := argumentTypes(input, f.args)
+	if err != nil {
+		return bonobo.Field{}, err
 	}
 
 	// TODO
@@ -107,13 +120,9 @@ func (f *Function) String() string {
 // TODO: Consolidate with Field()?
 // ToProto implements Expr.
 func (f *Function) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
-	args := make([]bonobo.Type, len(f.args))
-	for i, arg := range f.args {
-		field, err := arg.Field(input)
-		if err != nil {
-			return nil, err
-		}
-		args[i] = field.Type
+	args, err := argumentTypes(input, f.args)
+	if err != nil {
+		return nil, err
 	}
 
 	impl, err := f.repository.GetImplementation(f.uri, f.name, args...)
@@ -121,25 +130,23 @@ func (f *Function) ToProto(input Relation, extensions *substrait.ExtensionRegist
 		return nil, err
 	}
 
+	if _, ok := impl.(substrait.AggregateFunctionImplementation); ok {
+		return nil, fmt.Errorf("cannot use aggregate function %s as a scalar function", impl.Signature())
+	}
+
 	returnType, err := impl.ReturnType(args...)
 	if err != nil {
 		return nil, err
 	}
 
-	outputType := types.TypeToProto(returnType)
-
-	functionArgs := make([]*proto.FunctionArgument, len(f.args))
-	for i, arg := range f.args {
-		expr, err := arg.ToProto(input, extensions)
-		if err != nil {
-			return nil, err
-		}
+	outputType, err := bonobo.TypeToProto(returnType)
+	if err != nil {
+		return nil, err
+	}
 
-		functionArgs[i] = &proto.FunctionArgument{
-			ArgType: &proto.FunctionArgument_Value{
-				Value: expr,
-			},
-		}
+	functionArgs, err := functionArguments(input, extensions, f.args)
+	if err != nil {
+		return nil, err
 	}
 
 	ref := extensions.RegisterFunction(f.uri, impl.Signature())
@@ -156,6 +163,221 @@ func (f *Function) ToProto(input Relation, extensions *substrait.ExtensionRegist
 	}, nil
 }
 
+// AggregationPhase describes which part of a decomposed aggregation an
+// AggregateFunction computes.
+type AggregationPhase proto.AggregationPhase
+
+const (
+	AggregationPhaseUnspecified                = AggregationPhase(proto.AggregationPhase_AGGREGATION_PHASE_UNSPECIFIED)
+	AggregationPhaseInitialToIntermediate      = AggregationPhase(proto.AggregationPhase_AGGREGATION_PHASE_INITIAL_TO_INTERMEDIATE)
+	AggregationPhaseIntermediateToIntermediate = AggregationPhase(proto.AggregationPhase_AGGREGATION_PHASE_INTERMEDIATE_TO_INTERMEDIATE)
+	AggregationPhaseInitialToResult            = AggregationPhase(proto.AggregationPhase_AGGREGATION_PHASE_INITIAL_TO_RESULT)
+	AggregationPhaseIntermediateToResult       = AggregationPhase(proto.AggregationPhase_AGGREGATION_PHASE_INTERMEDIATE_TO_RESULT)
+)
+
+// OutputsIntermediate reports whether the phase produces an intermediate
+// result rather than the final result of the aggregation.
+func (p AggregationPhase) OutputsIntermediate() bool {
+	return p == AggregationPhaseInitialToIntermediate || p == AggregationPhaseIntermediateToIntermediate
+}
+
+// AggregationInvocation describes whether duplicate records are merged before
+// they are aggregated.
+type AggregationInvocation proto.AggregateFunction_AggregationInvocation
+
+const (
+	AggregationInvocationUnspecified = AggregationInvocation(proto.AggregateFunction_AGGREGATION_INVOCATION_UNSPECIFIED)
+	AggregationInvocationAll         = AggregationInvocation(proto.AggregateFunction_AGGREGATION_INVOCATION_ALL)
+	AggregationInvocationDistinct    = AggregationInvocation(proto.AggregateFunction_AGGREGATION_INVOCATION_DISTINCT)
+)
+
+func NewAggregateFunctionExpr(uri, name string, args ...Expr) *AggregateFunction {
+	return &AggregateFunction{
+		uri:        uri,
+		name:       name,
+		args:       args,
+		repository: DefaultFunctionRepository,
+		phase:      AggregationPhaseInitialToResult,
+		invocation: AggregationInvocationAll,
+	}
+}
+
+func NewAnonymousAggregateFunction(uri, signature string, output bonobo.Type, args ...Expr) (*AggregateFunction, error) {
+	repo := substrait.NewAnonymousFunctionRepository(signature, output)
+
+	name, _, found := strings.Cut(signature, ":")
+	if !found {
+		return nil, fmt.Errorf("invalid function signature: %s", signature)
+	}
+
+	return &AggregateFunction{
+		uri:        uri,
+		name:       name,
+		args:       args,
+		repository: repo,
+		phase:      AggregationPhaseInitialToResult,
+		invocation: AggregationInvocationAll,
+	}, nil
+}
+
+type AggregateFunction struct {
+	uri, name  string
+	args       []Expr
+	repository substrait.FunctionRepository
+
+	phase      AggregationPhase
+	invocation AggregationInvocation
+}
+
+// WithPhase returns a copy of the function that computes the provided phase
+// of the aggregation.
+func (f *AggregateFunction) WithPhase(phase AggregationPhase) *AggregateFunction {
+	out := *f
+	out.phase = phase
+	return &out
+}
+
+// WithInvocation returns a copy of the function with the provided invocation.
+func (f *AggregateFunction) WithInvocation(invocation AggregationInvocation) *AggregateFunction {
+	out := *f
+	out.invocation = invocation
+	return &out
+}
+
+// Distinct returns a copy of the function that only aggregates distinct values.
+func (f *AggregateFunction) Distinct() *AggregateFunction {
+	return f.WithInvocation(AggregationInvocationDistinct)
+}
+
+// Field implements AggregateExpr.
+func (f *AggregateFunction) Field(input Relation) (bonobo.Field, error) {
+	_, outputType, err := f.resolve(input)
+	if err != nil {
+		return bonobo.Field{}, err
+	}
+
+	return bonobo.Field{Name: f.String(), Type: outputType}, nil
+}
+
+// String implements AggregateExpr.
+func (f *AggregateFunction) String() string {
+	args := make([]string, len(f.args))
+	for i, arg := range f.args {
+		args[i] = arg.String()
+	}
+
+	var distinct string
+	if f.invocation == AggregationInvocationDistinct {
+		distinct = "DISTINCT "
+	}
+
+	return fmt.Sprintf("%s(%s%s)", f.name, distinct, strings.Join(args, ", "))
+}
+
+// ToProto implements AggregateExpr.
+func (f *AggregateFunction) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.AggregateFunction, error) {
+	impl, outputType, err := f.resolve(input)
+	if err != nil {
+		return nil, err
+	}
+
+	functionArgs, err := functionArguments(input, extensions, f.args)
+	if err != nil {
+		return nil, err
+	}
+
+	outputTypeProto, err := bonobo.TypeToProto(outputType)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := extensions.RegisterFunction(f.uri, impl.Signature())
+
+	return &proto.AggregateFunction{
+		FunctionReference: ref,
+		Arguments:         functionArgs,
+		OutputType:        outputTypeProto,
+		Phase:             proto.AggregationPhase(f.phase),
+		Invocation:        proto.AggregateFunction_AggregationInvocation(f.invocation),
+	}, nil
+}
+
+// resolve finds the implementation matching the function arguments and
+// determines the output type for the phase being computed.
+//
+// Implementations that do not describe their aggregation properties, such as
+// those of anonymous functions, always output the type they declare.
+func (f *AggregateFunction) resolve(input Relation) (substrait.FunctionImplementation, bonobo.Type, error) {
+	args, err := argumentTypes(input, f.args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	impl, err := f.repository.GetImplementation(f.uri, f.name, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, ok := impl.(substrait.WindowFunctionImplementation); ok {
+		return nil, nil, fmt.Errorf("cannot use window function %s as an aggregate function", impl.Signature())
+	}
+
+	outputType, err := aggregateOutputType(impl, f.phase, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return impl, outputType, nil
+}
+
+// aggregateOutputType determines the type that impl outputs when computing
+// phase of an aggregation of args.
+func aggregateOutputType(impl substrait.FunctionImplementation, phase AggregationPhase, args []bonobo.Type) (bonobo.Type, error) {
+	aggImpl, ok := impl.(substrait.AggregateFunctionImplementation)
+	if !ok {
+		return impl.ReturnType(args...)
+	}
+
+	if phase != AggregationPhaseInitialToResult && aggImpl.Decomposability() == substrait.DecomposableNone {
+		return nil, fmt.Errorf("aggregate function %s is not decomposable and must be computed in a single phase", impl.Signature())
+	}
+
+	if phase.OutputsIntermediate() {
+		return aggImpl.IntermediateType(args...)
+	}
+
+	return aggImpl.ReturnType(args...)
+}
+
+func argumentTypes(input Relation, args []Expr) ([]bonobo.Type, error) {
+	types := make([]bonobo.Type, len(args))
+	for i, arg := range args {
+		field, err := arg.Field(input)
+		if err != nil {
+			return nil, err
+		}
+		types[i] = field.Type
+	}
+	return types, nil
+}
+
+func functionArguments(input Relation, extensions *substrait.ExtensionRegistry, args []Expr) ([]*proto.FunctionArgument, error) {
+	functionArgs := make([]*proto.FunctionArgument, len(args))
+	for i, arg := range args {
+		expr, err := arg.ToProto(input, extensions)
+		if err != nil {
+			return nil, err
+		}
+
+		functionArgs[i] = &proto.FunctionArgument{
+			ArgType: &proto.FunctionArgument_Value{
+				Value: expr,
+			},
+		}
+	}
+	return functionArgs, nil
+}
+
 type addI8Impl struct{}
 
 func (impl *addI8Impl) Name() string {
@@ -215,3 +437,4 @@ func (impl *addI64Impl) ReturnType(inputs ...bonobo.Type) (bonobo.Type, error) {
 }
 
 var _ Expr = (*Function)(nil)
+var _ AggregateExpr = (*AggregateFunction)(nil)
@@ -0,0 +1,763 @@
+package engine
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/joellubi/bonobo"
+	"github.com/joellubi/bonobo/substrait"
+
+	"github.com/apache/arrow/go/v17/arrow/decimal128"
+	"github.com/substrait-io/substrait-go/v3/proto"
+	"github.com/substrait-io/substrait-go/v3/types"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ListValue is the value of a list literal. There must be at least one
+// element, and they must all have the same type. Empty lists are created
+// with NewEmptyLiteral.
+type ListValue []*Literal
+
+// StructValue is the value of a struct literal with a field for each literal.
+type StructValue []*Literal
+
+// MapValue is the value of a map literal. There must be at least one entry,
+// and the keys and values must each have the same type. Empty maps are
+// created with NewEmptyLiteral.
+type MapValue []MapLiteralEntry
+
+// MapLiteralEntry is a key of a map literal and the value that it maps to.
+type MapLiteralEntry struct {
+	Key   *Literal
+	Value *Literal
+}
+
+// UserDefinedValue is the value of a literal of the user-defined type named
+// Name in the extension at URI, which is declared by the plan the literal is
+// serialized in. TypeReference identifies the type in the query, like the
+// reference of bonobo.Types.UserDefinedType, and is the anchor that the plan
+// declared the type with when the literal is deserialized.
+//
+// Exactly one of Value, a message specific to the type, and Struct, which has
+// a field for each field in the structure the type is declared with, is set.
+type UserDefinedValue struct {
+	TypeReference uint32
+	URI, Name     string
+	Parameters    []types.TypeParam
+	Value         *anypb.Any
+	Struct        StructValue
+}
+
+// NewLiteral creates a literal of val, which must have one of the types:
+//
+//   - bool, int8, int16, int32, int64, int, float32, float64 or string
+//   - []byte, types.FixedBinary, types.FixedChar, *types.VarChar or types.UUID
+//   - types.Date, types.Time, types.Timestamp or types.TimestampTz, which
+//     count days or microseconds since the Unix epoch, or since midnight
+//   - *types.PrecisionTimestamp or *types.PrecisionTimestampTz
+//   - *types.IntervalYearToMonth, *types.IntervalDayToSecond or
+//     *proto.Expression_Literal_IntervalCompound
+//   - *types.Decimal
+//   - ListValue, StructValue or MapValue
+//   - *UserDefinedValue
+//
+// The literal is not nullable. Null values are created with NewNullLiteral.
+// An error is returned if val has any other type, or is not a valid value of
+// its type.
+func NewLiteral(val any) (*Literal, error) {
+	if v, ok := val.(int); ok {
+		val = int64(v)
+	}
+
+	typ, err := literalType(val)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Literal{val: val, typ: typ}, nil
+}
+
+// MustLiteral is like NewLiteral but panics if the literal cannot be created.
+// It is intended for values that are known to be valid, such as constants.
+func MustLiteral(val any) *Literal {
+	lit, err := NewLiteral(val)
+	if err != nil {
+		panic(err)
+	}
+
+	return lit
+}
+
+// NewLiteralExpr creates a literal of val, panicking if it is invalid.
+//
+// Deprecated: Use NewLiteral, or MustLiteral for values known to be valid.
+func NewLiteralExpr(val any) *Literal {
+	return MustLiteral(val)
+}
+
+// NewNullLiteral creates a null literal of typ, which is made nullable.
+func NewNullLiteral(typ bonobo.Type) *Literal {
+	return &Literal{typ: bonobo.WithNullability(typ, types.NullabilityNullable)}
+}
+
+// NewEmptyLiteral creates an empty list or map literal of typ.
+func NewEmptyLiteral(typ bonobo.Type) (*Literal, error) {
+	switch typ.(type) {
+	case *types.ListType:
+		return &Literal{val: ListValue{}, typ: typ}, nil
+	case *types.MapType:
+		return &Literal{val: MapValue{}, typ: typ}, nil
+	default:
+		return nil, fmt.Errorf("engine: empty literal must be a list or map, found %s", typ)
+	}
+}
+
+// NewDecimalLiteral creates a decimal literal of num, which must fit in
+// precision digits, scale of which are after the decimal point.
+func NewDecimalLiteral(num decimal128.Num, precision, scale int32) (*Literal, error) {
+	return NewLiteral(&types.Decimal{Value: decimalBytes(num), Precision: precision, Scale: scale})
+}
+
+// Literal is a constant value, which is nil if the literal is null.
+type Literal struct {
+	val any
+	typ bonobo.Type
+}
+
+func (expr *Literal) Field(input Relation) (bonobo.Field, error) {
+	return bonobo.Field{Name: expr.Name(), Type: expr.typ}, nil
+}
+
+func (expr *Literal) String() string {
+	return fmt.Sprintf("%s::%s", expr.Name(), expr.typ)
+}
+
+func (expr *Literal) Name() string {
+	switch v := expr.val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case int8:
+		return strconv.FormatInt(int64(v), 10)
+	case int16:
+		return strconv.FormatInt(int64(v), 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	case []byte:
+		return "0x" + hex.EncodeToString(v)
+	case types.FixedBinary:
+		return "0x" + hex.EncodeToString(v)
+	case types.FixedChar:
+		return string(v)
+	case *types.VarChar:
+		return v.GetValue()
+	case types.UUID:
+		s := hex.EncodeToString(v)
+		return strings.Join([]string{s[:8], s[8:12], s[12:16], s[16:20], s[20:]}, "-")
+	case types.Date:
+		return time.Unix(0, 0).UTC().AddDate(0, 0, int(v)).Format(time.DateOnly)
+	case types.Time:
+		return time.UnixMicro(int64(v)).UTC().Format("15:04:05.999999")
+	case types.Timestamp:
+		return time.UnixMicro(int64(v)).UTC().Format("2006-01-02 15:04:05.999999")
+	case types.TimestampTz:
+		return time.UnixMicro(int64(v)).UTC().Format("2006-01-02 15:04:05.999999Z07:00")
+	case *types.PrecisionTimestamp:
+		return precisionTime(v.PrecisionTimestamp).Format("2006-01-02 15:04:05.999999999")
+	case *types.PrecisionTimestampTz:
+		return precisionTime(v.PrecisionTimestampTz).Format("2006-01-02 15:04:05.999999999Z07:00")
+	case *types.IntervalYearToMonth:
+		return fmt.Sprintf("P%dY%dM", v.GetYears(), v.GetMonths())
+	case *types.IntervalDayToSecond:
+		return "P" + formatIntervalDay(v)
+	case *proto.Expression_Literal_IntervalCompound:
+		years := v.GetIntervalYearToMonth()
+		return fmt.Sprintf("P%dY%dM%s", years.GetYears(), years.GetMonths(), formatIntervalDay(v.GetIntervalDayToSecond()))
+	case *types.Decimal:
+		num, _ := decimalFromBytes(v.GetValue())
+		return num.ToString(v.GetScale())
+	case ListValue:
+		return fmt.Sprintf("[%s]", literalNames(v))
+	case StructValue:
+		return fmt.Sprintf("STRUCT(%s)", literalNames(v))
+	case MapValue:
+		entries := make([]string, len(v))
+		for i, entry := range v {
+			entries[i] = fmt.Sprintf("%s, %s", entry.Key.Name(), entry.Value.Name())
+		}
+		return fmt.Sprintf("MAP(%s)", strings.Join(entries, ", "))
+	case *UserDefinedValue:
+		if v.Struct != nil {
+			return fmt.Sprintf("%s(%s)", v.Name, literalNames(v.Struct))
+		}
+		return fmt.Sprintf("%s(0x%s)", v.Name, hex.EncodeToString(v.Value.GetValue()))
+	default:
+		// Literals are validated when they are created, so this is only
+		// reachable for a Literal that was not created by a constructor
+		return fmt.Sprintf("<invalid literal %T>", v)
+	}
+}
+
+func (expr *Literal) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
+	exprLiteral, err := literalToProto(expr, extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.Expression{
+		RexType: &proto.Expression_Literal_{
+			Literal: exprLiteral,
+		},
+	}, nil
+}
+
+// literalType derives the type of a literal of val, checking that it is a
+// valid value of the type.
+func literalType(val any) (bonobo.Type, error) {
+	required := types.NullabilityRequired
+
+	switch v := val.(type) {
+	case bool:
+		return &types.BooleanType{Nullability: required}, nil
+	case int8:
+		return &types.Int8Type{Nullability: required}, nil
+	case int16:
+		return &types.Int16Type{Nullability: required}, nil
+	case int32:
+		return &types.Int32Type{Nullability: required}, nil
+	case int64:
+		return &types.Int64Type{Nullability: required}, nil
+	case float32:
+		return &types.Float32Type{Nullability: required}, nil
+	case float64:
+		return &types.Float64Type{Nullability: required}, nil
+	case string:
+		return &types.StringType{Nullability: required}, nil
+	case []byte:
+		return &types.BinaryType{Nullability: required}, nil
+	case types.FixedBinary:
+		return &types.FixedBinaryType{Nullability: required, Length: int32(len(v))}, nil
+	case types.FixedChar:
+		return &types.FixedCharType{Nullability: required, Length: int32(utf8.RuneCountInString(string(v)))}, nil
+	case *types.VarChar:
+		if utf8.RuneCountInString(v.GetValue()) > int(v.GetLength()) {
+			return nil, fmt.Errorf("engine: varchar literal %q is longer than %d characters", v.GetValue(), v.GetLength())
+		}
+		return &types.VarCharType{Nullability: required, Length: int32(v.GetLength())}, nil
+	case types.UUID:
+		if len(v) != 16 {
+			return nil, fmt.Errorf("engine: uuid literal must be 16 bytes, found %d", len(v))
+		}
+		return &types.UUIDType{Nullability: required}, nil
+	case types.Date:
+		return &types.DateType{Nullability: required}, nil
+	case types.Time:
+		return &types.TimeType{Nullability: required}, nil
+	case types.Timestamp:
+		return &types.TimestampType{Nullability: required}, nil
+	case types.TimestampTz:
+		return &types.TimestampTzType{Nullability: required}, nil
+	case *types.PrecisionTimestamp:
+		precision, err := bonobo.TimestampPrecision(v.PrecisionTimestamp.GetPrecision())
+		if err != nil {
+			return nil, fmt.Errorf("engine: precision timestamp literal: %w", err)
+		}
+		return &types.PrecisionTimestampType{Nullability: required, Precision: precision}, nil
+	case *types.PrecisionTimestampTz:
+		precision, err := bonobo.TimestampPrecision(v.PrecisionTimestampTz.GetPrecision())
+		if err != nil {
+			return nil, fmt.Errorf("engine: precision timestamp literal: %w", err)
+		}
+		return &types.PrecisionTimestampTzType{
+			PrecisionTimestampType: types.PrecisionTimestampType{Nullability: required, Precision: precision},
+		}, nil
+	case *types.IntervalYearToMonth:
+		return &types.IntervalYearType{Nullability: required}, nil
+	case *types.IntervalDayToSecond:
+		precision, _, err := intervalDaySubseconds(v)
+		if err != nil {
+			return nil, err
+		}
+		return &types.IntervalDayType{Nullability: required, Precision: precision}, nil
+	case *proto.Expression_Literal_IntervalCompound:
+		precision, _, err := intervalDaySubseconds(v.GetIntervalDayToSecond())
+		if err != nil {
+			return nil, err
+		}
+		return types.NewIntervalCompoundType().WithPrecision(precision).WithNullability(required), nil
+	case *types.Decimal:
+		num, err := decimalFromBytes(v.GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("engine: %w", err)
+		}
+		if v.GetPrecision() < 1 || v.GetPrecision() > 38 || v.GetScale() < 0 || v.GetScale() > v.GetPrecision() {
+			return nil, fmt.Errorf("engine: invalid decimal literal precision %d and scale %d", v.GetPrecision(), v.GetScale())
+		}
+		if !num.FitsInPrecision(v.GetPrecision()) {
+			return nil, fmt.Errorf("engine: decimal literal %s does not fit in precision %d", num.ToString(v.GetScale()), v.GetPrecision())
+		}
+		return &types.DecimalType{Nullability: required, Precision: v.GetPrecision(), Scale: v.GetScale()}, nil
+	case *UserDefinedValue:
+		if v.URI == "" || v.Name == "" {
+			return nil, fmt.Errorf("engine: user-defined literal must have the URI and name of its type")
+		}
+		if (v.Value == nil) == (v.Struct == nil) {
+			return nil, fmt.Errorf("engine: user-defined literal of type %s must have exactly one of a value or struct", v.Name)
+		}
+		for i, field := range v.Struct {
+			if field == nil {
+				return nil, fmt.Errorf("engine: user-defined literal struct field %d is missing", i)
+			}
+		}
+		return &types.UserDefinedType{Nullability: required, TypeReference: v.TypeReference, TypeParameters: v.Parameters}, nil
+	case ListValue:
+		if len(v) == 0 {
+			return nil, fmt.Errorf("engine: list literal must have at least one value")
+		}
+
+		elem, err := unifyLiteralTypes(v)
+		if err != nil {
+			return nil, fmt.Errorf("engine: list literal values: %w", err)
+		}
+		return &types.ListType{Nullability: required, Type: elem}, nil
+	case StructValue:
+		fields := make([]types.Type, len(v))
+		for i, field := range v {
+			if field == nil {
+				return nil, fmt.Errorf("engine: struct literal field %d is missing", i)
+			}
+			fields[i] = field.typ
+		}
+		return &types.StructType{Nullability: required, Types: fields}, nil
+	case MapValue:
+		if len(v) == 0 {
+			return nil, fmt.Errorf("engine: map literal must have at least one entry")
+		}
+
+		keys := make([]*Literal, len(v))
+		values := make([]*Literal, len(v))
+		for i, entry := range v {
+			keys[i], values[i] = entry.Key, entry.Value
+		}
+
+		keyType, err := unifyLiteralTypes(keys)
+		if err != nil {
+			return nil, fmt.Errorf("engine: map literal keys: %w", err)
+		}
+		valueType, err := unifyLiteralTypes(values)
+		if err != nil {
+			return nil, fmt.Errorf("engine: map literal values: %w", err)
+		}
+		return &types.MapType{Nullability: required, Key: keyType, Value: valueType}, nil
+	default:
+		return nil, fmt.Errorf("engine: invalid literal type: %T", v)
+	}
+}
+
+func unifyLiteralTypes(lits []*Literal) (bonobo.Type, error) {
+	typs := make([]bonobo.Type, len(lits))
+	for i, lit := range lits {
+		if lit == nil {
+			return nil, fmt.Errorf("literal %d is missing", i)
+		}
+		typs[i] = lit.typ
+	}
+
+	return unifyTypes(typs...)
+}
+
+func literalNames(lits []*Literal) string {
+	names := make([]string, len(lits))
+	for i, lit := range lits {
+		names[i] = lit.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+// intervalDaySubseconds returns the precision and number of the subseconds
+// of interval, which are microseconds if it does not specify a precision.
+func intervalDaySubseconds(interval *types.IntervalDayToSecond) (types.TimePrecision, int64, error) {
+	switch mode := interval.GetPrecisionMode().(type) {
+	case *proto.Expression_Literal_IntervalDayToSecond_Precision:
+		precision, err := types.ProtoToTimePrecision(mode.Precision)
+		if err != nil {
+			return types.PrecisionUnknown, 0, fmt.Errorf("engine: interval literal: %w", err)
+		}
+		return precision, interval.GetSubseconds(), nil
+	case *proto.Expression_Literal_IntervalDayToSecond_Microseconds:
+		return types.PrecisionMicroSeconds, int64(mode.Microseconds), nil
+	default:
+		return types.PrecisionMicroSeconds, 0, nil
+	}
+}
+
+// formatIntervalDay formats the days and seconds of interval as the time
+// part of an ISO 8601 duration, like 1DT2.5S.
+func formatIntervalDay(interval *types.IntervalDayToSecond) string {
+	precision, subseconds, _ := intervalDaySubseconds(interval)
+	scale := int32(precision)
+	total := decimal128.FromI64(int64(interval.GetSeconds())).
+		Mul(decimal128.GetScaleMultiplier(int(scale))).
+		Add(decimal128.FromI64(subseconds))
+
+	seconds := total.ToString(scale)
+	if strings.Contains(seconds, ".") {
+		seconds = strings.TrimSuffix(strings.TrimRight(seconds, "0"), ".")
+	}
+
+	return fmt.Sprintf("%dDT%sS", interval.GetDays(), seconds)
+}
+
+// precisionTime converts a timestamp counted in units of its precision
+// since the Unix epoch. Digits beyond nanoseconds are truncated.
+func precisionTime(ts *proto.Expression_Literal_PrecisionTimestamp) time.Time {
+	unit := int64(1)
+	for range ts.GetPrecision() {
+		unit *= 10
+	}
+
+	sec, frac := ts.GetValue()/unit, ts.GetValue()%unit
+	if frac < 0 {
+		sec, frac = sec-1, frac+unit
+	}
+
+	if unit > int64(time.Second) {
+		return time.Unix(sec, frac/(unit/int64(time.Second))).UTC()
+	}
+	return time.Unix(sec, frac*(int64(time.Second)/unit)).UTC()
+}
+
+// literalToProto serializes lit, which is marked nullable if its type is. The
+// types of user-defined literals are declared in extensions.
+func literalToProto(lit *Literal, extensions *substrait.ExtensionRegistry) (*proto.Expression_Literal, error) {
+	if lit.val == nil {
+		typ, err := bonobo.TypeToProto(lit.typ)
+		if err != nil {
+			return nil, err
+		}
+
+		return &proto.Expression_Literal{
+			LiteralType: &proto.Expression_Literal_Null{Null: typ},
+			Nullable:    true,
+		}, nil
+	}
+
+	exprLiteral := &proto.Expression_Literal{
+		Nullable: lit.typ.GetNullability() == types.NullabilityNullable,
+	}
+
+	switch v := lit.val.(type) {
+	case bool:
+		exprLiteral.LiteralType = &proto.Expression_Literal_Boolean{Boolean: v}
+	case int8:
+		exprLiteral.LiteralType = &proto.Expression_Literal_I8{I8: int32(v)}
+	case int16:
+		exprLiteral.LiteralType = &proto.Expression_Literal_I16{I16: int32(v)}
+	case int32:
+		exprLiteral.LiteralType = &proto.Expression_Literal_I32{I32: v}
+	case int64:
+		exprLiteral.LiteralType = &proto.Expression_Literal_I64{I64: v}
+	case float32:
+		exprLiteral.LiteralType = &proto.Expression_Literal_Fp32{Fp32: v}
+	case float64:
+		exprLiteral.LiteralType = &proto.Expression_Literal_Fp64{Fp64: v}
+	case string:
+		exprLiteral.LiteralType = &proto.Expression_Literal_String_{String_: v}
+	case []byte:
+		exprLiteral.LiteralType = &proto.Expression_Literal_Binary{Binary: v}
+	case types.FixedBinary:
+		exprLiteral.LiteralType = &proto.Expression_Literal_FixedBinary{FixedBinary: v}
+	case types.FixedChar:
+		exprLiteral.LiteralType = &proto.Expression_Literal_FixedChar{FixedChar: string(v)}
+	case *types.VarChar:
+		exprLiteral.LiteralType = &proto.Expression_Literal_VarChar_{VarChar: v}
+	case types.UUID:
+		exprLiteral.LiteralType = &proto.Expression_Literal_Uuid{Uuid: v}
+	case types.Date:
+		exprLiteral.LiteralType = &proto.Expression_Literal_Date{Date: int32(v)}
+	case types.Time:
+		exprLiteral.LiteralType = &proto.Expression_Literal_Time{Time: int64(v)}
+	case types.Timestamp:
+		exprLiteral.LiteralType = &proto.Expression_Literal_Timestamp{Timestamp: int64(v)}
+	case types.TimestampTz:
+		exprLiteral.LiteralType = &proto.Expression_Literal_TimestampTz{TimestampTz: int64(v)}
+	case *types.PrecisionTimestamp:
+		exprLiteral.LiteralType = v
+	case *types.PrecisionTimestampTz:
+		exprLiteral.LiteralType = v
+	case *types.IntervalYearToMonth:
+		exprLiteral.LiteralType = &proto.Expression_Literal_IntervalYearToMonth_{IntervalYearToMonth: v}
+	case *types.IntervalDayToSecond:
+		exprLiteral.LiteralType = &proto.Expression_Literal_IntervalDayToSecond_{IntervalDayToSecond: v}
+	case *proto.Expression_Literal_IntervalCompound:
+		exprLiteral.LiteralType = &proto.Expression_Literal_IntervalCompound_{IntervalCompound: v}
+	case *types.Decimal:
+		exprLiteral.LiteralType = &proto.Expression_Literal_Decimal_{Decimal: v}
+	case ListValue:
+		if len(v) == 0 {
+			typ, err := bonobo.TypeToProto(lit.typ)
+			if err != nil {
+				return nil, err
+			}
+			exprLiteral.LiteralType = &proto.Expression_Literal_EmptyList{EmptyList: typ.GetList()}
+			break
+		}
+
+		values, err := literalsToProto(v, extensions)
+		if err != nil {
+			return nil, err
+		}
+		exprLiteral.LiteralType = &proto.Expression_Literal_List_{
+			List: &proto.Expression_Literal_List{Values: values},
+		}
+	case StructValue:
+		fields, err := literalsToProto(v, extensions)
+		if err != nil {
+			return nil, err
+		}
+		exprLiteral.LiteralType = &proto.Expression_Literal_Struct_{
+			Struct: &proto.Expression_Literal_Struct{Fields: fields},
+		}
+	case MapValue:
+		if len(v) == 0 {
+			typ, err := bonobo.TypeToProto(lit.typ)
+			if err != nil {
+				return nil, err
+			}
+			exprLiteral.LiteralType = &proto.Expression_Literal_EmptyMap{EmptyMap: typ.GetMap()}
+			break
+		}
+
+		keyValues := make([]*proto.Expression_Literal_Map_KeyValue, len(v))
+		for i, entry := range v {
+			key, err := literalToProto(entry.Key, extensions)
+			if err != nil {
+				return nil, err
+			}
+			value, err := literalToProto(entry.Value, extensions)
+			if err != nil {
+				return nil, err
+			}
+			keyValues[i] = &proto.Expression_Literal_Map_KeyValue{Key: key, Value: value}
+		}
+		exprLiteral.LiteralType = &proto.Expression_Literal_Map_{
+			Map: &proto.Expression_Literal_Map{KeyValues: keyValues},
+		}
+	case *UserDefinedValue:
+		params := make([]*proto.Type_Parameter, len(v.Parameters))
+		for i, param := range v.Parameters {
+			params[i] = param.ToProto()
+		}
+
+		userDefined := &proto.Expression_Literal_UserDefined{
+			TypeReference:  extensions.RegisterType(v.URI, v.Name),
+			TypeParameters: params,
+		}
+		if v.Struct != nil {
+			fields, err := literalsToProto(v.Struct, extensions)
+			if err != nil {
+				return nil, err
+			}
+			userDefined.Val = &proto.Expression_Literal_UserDefined_Struct{
+				Struct: &proto.Expression_Literal_Struct{Fields: fields},
+			}
+		} else {
+			userDefined.Val = &proto.Expression_Literal_UserDefined_Value{Value: v.Value}
+		}
+		exprLiteral.LiteralType = &proto.Expression_Literal_UserDefined_{UserDefined: userDefined}
+	default:
+		return nil, fmt.Errorf("engine: invalid literal type: %T", v)
+	}
+
+	return exprLiteral, nil
+}
+
+func literalsToProto(lits []*Literal, extensions *substrait.ExtensionRegistry) ([]*proto.Expression_Literal, error) {
+	out := make([]*proto.Expression_Literal, len(lits))
+	for i, lit := range lits {
+		var err error
+		if out[i], err = literalToProto(lit, extensions); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// literalFromProto deserializes lit. Its type is nullable if lit is marked
+// nullable or is null. The types of user-defined literals are resolved in
+// extensions.
+func literalFromProto(lit *proto.Expression_Literal, extensions *substrait.ExtensionRegistry) (*Literal, error) {
+	var val any
+	switch l := lit.GetLiteralType().(type) {
+	case *proto.Expression_Literal_Null:
+		typ, err := bonobo.TypeFromProto(l.Null)
+		if err != nil {
+			return nil, fmt.Errorf("null literal: %w", err)
+		}
+		return NewNullLiteral(typ), nil
+	case *proto.Expression_Literal_EmptyList:
+		typ, err := bonobo.TypeFromProto(&proto.Type{Kind: &proto.Type_List_{List: l.EmptyList}})
+		if err != nil {
+			return nil, fmt.Errorf("empty list literal: %w", err)
+		}
+		return &Literal{val: ListValue{}, typ: literalNullability(typ, lit)}, nil
+	case *proto.Expression_Literal_EmptyMap:
+		typ, err := bonobo.TypeFromProto(&proto.Type{Kind: &proto.Type_Map_{Map: l.EmptyMap}})
+		if err != nil {
+			return nil, fmt.Errorf("empty map literal: %w", err)
+		}
+		return &Literal{val: MapValue{}, typ: literalNullability(typ, lit)}, nil
+	case *proto.Expression_Literal_Boolean:
+		val = l.Boolean
+	case *proto.Expression_Literal_I8:
+		val = int8(l.I8)
+	case *proto.Expression_Literal_I16:
+		val = int16(l.I16)
+	case *proto.Expression_Literal_I32:
+		val = l.I32
+	case *proto.Expression_Literal_I64:
+		val = l.I64
+	case *proto.Expression_Literal_Fp32:
+		val = l.Fp32
+	case *proto.Expression_Literal_Fp64:
+		val = l.Fp64
+	case *proto.Expression_Literal_String_:
+		val = l.String_
+	case *proto.Expression_Literal_Binary:
+		val = l.Binary
+	case *proto.Expression_Literal_FixedBinary:
+		val = types.FixedBinary(l.FixedBinary)
+	case *proto.Expression_Literal_FixedChar:
+		val = types.FixedChar(l.FixedChar)
+	case *proto.Expression_Literal_VarChar_:
+		val = l.VarChar
+	case *proto.Expression_Literal_Uuid:
+		val = types.UUID(l.Uuid)
+	case *proto.Expression_Literal_Date:
+		val = types.Date(l.Date)
+	case *proto.Expression_Literal_Time:
+		val = types.Time(l.Time)
+	case *proto.Expression_Literal_Timestamp:
+		val = types.Timestamp(l.Timestamp)
+	case *proto.Expression_Literal_TimestampTz:
+		val = types.TimestampTz(l.TimestampTz)
+	case *proto.Expression_Literal_PrecisionTimestamp_:
+		val = l
+	case *proto.Expression_Literal_PrecisionTimestampTz:
+		val = l
+	case *proto.Expression_Literal_IntervalYearToMonth_:
+		val = l.IntervalYearToMonth
+	case *proto.Expression_Literal_IntervalDayToSecond_:
+		val = l.IntervalDayToSecond
+	case *proto.Expression_Literal_IntervalCompound_:
+		val = l.IntervalCompound
+	case *proto.Expression_Literal_Decimal_:
+		val = l.Decimal
+	case *proto.Expression_Literal_UserDefined_:
+		userDefined, err := userDefinedFromProto(l.UserDefined, extensions)
+		if err != nil {
+			return nil, err
+		}
+		val = userDefined
+	case *proto.Expression_Literal_List_:
+		values, err := literalsFromProto(l.List.GetValues(), extensions)
+		if err != nil {
+			return nil, err
+		}
+		val = ListValue(values)
+	case *proto.Expression_Literal_Struct_:
+		fields, err := literalsFromProto(l.Struct.GetFields(), extensions)
+		if err != nil {
+			return nil, err
+		}
+		val = StructValue(fields)
+	case *proto.Expression_Literal_Map_:
+		entries := make(MapValue, len(l.Map.GetKeyValues()))
+		for i, kv := range l.Map.GetKeyValues() {
+			key, err := literalFromProto(kv.GetKey(), extensions)
+			if err != nil {
+				return nil, err
+			}
+			value, err := literalFromProto(kv.GetValue(), extensions)
+			if err != nil {
+				return nil, err
+			}
+			entries[i] = MapLiteralEntry{Key: key, Value: value}
+		}
+		val = entries
+	default:
+		return nil, fmt.Errorf("unrecognized proto.Expression_Literal type: %T", l)
+	}
+
+	typ, err := literalType(val)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Literal{val: val, typ: literalNullability(typ, lit)}, nil
+}
+
+func literalsFromProto(lits []*proto.Expression_Literal, extensions *substrait.ExtensionRegistry) ([]*Literal, error) {
+	out := make([]*Literal, len(lits))
+	for i, lit := range lits {
+		var err error
+		if out[i], err = literalFromProto(lit, extensions); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// userDefinedFromProto deserializes lit, resolving the URI and name of its
+// type from the declaration of its type reference in extensions.
+func userDefinedFromProto(lit *proto.Expression_Literal_UserDefined, extensions *substrait.ExtensionRegistry) (*UserDefinedValue, error) {
+	ext, uri, err := extensions.GetExtensionByReference(lit.GetTypeReference())
+	if err != nil {
+		return nil, fmt.Errorf("user-defined literal: %w", err)
+	}
+	if ext.Kind != substrait.ExtensionKindType {
+		return nil, fmt.Errorf("user-defined literal: extension reference %d is not a type", lit.GetTypeReference())
+	}
+
+	params := make([]types.TypeParam, len(lit.GetTypeParameters()))
+	for i, param := range lit.GetTypeParameters() {
+		params[i] = types.TypeParamFromProto(param)
+	}
+
+	val := &UserDefinedValue{TypeReference: lit.GetTypeReference(), URI: uri, Name: ext.Name, Parameters: params}
+	switch v := lit.GetVal().(type) {
+	case *proto.Expression_Literal_UserDefined_Value:
+		val.Value = v.Value
+	case *proto.Expression_Literal_UserDefined_Struct:
+		fields, err := literalsFromProto(v.Struct.GetFields(), extensions)
+		if err != nil {
+			return nil, err
+		}
+		val.Struct = StructValue(fields)
+	default:
+		return nil, fmt.Errorf("user-defined literal of type %s has no value", ext.Name)
+	}
+
+	return val, nil
+}
+
+// literalNullability makes typ nullable if lit is marked nullable.
+func literalNullability(typ bonobo.Type, lit *proto.Expression_Literal) bonobo.Type {
+	if lit.GetNullable() {
+		return bonobo.WithNullability(typ, types.NullabilityNullable)
+	}
+	return typ
+}
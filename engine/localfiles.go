@@ -0,0 +1,432 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joellubi/bonobo"
+	"github.com/joellubi/bonobo/substrait"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	"github.com/apache/arrow/go/v17/parquet/file"
+	"github.com/apache/arrow/go/v17/parquet/pqarrow"
+	"github.com/substrait-io/substrait-go/v3/proto"
+)
+
+var (
+	ErrUnknownSchema = errors.New("engine: schema of table could not be determined")
+)
+
+// FilePathType describes how the path of a FileItem should be interpreted.
+type FilePathType int
+
+const (
+	// FilePathTypeURIPath refers to either a single file or a single folder.
+	FilePathTypeURIPath FilePathType = iota
+	// FilePathTypeURIPathGlob is a glob matching zero or more paths.
+	FilePathTypeURIPathGlob
+	// FilePathTypeURIFile refers to a single file.
+	FilePathTypeURIFile
+	// FilePathTypeURIFolder refers to a single folder.
+	FilePathTypeURIFolder
+)
+
+func (t FilePathType) String() string {
+	switch t {
+	case FilePathTypeURIPath:
+		return "PATH"
+	case FilePathTypeURIPathGlob:
+		return "GLOB"
+	case FilePathTypeURIFile:
+		return "FILE"
+	case FilePathTypeURIFolder:
+		return "FOLDER"
+	default:
+		return fmt.Sprintf("FilePathType(%d)", int(t))
+	}
+}
+
+// FileItem is a file, folder or glob to be read by a LocalFilesTable.
+type FileItem struct {
+	Path     string
+	PathType FilePathType
+
+	// PartitionIndex is the index of the partition this item belongs to.
+	PartitionIndex uint64
+	// Start and Length are the byte range of the item to read.
+	// A zero Length reads the whole item.
+	Start  uint64
+	Length uint64
+}
+
+// FileFormat is the format of the files read by a LocalFilesTable.
+type FileFormat interface {
+	fmt.Stringer
+
+	setFileFormat(item *proto.ReadRel_LocalFiles_FileOrFiles)
+}
+
+type ParquetFormat struct{}
+
+func (ParquetFormat) String() string { return "PARQUET" }
+
+func (ParquetFormat) setFileFormat(item *proto.ReadRel_LocalFiles_FileOrFiles) {
+	item.FileFormat = &proto.ReadRel_LocalFiles_FileOrFiles_Parquet{
+		Parquet: &proto.ReadRel_LocalFiles_FileOrFiles_ParquetReadOptions{},
+	}
+}
+
+type ArrowFormat struct{}
+
+func (ArrowFormat) String() string { return "ARROW" }
+
+func (ArrowFormat) setFileFormat(item *proto.ReadRel_LocalFiles_FileOrFiles) {
+	item.FileFormat = &proto.ReadRel_LocalFiles_FileOrFiles_Arrow{
+		Arrow: &proto.ReadRel_LocalFiles_FileOrFiles_ArrowReadOptions{},
+	}
+}
+
+type OrcFormat struct{}
+
+func (OrcFormat) String() string { return "ORC" }
+
+func (OrcFormat) setFileFormat(item *proto.ReadRel_LocalFiles_FileOrFiles) {
+	item.FileFormat = &proto.ReadRel_LocalFiles_FileOrFiles_Orc{
+		Orc: &proto.ReadRel_LocalFiles_FileOrFiles_OrcReadOptions{},
+	}
+}
+
+type DwrfFormat struct{}
+
+func (DwrfFormat) String() string { return "DWRF" }
+
+func (DwrfFormat) setFileFormat(item *proto.ReadRel_LocalFiles_FileOrFiles) {
+	item.FileFormat = &proto.ReadRel_LocalFiles_FileOrFiles_Dwrf{
+		Dwrf: &proto.ReadRel_LocalFiles_FileOrFiles_DwrfReadOptions{},
+	}
+}
+
+// DelimitedTextFormat reads files of delimiter separated values, such as CSV.
+type DelimitedTextFormat struct {
+	FieldDelimiter    string
+	MaxLineSize       uint64
+	Quote             string
+	HeaderLinesToSkip uint64
+	Escape            string
+	// ValueTreatedAsNull is read as null when set, including the empty string.
+	ValueTreatedAsNull *string
+}
+
+func (DelimitedTextFormat) String() string { return "TEXT" }
+
+func (f DelimitedTextFormat) setFileFormat(item *proto.ReadRel_LocalFiles_FileOrFiles) {
+	item.FileFormat = &proto.ReadRel_LocalFiles_FileOrFiles_Text{
+		Text: &proto.ReadRel_LocalFiles_FileOrFiles_DelimiterSeparatedTextReadOptions{
+			FieldDelimiter:     f.FieldDelimiter,
+			MaxLineSize:        f.MaxLineSize,
+			Quote:              f.Quote,
+			HeaderLinesToSkip:  f.HeaderLinesToSkip,
+			Escape:             f.Escape,
+			ValueTreatedAsNull: f.ValueTreatedAsNull,
+		},
+	}
+}
+
+// NewLocalFilesTable creates a table that reads items in the given format,
+// which must not be nil. If schema is nil, it is inferred from the footer of
+// the first file found when the items are local Parquet or Arrow IPC files.
+func NewLocalFilesTable(items []FileItem, format FileFormat, schema *bonobo.Schema) *LocalFilesTable {
+	return &LocalFilesTable{items: items, format: format, schema: schema}
+}
+
+type LocalFilesTable struct {
+	items  []FileItem
+	format FileFormat
+	schema *bonobo.Schema
+}
+
+func (t *LocalFilesTable) Items() []FileItem {
+	return t.items
+}
+
+func (t *LocalFilesTable) Format() FileFormat {
+	return t.format
+}
+
+func (t *LocalFilesTable) Schema() (*bonobo.Schema, error) {
+	if t.schema != nil {
+		return t.schema, nil
+	}
+
+	schema, err := t.inferSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	t.schema = schema
+	return schema, nil
+}
+
+func (t *LocalFilesTable) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	if t.format == nil {
+		return nil, errors.New("engine: local files table has no file format")
+	}
+
+	var baseSchema *proto.NamedStruct
+
+	// As with named tables, the plan can still be serialized
+	// without a schema if it cannot be determined
+	schema, err := t.Schema()
+	if err == nil {
+		if baseSchema, err = schema.ToProto(); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, ErrUnknownSchema) {
+		return nil, err
+	}
+
+	items := make([]*proto.ReadRel_LocalFiles_FileOrFiles, len(t.items))
+	for i, item := range t.items {
+		items[i], err = fileItemToProto(item, t.format)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &proto.Rel{
+		RelType: &proto.Rel_Read{
+			Read: &proto.ReadRel{
+				BaseSchema: baseSchema,
+				ReadType: &proto.ReadRel_LocalFiles_{
+					LocalFiles: &proto.ReadRel_LocalFiles{
+						Items: items,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (t *LocalFilesTable) inferSchema() (*bonobo.Schema, error) {
+	var readSchema func(path string) (*arrow.Schema, error)
+	switch t.format.(type) {
+	case ParquetFormat:
+		readSchema = readParquetSchema
+	case ArrowFormat:
+		readSchema = readIPCSchema
+	default:
+		return nil, fmt.Errorf("%w: cannot infer schema of %s files", ErrUnknownSchema, t.format)
+	}
+
+	for _, item := range t.items {
+		paths, err := localPaths(item)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			continue
+		}
+
+		arrowSchema, err := readSchema(paths[0])
+		if err != nil {
+			return nil, fmt.Errorf("engine: reading schema of %s: %w", paths[0], err)
+		}
+
+		return bonobo.SchemaFromArrowValues(arrowSchema)
+	}
+
+	return nil, fmt.Errorf("%w: no local files found", ErrUnknownSchema)
+}
+
+// localPaths resolves item to the local files it refers to, in lexical order.
+func localPaths(item FileItem) ([]string, error) {
+	path, ok := localPath(item.Path)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not a local path", ErrUnknownSchema, item.Path)
+	}
+
+	if item.PathType == FilePathTypeURIPathGlob {
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var paths []string
+		for _, match := range matches {
+			found, err := dataFiles(match)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, found...)
+		}
+		return paths, nil
+	}
+
+	return dataFiles(path)
+}
+
+// localPath returns the filesystem path for a URI with no scheme
+// or the file scheme.
+func localPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" {
+		return uri, true
+	}
+	if u.Scheme != "file" {
+		return "", false
+	}
+	if u.Opaque != "" {
+		return u.Opaque, true
+	}
+	return u.Path, true
+}
+
+// dataFiles returns path if it is a file, or all data files beneath
+// it if it is a folder. Hidden files and those starting with an
+// underscore, such as _SUCCESS markers, are skipped.
+func dataFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnknownSchema, err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var paths []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name := d.Name()
+		if p != path && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Type().IsRegular() {
+			paths = append(paths, p)
+		}
+		return nil
+	})
+
+	return paths, err
+}
+
+func readParquetSchema(path string) (*arrow.Schema, error) {
+	rdr, err := file.OpenParquetFile(path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Close()
+
+	return pqarrow.FromParquet(rdr.MetaData().Schema, nil, rdr.MetaData().KeyValueMetadata())
+}
+
+// readIPCSchema reads the schema of an Arrow IPC file, falling back
+// to the IPC stream format if the file has no footer.
+func readIPCSchema(path string) (*arrow.Schema, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if rdr, err := ipc.NewFileReader(f); err == nil {
+		defer rdr.Close()
+		return rdr.Schema(), nil
+	}
+
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil, err
+	}
+
+	rdr, err := ipc.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Release()
+
+	return rdr.Schema(), nil
+}
+
+func fileItemToProto(item FileItem, format FileFormat) (*proto.ReadRel_LocalFiles_FileOrFiles, error) {
+	p := &proto.ReadRel_LocalFiles_FileOrFiles{
+		PartitionIndex: item.PartitionIndex,
+		Start:          item.Start,
+		Length:         item.Length,
+	}
+
+	switch item.PathType {
+	case FilePathTypeURIPath:
+		p.PathType = &proto.ReadRel_LocalFiles_FileOrFiles_UriPath{UriPath: item.Path}
+	case FilePathTypeURIPathGlob:
+		p.PathType = &proto.ReadRel_LocalFiles_FileOrFiles_UriPathGlob{UriPathGlob: item.Path}
+	case FilePathTypeURIFile:
+		p.PathType = &proto.ReadRel_LocalFiles_FileOrFiles_UriFile{UriFile: item.Path}
+	case FilePathTypeURIFolder:
+		p.PathType = &proto.ReadRel_LocalFiles_FileOrFiles_UriFolder{UriFolder: item.Path}
+	default:
+		return nil, fmt.Errorf("engine: unrecognized file path type: %s", item.PathType)
+	}
+
+	format.setFileFormat(p)
+	return p, nil
+}
+
+func fileItemFromProto(p *proto.ReadRel_LocalFiles_FileOrFiles) (FileItem, FileFormat, error) {
+	item := FileItem{
+		PartitionIndex: p.GetPartitionIndex(),
+		Start:          p.GetStart(),
+		Length:         p.GetLength(),
+	}
+
+	switch path := p.GetPathType().(type) {
+	case *proto.ReadRel_LocalFiles_FileOrFiles_UriPath:
+		item.Path, item.PathType = path.UriPath, FilePathTypeURIPath
+	case *proto.ReadRel_LocalFiles_FileOrFiles_UriPathGlob:
+		item.Path, item.PathType = path.UriPathGlob, FilePathTypeURIPathGlob
+	case *proto.ReadRel_LocalFiles_FileOrFiles_UriFile:
+		item.Path, item.PathType = path.UriFile, FilePathTypeURIFile
+	case *proto.ReadRel_LocalFiles_FileOrFiles_UriFolder:
+		item.Path, item.PathType = path.UriFolder, FilePathTypeURIFolder
+	default:
+		return FileItem{}, nil, fmt.Errorf("unrecognized file path type: %T", path)
+	}
+
+	var format FileFormat
+	switch f := p.GetFileFormat().(type) {
+	case *proto.ReadRel_LocalFiles_FileOrFiles_Parquet:
+		format = ParquetFormat{}
+	case *proto.ReadRel_LocalFiles_FileOrFiles_Arrow:
+		format = ArrowFormat{}
+	case *proto.ReadRel_LocalFiles_FileOrFiles_Orc:
+		format = OrcFormat{}
+	case *proto.ReadRel_LocalFiles_FileOrFiles_Dwrf:
+		format = DwrfFormat{}
+	case *proto.ReadRel_LocalFiles_FileOrFiles_Text:
+		format = DelimitedTextFormat{
+			FieldDelimiter:     f.Text.GetFieldDelimiter(),
+			MaxLineSize:        f.Text.GetMaxLineSize(),
+			Quote:              f.Text.GetQuote(),
+			HeaderLinesToSkip:  f.Text.GetHeaderLinesToSkip(),
+			Escape:             f.Text.GetEscape(),
+			ValueTreatedAsNull: f.Text.ValueTreatedAsNull,
+		}
+	default:
+		return FileItem{}, nil, fmt.Errorf("unsupported file format: %T", f)
+	}
+
+	return item, format, nil
+}
+
+var _ Table = (*LocalFilesTable)(nil)
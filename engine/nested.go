@@ -0,0 +1,247 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joellubi/bonobo"
+	"github.com/joellubi/bonobo/substrait"
+
+	"github.com/substrait-io/substrait-go/v3/proto"
+	"github.com/substrait-io/substrait-go/v3/types"
+)
+
+type nestedKind int
+
+const (
+	nestedStruct nestedKind = iota
+	nestedList
+	nestedMap
+)
+
+// MapEntry is a key and the value that it maps to.
+type MapEntry struct {
+	Key   Expr
+	Value Expr
+}
+
+// NewStructExpr builds a struct with a field for each of fields, like
+// STRUCT(fields...).
+func NewStructExpr(fields ...Expr) *Nested {
+	return &Nested{kind: nestedStruct, values: fields}
+}
+
+// NewListExpr builds a list of values, like [values...]. There must be at
+// least one value, and they must all have the same type.
+func NewListExpr(values ...Expr) *Nested {
+	return &Nested{kind: nestedList, values: values}
+}
+
+// NewMapExpr builds a map from entries, like MAP(key, value, ...). There must
+// be at least one entry, and the keys and values must each have the same type.
+func NewMapExpr(entries ...MapEntry) *Nested {
+	return &Nested{kind: nestedMap, entries: entries}
+}
+
+// Nested is an expression that builds a struct, list or map from the values
+// of other expressions.
+type Nested struct {
+	kind nestedKind
+
+	// values are the fields of a struct or the values of a list
+	values  []Expr
+	entries []MapEntry
+
+	nullable bool
+}
+
+// Field implements Expr. The fields of a struct are named after its values,
+// and any structs within a list or map are named after those of its first
+// value or entry.
+func (expr *Nested) Field(input Relation) (bonobo.Field, error) {
+	typ, err := expr.resultType(input)
+	if err != nil {
+		return bonobo.Field{}, err
+	}
+
+	switch t := typ.(type) {
+	case *types.ListType:
+		elem, err := expr.values[0].Field(input)
+		if err != nil {
+			return bonobo.Field{}, err
+		}
+		elem.Type = t.Type
+
+		return bonobo.NewListField(expr.String(), elem, expr.nullable), nil
+	case *types.MapType:
+		key, err := expr.entries[0].Key.Field(input)
+		if err != nil {
+			return bonobo.Field{}, err
+		}
+		value, err := expr.entries[0].Value.Field(input)
+		if err != nil {
+			return bonobo.Field{}, err
+		}
+		key.Type, value.Type = t.Key, t.Value
+
+		return bonobo.NewMapField(expr.String(), key, value, expr.nullable), nil
+	default:
+		fields := make([]bonobo.Field, len(expr.values))
+		for i, value := range expr.values {
+			if fields[i], err = value.Field(input); err != nil {
+				return bonobo.Field{}, err
+			}
+		}
+
+		return bonobo.NewStructField(expr.String(), fields, expr.nullable), nil
+	}
+}
+
+// resultType derives the type of the struct, list or map from the types of
+// its children.
+func (expr *Nested) resultType(input Relation) (bonobo.Type, error) {
+	nullability := types.NullabilityRequired
+	if expr.nullable {
+		nullability = types.NullabilityNullable
+	}
+
+	switch expr.kind {
+	case nestedStruct:
+		fields := make([]types.Type, len(expr.values))
+		for i, value := range expr.values {
+			field, err := value.Field(input)
+			if err != nil {
+				return nil, err
+			}
+			fields[i] = field.Type
+		}
+
+		return &types.StructType{Nullability: nullability, Types: fields}, nil
+	case nestedList:
+		if len(expr.values) == 0 {
+			return nil, fmt.Errorf("engine: list expression must have at least one value")
+		}
+
+		typ, err := unifyExprTypes(input, expr.values)
+		if err != nil {
+			return nil, fmt.Errorf("engine: list values: %w", err)
+		}
+
+		return &types.ListType{Nullability: nullability, Type: typ}, nil
+	case nestedMap:
+		if len(expr.entries) == 0 {
+			return nil, fmt.Errorf("engine: map expression must have at least one entry")
+		}
+
+		keys := make([]Expr, len(expr.entries))
+		values := make([]Expr, len(expr.entries))
+		for i, entry := range expr.entries {
+			keys[i], values[i] = entry.Key, entry.Value
+		}
+
+		keyType, err := unifyExprTypes(input, keys)
+		if err != nil {
+			return nil, fmt.Errorf("engine: map keys: %w", err)
+		}
+		valueType, err := unifyExprTypes(input, values)
+		if err != nil {
+			return nil, fmt.Errorf("engine: map values: %w", err)
+		}
+
+		return &types.MapType{Nullability: nullability, Key: keyType, Value: valueType}, nil
+	default:
+		return nil, fmt.Errorf("engine: unrecognized nested expression kind: %d", expr.kind)
+	}
+}
+
+// String implements Expr.
+func (expr *Nested) String() string {
+	switch expr.kind {
+	case nestedList:
+		return fmt.Sprintf("[%s]", ExprList(expr.values))
+	case nestedMap:
+		entries := make([]string, len(expr.entries))
+		for i, entry := range expr.entries {
+			entries[i] = fmt.Sprintf("%s, %s", entry.Key, entry.Value)
+		}
+		return fmt.Sprintf("MAP(%s)", strings.Join(entries, ", "))
+	default:
+		return fmt.Sprintf("STRUCT(%s)", ExprList(expr.values))
+	}
+}
+
+// ToProto implements Expr.
+func (expr *Nested) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
+	if _, err := expr.resultType(input); err != nil {
+		return nil, err
+	}
+
+	nested := &proto.Expression_Nested{Nullable: expr.nullable}
+	switch expr.kind {
+	case nestedStruct:
+		fields, err := exprsToProto(input, extensions, expr.values)
+		if err != nil {
+			return nil, err
+		}
+		nested.NestedType = &proto.Expression_Nested_Struct_{
+			Struct: &proto.Expression_Nested_Struct{Fields: fields},
+		}
+	case nestedList:
+		values, err := exprsToProto(input, extensions, expr.values)
+		if err != nil {
+			return nil, err
+		}
+		nested.NestedType = &proto.Expression_Nested_List_{
+			List: &proto.Expression_Nested_List{Values: values},
+		}
+	case nestedMap:
+		keyValues := make([]*proto.Expression_Nested_Map_KeyValue, len(expr.entries))
+		for i, entry := range expr.entries {
+			key, err := entry.Key.ToProto(input, extensions)
+			if err != nil {
+				return nil, err
+			}
+			value, err := entry.Value.ToProto(input, extensions)
+			if err != nil {
+				return nil, err
+			}
+			keyValues[i] = &proto.Expression_Nested_Map_KeyValue{Key: key, Value: value}
+		}
+		nested.NestedType = &proto.Expression_Nested_Map_{
+			Map: &proto.Expression_Nested_Map{KeyValues: keyValues},
+		}
+	}
+
+	return &proto.Expression{
+		RexType: &proto.Expression_Nested_{Nested: nested},
+	}, nil
+}
+
+// children lists the expressions that the struct, list or map is built from.
+func (expr *Nested) children() []Expr {
+	if expr.kind != nestedMap {
+		return expr.values
+	}
+
+	children := make([]Expr, 0, 2*len(expr.entries))
+	for _, entry := range expr.entries {
+		children = append(children, entry.Key, entry.Value)
+	}
+	return children
+}
+
+// unifyExprTypes unifies the types of exprs.
+func unifyExprTypes(input Relation, exprs []Expr) (bonobo.Type, error) {
+	typs := make([]bonobo.Type, len(exprs))
+	for i, expr := range exprs {
+		field, err := expr.Field(input)
+		if err != nil {
+			return nil, err
+		}
+		typs[i] = field.Type
+	}
+
+	return unifyTypes(typs...)
+}
+
+var _ Expr = (*Nested)(nil)
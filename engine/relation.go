@@ -3,11 +3,14 @@ package engine
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/joellubi/bonobo"
 	"github.com/joellubi/bonobo/substrait"
 
 	"github.com/substrait-io/substrait-go/v3/proto"
+	"github.com/substrait-io/substrait-go/v3/types"
 )
 
 type Relation interface { // TODO: Plan implements Table?
@@ -24,22 +27,102 @@ func NewReadOperation(table Table) *Read {
 
 type Read struct {
 	table Table
+
+	// projection is the indices of the table fields to output,
+	// or nil if all fields are output
+	projection       []int
+	filter           Expr
+	bestEffortFilter Expr
+}
+
+// WithProjection returns a copy of the read that only outputs the
+// table fields at the provided indices, in order.
+func (r *Read) WithProjection(fields ...int) *Read {
+	out := *r
+	out.projection = fields
+	return &out
+}
+
+// WithFilter returns a copy of the read that only outputs records
+// matching expr. The expression refers to the fields of the table
+// before the projection is applied.
+func (r *Read) WithFilter(expr Expr) *Read {
+	out := *r
+	out.filter = expr
+	return &out
+}
+
+// WithBestEffortFilter returns a copy of the read with a filter that
+// may be used to skip data, but is not required to remove every record
+// that does not match expr. Like the filter, it refers to the fields
+// of the table before the projection is applied.
+func (r *Read) WithBestEffortFilter(expr Expr) *Read {
+	out := *r
+	out.bestEffortFilter = expr
+	return &out
 }
 
 func (*Read) Children() []Relation {
 	return nil
 }
 
+func (r *Read) Table() Table {
+	return r.table
+}
+
+func (r *Read) Projection() []int {
+	return r.projection
+}
+
+func (r *Read) Filter() Expr {
+	return r.filter
+}
+
+func (r *Read) BestEffortFilter() Expr {
+	return r.bestEffortFilter
+}
+
 func (r *Read) Schema() (*bonobo.Schema, error) {
-	return r.table.Schema()
+	schema, err := r.table.Schema()
+	if err != nil || r.projection == nil {
+		return schema, err
+	}
+
+	fields := schema.Fields()
+	projected := make([]bonobo.Field, len(r.projection))
+	for i, idx := range r.projection {
+		if idx < 0 || idx >= len(fields) {
+			return nil, fmt.Errorf("engine: read projection field %d out of range for schema with %d fields", idx, len(fields))
+		}
+		projected[i] = fields[idx]
+	}
+
+	return bonobo.NewSchema(projected), nil
 }
 
 func (r *Read) String() string {
-	schema, err := r.Schema()
-	if err != nil && !errors.Is(err, ErrUnboundTable) {
-		panic(err)
+	// The schema is unavailable until the table is bound to a catalog, and
+	// any other error is printed in its place
+	schema, err := r.table.Schema()
+	formattedSchema := formatSchema(schema)
+	if err != nil && !errors.Is(err, ErrUnboundTable) && !errors.Is(err, ErrUnknownSchema) {
+		formattedSchema = fmt.Sprintf("error: %s", err)
+	}
+
+	projection := "None"
+	if r.projection != nil {
+		projection = fmt.Sprint(r.projection)
+	}
+
+	var bldr strings.Builder
+	fmt.Fprintf(&bldr, "Read: schema=[%s], projection=%s", formattedSchema, projection)
+	if r.filter != nil {
+		fmt.Fprintf(&bldr, ", filter=%s", r.filter)
 	}
-	return fmt.Sprintf("Read: schema=[%s], projection=%s", formatSchema(schema), "None") // TODO: Projection
+	if r.bestEffortFilter != nil {
+		fmt.Fprintf(&bldr, ", best_effort_filter=%s", r.bestEffortFilter)
+	}
+	return bldr.String()
 }
 
 func (r *Read) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
@@ -48,8 +131,45 @@ func (r *Read) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, err
 		return nil, err
 	}
 
-	// TODO: Schema projection stuff
-	return p, err
+	read := p.GetRead()
+	if read == nil {
+		return nil, fmt.Errorf("engine: table %T did not serialize to a ReadRel", r.table)
+	}
+
+	if r.projection != nil {
+		// Validate the projection against the table if its schema is known
+		if _, err := r.Schema(); err != nil && !errors.Is(err, ErrUnboundTable) && !errors.Is(err, ErrUnknownSchema) {
+			return nil, err
+		}
+
+		items := make([]*proto.Expression_MaskExpression_StructItem, len(r.projection))
+		for i, idx := range r.projection {
+			items[i] = &proto.Expression_MaskExpression_StructItem{Field: int32(idx)}
+		}
+
+		read.Projection = &proto.Expression_MaskExpression{
+			Select: &proto.Expression_MaskExpression_StructSelect{
+				StructItems: items,
+			},
+		}
+	}
+
+	// Filters refer to the fields of the table before projection
+	base := NewReadOperation(r.table)
+	if r.filter != nil {
+		read.Filter, err = r.filter.ToProto(base, extensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.bestEffortFilter != nil {
+		read.BestEffortFilter, err = r.bestEffortFilter.ToProto(base, extensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
 }
 
 func NewProjectionOperation(input Relation, exprs []Expr) *Projection {
@@ -61,9 +181,12 @@ type Projection struct {
 	exprs ExprList
 }
 
+// Schema implements Relation.
+//
+// As in SQL, several fields may share a name, as in SELECT t1.a, t2.a. An
+// unqualified reference to such a name is rejected as ambiguous.
 func (p *Projection) Schema() (*bonobo.Schema, error) {
 	fields := make([]bonobo.Field, len(p.exprs))
-	fieldNames := make(map[string]bool, len(p.exprs))
 	for i, expr := range p.exprs {
 		if expr == nil {
 			return nil, fmt.Errorf("invalid Projection, expr is nil")
@@ -73,12 +196,6 @@ func (p *Projection) Schema() (*bonobo.Schema, error) {
 			return nil, err
 		}
 
-		// TODO: Broader duplicate detection, outside of only projection
-		if _, found := fieldNames[f.Name]; found {
-			return nil, fmt.Errorf("invalid Projection, duplicate field name %s", f.Name)
-		}
-
-		fieldNames[f.Name] = true
 		fields[i] = f
 	}
 
@@ -167,17 +284,1272 @@ func (s *Selection) Child() Relation {
 	return s.input
 }
 
-func SetCatalogForPlan(plan *Plan, catalog Catalog) {
-	for _, relation := range plan.Relations() {
-		SetCatalogForRelation(relation, catalog)
+func NewFetchOperation(input Relation, offset, count int64) *Fetch {
+	return &Fetch{input: input, offset: offset, count: count}
+}
+
+// Fetch skips the first offset records of its input and returns at most count
+// of the remaining ones. A count of -1 returns all remaining records.
+type Fetch struct {
+	input         Relation
+	offset, count int64
+}
+
+func (f *Fetch) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	childRel, err := f.input.ToProto(extensions)
+	if err != nil {
+		return nil, err
 	}
+
+	return &proto.Rel{
+		RelType: &proto.Rel_Fetch{
+			Fetch: &proto.FetchRel{
+				Input:  childRel,
+				Offset: f.offset,
+				Count:  f.count,
+			},
+		},
+	}, nil
 }
 
-func SetCatalogForRelation(plan Relation, catalog Catalog) {
-	r, ok := plan.(*Read)
-	if ok {
-		SetCatalogForTable(r.table, catalog)
-		return
+func (f *Fetch) Schema() (*bonobo.Schema, error) {
+	return f.input.Schema()
+}
+
+func (f *Fetch) Children() []Relation {
+	return []Relation{f.input}
+}
+
+func (f *Fetch) String() string {
+	return fmt.Sprintf("Fetch: offset=%d, count=%d", f.offset, f.count)
+}
+
+// NewAggregateOperation creates an Aggregate that computes measures for each
+// of the provided grouping sets. Expressions that appear in more than one
+// grouping set are only output once.
+func NewAggregateOperation(input Relation, groupingSets [][]Expr, measures []AggregateExpr) *Aggregate {
+	var (
+		groupingExprs ExprList
+		indexByName   = make(map[string]int)
+	)
+
+	sets := make([][]int, len(groupingSets))
+	for i, set := range groupingSets {
+		sets[i] = make([]int, len(set))
+		for j, expr := range set {
+			index, found := indexByName[expr.String()]
+			if !found {
+				index = len(groupingExprs)
+				indexByName[expr.String()] = index
+				groupingExprs = append(groupingExprs, expr)
+			}
+			sets[i][j] = index
+		}
+	}
+
+	return &Aggregate{input: input, groupingExprs: groupingExprs, groupingSets: sets, measures: measures}
+}
+
+type Aggregate struct {
+	input Relation
+
+	// groupingSets index into groupingExprs
+	groupingExprs ExprList
+	groupingSets  [][]int
+
+	measures []AggregateExpr
+}
+
+func (a *Aggregate) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	var err error
+
+	groupingExprs := make([]*proto.Expression, len(a.groupingExprs))
+	for i, expr := range a.groupingExprs {
+		groupingExprs[i], err = expr.ToProto(a.input, extensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	groupings := make([]*proto.AggregateRel_Grouping, len(a.groupingSets))
+	for i, set := range a.groupingSets {
+		refs := make([]uint32, len(set))
+		for j, index := range set {
+			refs[j] = uint32(index)
+		}
+		groupings[i] = &proto.AggregateRel_Grouping{ExpressionReferences: refs}
+	}
+
+	measures := make([]*proto.AggregateRel_Measure, len(a.measures))
+	for i, measure := range a.measures {
+		fn, err := measure.ToProto(a.input, extensions)
+		if err != nil {
+			return nil, err
+		}
+		measures[i] = &proto.AggregateRel_Measure{Measure: fn}
+	}
+
+	childRel, err := a.input.ToProto(extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.Rel{
+		RelType: &proto.Rel_Aggregate{
+			Aggregate: &proto.AggregateRel{
+				Input:               childRel,
+				Groupings:           groupings,
+				Measures:            measures,
+				GroupingExpressions: groupingExprs,
+			},
+		},
+	}, nil
+}
+
+// Schema implements Relation.
+//
+// The output contains each distinct grouping expression followed by each
+// measure. Grouping expressions missing from any grouping set are null for
+// the records produced by that set. When there is more than one grouping set,
+// a final i32 field identifies the grouping set each record belongs to.
+func (a *Aggregate) Schema() (*bonobo.Schema, error) {
+	fields := make([]bonobo.Field, 0, len(a.groupingExprs)+len(a.measures)+1)
+	for i, expr := range a.groupingExprs {
+		f, err := expr.Field(a.input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, set := range a.groupingSets {
+			if !slices.Contains(set, i) {
+				f.Type = bonobo.WithNullability(f.Type, types.NullabilityNullable)
+				break
+			}
+		}
+
+		fields = append(fields, f)
+	}
+
+	for _, measure := range a.measures {
+		f, err := measure.Field(a.input)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+
+	if len(a.groupingSets) > 1 {
+		fields = append(fields, bonobo.Field{Name: "$grouping_set", Type: bonobo.Types.Int32Type(false)})
+	}
+
+	return bonobo.NewSchema(fields), nil
+}
+
+func (a *Aggregate) Children() []Relation {
+	return []Relation{a.input}
+}
+
+func (a *Aggregate) String() string {
+	sets := make([]string, len(a.groupingSets))
+	for i, set := range a.groupingSets {
+		exprs := make(ExprList, len(set))
+		for j, index := range set {
+			exprs[j] = a.groupingExprs[index]
+		}
+		sets[i] = fmt.Sprintf("[%s]", exprs)
+	}
+
+	measures := make([]string, len(a.measures))
+	for i, measure := range a.measures {
+		measures[i] = measure.String()
+	}
+
+	return fmt.Sprintf("Aggregate: groupings=[%s], measures=[%s]", strings.Join(sets, ", "), strings.Join(measures, ", "))
+}
+
+// SortDirection describes the order of values and the placement of nulls
+// for a SortField.
+type SortDirection proto.SortField_SortDirection
+
+const (
+	SortAscNullsFirst  = SortDirection(proto.SortField_SORT_DIRECTION_ASC_NULLS_FIRST)
+	SortAscNullsLast   = SortDirection(proto.SortField_SORT_DIRECTION_ASC_NULLS_LAST)
+	SortDescNullsFirst = SortDirection(proto.SortField_SORT_DIRECTION_DESC_NULLS_FIRST)
+	SortDescNullsLast  = SortDirection(proto.SortField_SORT_DIRECTION_DESC_NULLS_LAST)
+	SortClustered      = SortDirection(proto.SortField_SORT_DIRECTION_CLUSTERED)
+)
+
+func (d SortDirection) String() string {
+	switch d {
+	case SortAscNullsFirst:
+		return "ASC NULLS FIRST"
+	case SortAscNullsLast:
+		return "ASC NULLS LAST"
+	case SortDescNullsFirst:
+		return "DESC NULLS FIRST"
+	case SortDescNullsLast:
+		return "DESC NULLS LAST"
+	case SortClustered:
+		return "CLUSTERED"
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+func NewSortField(expr Expr, direction SortDirection) SortField {
+	return SortField{Expr: expr, Direction: direction}
+}
+
+type SortField struct {
+	Expr      Expr
+	Direction SortDirection
+}
+
+func (f SortField) String() string {
+	return fmt.Sprintf("%s %s", f.Expr, f.Direction)
+}
+
+func (f SortField) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.SortField, error) {
+	expr, err := f.Expr.ToProto(input, extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.SortField{
+		Expr: expr,
+		SortKind: &proto.SortField_Direction{
+			Direction: proto.SortField_SortDirection(f.Direction),
+		},
+	}, nil
+}
+
+func NewSortOperation(input Relation, fields []SortField) *Sort {
+	return &Sort{input: input, fields: fields}
+}
+
+type Sort struct {
+	input  Relation
+	fields []SortField
+}
+
+func (s *Sort) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	var err error
+
+	sorts := make([]*proto.SortField, len(s.fields))
+	for i, field := range s.fields {
+		sorts[i], err = field.ToProto(s.input, extensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	childRel, err := s.input.ToProto(extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.Rel{
+		RelType: &proto.Rel_Sort{
+			Sort: &proto.SortRel{
+				Input: childRel,
+				Sorts: sorts,
+			},
+		},
+	}, nil
+}
+
+func (s *Sort) Schema() (*bonobo.Schema, error) {
+	return s.input.Schema()
+}
+
+func (s *Sort) Children() []Relation {
+	return []Relation{s.input}
+}
+
+func (s *Sort) String() string {
+	fields := make([]string, len(s.fields))
+	for i, field := range s.fields {
+		fields[i] = field.String()
+	}
+
+	return fmt.Sprintf("Sort: %s", strings.Join(fields, ", "))
+}
+
+// JoinType determines which records a Join outputs and which of the fields
+// of its inputs are included in them.
+type JoinType proto.JoinRel_JoinType
+
+const (
+	JoinTypeInner       = JoinType(proto.JoinRel_JOIN_TYPE_INNER)
+	JoinTypeOuter       = JoinType(proto.JoinRel_JOIN_TYPE_OUTER)
+	JoinTypeLeft        = JoinType(proto.JoinRel_JOIN_TYPE_LEFT)
+	JoinTypeRight       = JoinType(proto.JoinRel_JOIN_TYPE_RIGHT)
+	JoinTypeLeftSemi    = JoinType(proto.JoinRel_JOIN_TYPE_LEFT_SEMI)
+	JoinTypeLeftAnti    = JoinType(proto.JoinRel_JOIN_TYPE_LEFT_ANTI)
+	JoinTypeLeftSingle  = JoinType(proto.JoinRel_JOIN_TYPE_LEFT_SINGLE)
+	JoinTypeRightSemi   = JoinType(proto.JoinRel_JOIN_TYPE_RIGHT_SEMI)
+	JoinTypeRightAnti   = JoinType(proto.JoinRel_JOIN_TYPE_RIGHT_ANTI)
+	JoinTypeRightSingle = JoinType(proto.JoinRel_JOIN_TYPE_RIGHT_SINGLE)
+	JoinTypeLeftMark    = JoinType(proto.JoinRel_JOIN_TYPE_LEFT_MARK)
+	JoinTypeRightMark   = JoinType(proto.JoinRel_JOIN_TYPE_RIGHT_MARK)
+)
+
+func (t JoinType) String() string {
+	name, _ := strings.CutPrefix(proto.JoinRel_JoinType(t).String(), "JOIN_TYPE_")
+	return name
+}
+
+// NewJoinOperation creates a Join of the left and right inputs. The condition
+// is evaluated against the fields of the left input followed by the fields of
+// the right input. The postJoinFilter is optional and is evaluated against the
+// output of the Join.
+func NewJoinOperation(left, right Relation, joinType JoinType, condition, postJoinFilter Expr) *Join {
+	return &Join{left: left, right: right, joinType: joinType, condition: condition, postJoinFilter: postJoinFilter}
+}
+
+type Join struct {
+	left, right Relation
+	joinType    JoinType
+
+	condition      Expr
+	postJoinFilter Expr
+}
+
+func (j *Join) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	if j.condition == nil {
+		return nil, fmt.Errorf("invalid Join, condition is nil")
+	}
+
+	condition, err := j.condition.ToProto(j.conditionInput(), extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	var postJoinFilter *proto.Expression
+	if j.postJoinFilter != nil {
+		postJoinFilter, err = j.postJoinFilter.ToProto(j, extensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	leftRel, err := j.left.ToProto(extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	rightRel, err := j.right.ToProto(extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.Rel{
+		RelType: &proto.Rel_Join{
+			Join: &proto.JoinRel{
+				Left:           leftRel,
+				Right:          rightRel,
+				Expression:     condition,
+				PostJoinFilter: postJoinFilter,
+				Type:           proto.JoinRel_JoinType(j.joinType),
+			},
+		},
+	}, nil
+}
+
+// Schema implements Relation.
+//
+// The fields of the left input are followed by the fields of the right input.
+// Fields from a side that may not have a match are nullable, semi and anti
+// joins only output the fields of one side, and mark joins append a nullable
+// boolean field indicating whether a match was found.
+func (j *Join) Schema() (*bonobo.Schema, error) {
+	leftSchema, err := j.left.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	rightSchema, err := j.right.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	left, right := leftSchema.Fields(), rightSchema.Fields()
+	mark := []bonobo.Field{{Name: "$mark", Type: bonobo.Types.BooleanType(true)}}
+
+	var fields []bonobo.Field
+	switch j.joinType {
+	case JoinTypeInner:
+		fields = slices.Concat(left, right)
+	case JoinTypeOuter:
+		fields = slices.Concat(nullableFields(left), nullableFields(right))
+	case JoinTypeLeft, JoinTypeLeftSingle:
+		fields = slices.Concat(left, nullableFields(right))
+	case JoinTypeRight, JoinTypeRightSingle:
+		fields = slices.Concat(nullableFields(left), right)
+	case JoinTypeLeftSemi, JoinTypeLeftAnti:
+		fields = left
+	case JoinTypeRightSemi, JoinTypeRightAnti:
+		fields = right
+	case JoinTypeLeftMark:
+		fields = slices.Concat(left, mark)
+	case JoinTypeRightMark:
+		fields = slices.Concat(right, mark)
+	default:
+		return nil, fmt.Errorf("invalid Join, unrecognized join type: %d", j.joinType)
+	}
+
+	return bonobo.NewSchema(fields), nil
+}
+
+func (j *Join) Children() []Relation {
+	return []Relation{j.left, j.right}
+}
+
+func (j *Join) String() string {
+	s := fmt.Sprintf("Join: type=%s, condition=%s", j.joinType, j.condition)
+	if j.postJoinFilter != nil {
+		s += fmt.Sprintf(", filter=%s", j.postJoinFilter)
+	}
+	return s
+}
+
+// conditionInput is the relation the join condition is evaluated against,
+// containing all fields of both inputs.
+func (j *Join) conditionInput() Relation {
+	return &Join{left: j.left, right: j.right, joinType: JoinTypeInner}
+}
+
+// NewJoinUsingOperation creates a Join of the left and right inputs on the
+// equality of the columns with the provided names, as in SQL JOIN ... USING.
+// Each of these columns is output once, before the other fields of the
+// inputs, with the value of whichever input is not null. Only inner, outer,
+// left and right joins are supported.
+func NewJoinUsingOperation(left, right Relation, joinType JoinType, columns []string) *JoinUsing {
+	return &JoinUsing{left: left, right: right, joinType: joinType, columns: columns}
+}
+
+type JoinUsing struct {
+	left, right Relation
+	joinType    JoinType
+	columns     []string
+}
+
+// ToProto implements Relation.
+//
+// The Join is wrapped in a Project that coalesces the USING columns of both
+// inputs, followed by references to the other fields of the Join.
+func (j *JoinUsing) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	layout, err := j.layout()
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := make([]Expr, 0, len(layout.using)+len(layout.keep))
+	for _, pair := range layout.using {
+		exprs = append(exprs, coalesceUsing(pair))
+	}
+	for _, index := range layout.keep {
+		exprs = append(exprs, NewColumnIndexExpr(index))
+	}
+
+	return NewProjectionOperation(layout.join, exprs).ToProto(extensions)
+}
+
+// Schema implements Relation.
+//
+// The USING columns have the type returned by the coalesce that ToProto
+// serializes for them.
+func (j *JoinUsing) Schema() (*bonobo.Schema, error) {
+	layout, err := j.layout()
+	if err != nil {
+		return nil, err
+	}
+
+	joinSchema, err := layout.join.Schema()
+	if err != nil {
+		return nil, err
+	}
+	joinFields := joinSchema.Fields()
+
+	fields := make([]bonobo.Field, 0, len(layout.using)+len(layout.keep))
+	for _, pair := range layout.using {
+		coalesced, err := coalesceUsing(pair).Field(layout.join)
+		if err != nil {
+			return nil, err
+		}
+
+		field := joinFields[pair[0]]
+		field.Type = coalesced.Type
+		fields = append(fields, field)
+	}
+	for _, index := range layout.keep {
+		fields = append(fields, joinFields[index])
+	}
+
+	return bonobo.NewSchema(fields), nil
+}
+
+func (j *JoinUsing) Children() []Relation {
+	return []Relation{j.left, j.right}
+}
+
+func (j *JoinUsing) String() string {
+	return fmt.Sprintf("JoinUsing: type=%s, columns=%s", j.joinType, strings.Join(j.columns, ", "))
+}
+
+// joinUsingLayout is the Join underlying a JoinUsing and the fields of its
+// output that make up the output of the JoinUsing.
+type joinUsingLayout struct {
+	join *Join
+	// using are the indexes of each USING column in the left and right input
+	using [][2]int
+	// keep are the indexes of the fields that are output unchanged
+	keep []int
+}
+
+// coalesceUsing merges the fields of a USING column from the left and right
+// inputs of the underlying Join.
+func coalesceUsing(pair [2]int) Expr {
+	return NewFunctionExpr(
+		"https://github.com/substrait-io/substrait/blob/main/extensions/functions_comparison.yaml",
+		"coalesce",
+		NewColumnIndexExpr(pair[0]),
+		NewColumnIndexExpr(pair[1]),
+	)
+}
+
+// layout creates the Join of the inputs on the equality of the USING columns.
+func (j *JoinUsing) layout() (*joinUsingLayout, error) {
+	switch j.joinType {
+	case JoinTypeInner, JoinTypeOuter, JoinTypeLeft, JoinTypeRight:
+	default:
+		return nil, fmt.Errorf("invalid JoinUsing, unsupported join type: %s", j.joinType)
+	}
+	if len(j.columns) == 0 {
+		return nil, fmt.Errorf("invalid JoinUsing, no columns")
+	}
+
+	leftSchema, err := j.left.Schema()
+	if err != nil {
+		return nil, err
+	}
+	rightSchema, err := j.right.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	layout := &joinUsingLayout{using: make([][2]int, len(j.columns))}
+	var condition Expr
+	for i, col := range j.columns {
+		left, err := NewColumnExpr(col).index(j.left)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JoinUsing, left input: %w", err)
+		}
+		right, err := NewColumnExpr(col).index(j.right)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JoinUsing, right input: %w", err)
+		}
+		right += leftSchema.Len()
+		layout.using[i] = [2]int{left, right}
+
+		eq := NewFunctionExpr(
+			"https://github.com/substrait-io/substrait/blob/main/extensions/functions_comparison.yaml",
+			"equal",
+			NewColumnIndexExpr(left),
+			NewColumnIndexExpr(right),
+		)
+		if condition == nil {
+			condition = eq
+		} else {
+			condition = NewFunctionExpr(
+				"https://github.com/substrait-io/substrait/blob/main/extensions/functions_boolean.yaml",
+				"and",
+				condition,
+				eq,
+			)
+		}
+	}
+
+	for i := 0; i < leftSchema.Len()+rightSchema.Len(); i++ {
+		if !slices.ContainsFunc(layout.using, func(pair [2]int) bool { return pair[0] == i || pair[1] == i }) {
+			layout.keep = append(layout.keep, i)
+		}
+	}
+
+	layout.join = NewJoinOperation(j.left, j.right, j.joinType, condition, nil)
+	return layout, nil
+}
+
+// NewCrossOperation creates the cartesian product of the left and right
+// inputs, pairing every record of the left input with every record of the
+// right input.
+func NewCrossOperation(left, right Relation) *Cross {
+	return &Cross{left: left, right: right}
+}
+
+type Cross struct {
+	left, right Relation
+}
+
+func (c *Cross) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	leftRel, err := c.left.ToProto(extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	rightRel, err := c.right.ToProto(extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.Rel{
+		RelType: &proto.Rel_Cross{
+			Cross: &proto.CrossRel{
+				Left:  leftRel,
+				Right: rightRel,
+			},
+		},
+	}, nil
+}
+
+// Schema implements Relation.
+//
+// The fields of the left input are followed by the fields of the right input.
+func (c *Cross) Schema() (*bonobo.Schema, error) {
+	leftSchema, err := c.left.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	rightSchema, err := c.right.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	return bonobo.NewSchema(slices.Concat(leftSchema.Fields(), rightSchema.Fields())), nil
+}
+
+func (c *Cross) Children() []Relation {
+	return []Relation{c.left, c.right}
+}
+
+func (c *Cross) String() string {
+	return "Cross"
+}
+
+func nullableFields(fields []bonobo.Field) []bonobo.Field {
+	out := make([]bonobo.Field, len(fields))
+	for i, f := range fields {
+		out[i] = bonobo.Field{Name: f.Name, Type: bonobo.WithNullability(f.Type, types.NullabilityNullable)}
+	}
+	return out
+}
+
+// SetOp determines which records of its inputs a Set outputs.
+type SetOp proto.SetRel_SetOp
+
+const (
+	SetOpMinusPrimary            = SetOp(proto.SetRel_SET_OP_MINUS_PRIMARY)
+	SetOpMinusPrimaryAll         = SetOp(proto.SetRel_SET_OP_MINUS_PRIMARY_ALL)
+	SetOpMinusMultiset           = SetOp(proto.SetRel_SET_OP_MINUS_MULTISET)
+	SetOpIntersectionPrimary     = SetOp(proto.SetRel_SET_OP_INTERSECTION_PRIMARY)
+	SetOpIntersectionMultiset    = SetOp(proto.SetRel_SET_OP_INTERSECTION_MULTISET)
+	SetOpIntersectionMultisetAll = SetOp(proto.SetRel_SET_OP_INTERSECTION_MULTISET_ALL)
+	SetOpUnionDistinct           = SetOp(proto.SetRel_SET_OP_UNION_DISTINCT)
+	SetOpUnionAll                = SetOp(proto.SetRel_SET_OP_UNION_ALL)
+)
+
+func (op SetOp) String() string {
+	name, _ := strings.CutPrefix(proto.SetRel_SetOp(op).String(), "SET_OP_")
+	return name
+}
+
+// NewSetOperation combines the records of two or more inputs. The first input
+// is the primary input and the rest are secondary inputs. All inputs must have
+// the same field types, though their nullability may differ.
+func NewSetOperation(inputs []Relation, op SetOp) *Set {
+	return &Set{inputs: inputs, op: op}
+}
+
+type Set struct {
+	inputs []Relation
+	op     SetOp
+}
+
+func (s *Set) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	if _, err := s.Schema(); err != nil {
+		return nil, err
+	}
+
+	inputs := make([]*proto.Rel, len(s.inputs))
+	for i, input := range s.inputs {
+		rel, err := input.ToProto(extensions)
+		if err != nil {
+			return nil, err
+		}
+		inputs[i] = rel
+	}
+
+	return &proto.Rel{
+		RelType: &proto.Rel_Set{
+			Set: &proto.SetRel{
+				Inputs: inputs,
+				Op:     proto.SetRel_SetOp(s.op),
+			},
+		},
+	}, nil
+}
+
+// Schema implements Relation.
+//
+// The field names are those of the primary input. Whether each field is
+// nullable depends on the nullability of the field in each input and on
+// whether the records output by the operation may come from that input.
+func (s *Set) Schema() (*bonobo.Schema, error) {
+	if len(s.inputs) < 2 {
+		return nil, fmt.Errorf("invalid Set, expected at least 2 inputs but found %d", len(s.inputs))
+	}
+
+	schemas := make([]*bonobo.Schema, len(s.inputs))
+	for i, input := range s.inputs {
+		schema, err := input.Schema()
+		if err != nil {
+			return nil, err
+		}
+		schemas[i] = schema
+	}
+
+	primary := schemas[0].Fields()
+	for i, schema := range schemas[1:] {
+		fields := schema.Fields()
+		if len(fields) != len(primary) {
+			return nil, fmt.Errorf("invalid Set, input %d has %d fields but the primary input has %d", i+1, len(fields), len(primary))
+		}
+
+		for j, field := range fields {
+			if !bonobo.WithNullability(field.Type, types.NullabilityRequired).Equals(bonobo.WithNullability(primary[j].Type, types.NullabilityRequired)) {
+				return nil, fmt.Errorf("invalid Set, type of field %d of input %d is %s but the primary input has %s", j, i+1, field.Type, primary[j].Type)
+			}
+		}
+	}
+
+	fields := make([]bonobo.Field, len(primary))
+	for i, field := range primary {
+		nullable := func(input int) bool {
+			return schemas[input].Fields()[i].Type.GetNullability() == types.NullabilityNullable
+		}
+
+		var anySecondary, allSecondary bool
+		allSecondary = true
+		for input := 1; input < len(schemas); input++ {
+			anySecondary = anySecondary || nullable(input)
+			allSecondary = allSecondary && nullable(input)
+		}
+
+		var isNullable bool
+		switch s.op {
+		case SetOpMinusPrimary, SetOpMinusPrimaryAll, SetOpMinusMultiset:
+			isNullable = nullable(0)
+		case SetOpIntersectionPrimary:
+			isNullable = nullable(0) && anySecondary
+		case SetOpIntersectionMultiset, SetOpIntersectionMultisetAll:
+			isNullable = nullable(0) && allSecondary
+		case SetOpUnionDistinct, SetOpUnionAll:
+			isNullable = nullable(0) || anySecondary
+		default:
+			return nil, fmt.Errorf("invalid Set, unrecognized set operation: %d", s.op)
+		}
+
+		nullability := types.NullabilityRequired
+		if isNullable {
+			nullability = types.NullabilityNullable
+		}
+		fields[i] = bonobo.Field{Name: field.Name, Type: bonobo.WithNullability(field.Type, nullability)}
+	}
+
+	return bonobo.NewSchema(fields), nil
+}
+
+func (s *Set) Children() []Relation {
+	return s.inputs
+}
+
+func (s *Set) String() string {
+	return fmt.Sprintf("Set: op=%s", s.op)
+}
+
+// WriteOp determines how a Write modifies its target table.
+type WriteOp proto.WriteRel_WriteOp
+
+const (
+	WriteOpInsert = WriteOp(proto.WriteRel_WRITE_OP_INSERT)
+	WriteOpDelete = WriteOp(proto.WriteRel_WRITE_OP_DELETE)
+	WriteOpUpdate = WriteOp(proto.WriteRel_WRITE_OP_UPDATE)
+	WriteOpCTAS   = WriteOp(proto.WriteRel_WRITE_OP_CTAS)
+)
+
+func (op WriteOp) String() string {
+	name, _ := strings.CutPrefix(proto.WriteRel_WriteOp(op).String(), "WRITE_OP_")
+	return name
+}
+
+// WriteOutputMode determines which records, if any, a Write outputs.
+type WriteOutputMode proto.WriteRel_OutputMode
+
+const (
+	WriteOutputModeNoOutput        = WriteOutputMode(proto.WriteRel_OUTPUT_MODE_NO_OUTPUT)
+	WriteOutputModeModifiedRecords = WriteOutputMode(proto.WriteRel_OUTPUT_MODE_MODIFIED_RECORDS)
+)
+
+func (m WriteOutputMode) String() string {
+	name, _ := strings.CutPrefix(proto.WriteRel_OutputMode(m).String(), "OUTPUT_MODE_")
+	return name
+}
+
+// NewWriteOperation modifies the table using the records of input, which
+// must have the same schema as the table, ignoring nullability. A table
+// created by WriteOpCTAS takes the schema of input instead.
+func NewWriteOperation(input Relation, table NamedTable, op WriteOp, output WriteOutputMode) *Write {
+	return &Write{input: input, table: table, op: op, output: output}
+}
+
+type Write struct {
+	input  Relation
+	table  NamedTable
+	op     WriteOp
+	output WriteOutputMode
+}
+
+func (w *Write) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	schema, err := w.tableSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.checkInput(schema); err != nil {
+		return nil, err
+	}
+
+	tableSchema, err := schema.ToProto()
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := w.input.ToProto(extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.Rel{
+		RelType: &proto.Rel_Write{
+			Write: &proto.WriteRel{
+				WriteType: &proto.WriteRel_NamedTable{
+					NamedTable: &proto.NamedObjectWrite{
+						Names: w.table.Identifier(),
+					},
+				},
+				TableSchema: tableSchema,
+				Op:          proto.WriteRel_WriteOp(w.op),
+				Input:       input,
+				Output:      proto.WriteRel_OutputMode(w.output),
+			},
+		},
+	}, nil
+}
+
+// Schema implements Relation.
+//
+// The Write either outputs no records or the after-image of the records it
+// modified, depending on its output mode.
+func (w *Write) Schema() (*bonobo.Schema, error) {
+	if w.output == WriteOutputModeModifiedRecords {
+		return w.tableSchema()
+	}
+
+	return bonobo.NewSchema(nil), nil
+}
+
+func (w *Write) Children() []Relation {
+	return []Relation{w.input}
+}
+
+func (w *Write) String() string {
+	return fmt.Sprintf("Write: op=%s, table=%s, output=%s", w.op, strings.Join(w.table.Identifier(), "."), w.output)
+}
+
+// tableSchema is the schema of the target table. If the table is being
+// created or has not been bound to a catalog, it is the schema of the input.
+func (w *Write) tableSchema() (*bonobo.Schema, error) {
+	if w.op != WriteOpCTAS {
+		schema, err := w.table.Schema()
+		if !errors.Is(err, ErrUnboundTable) {
+			return schema, err
+		}
+	}
+
+	return w.input.Schema()
+}
+
+// checkInput returns an error if the fields of the input differ in number or
+// type from those of the table. Nullability is checked when the records are
+// written rather than when the plan is built.
+func (w *Write) checkInput(tableSchema *bonobo.Schema) error {
+	inputSchema, err := w.input.Schema()
+	if err != nil {
+		return err
+	}
+
+	if inputSchema.Len() != tableSchema.Len() {
+		return fmt.Errorf(
+			"cannot write %d fields to table %s with %d fields",
+			inputSchema.Len(), strings.Join(w.table.Identifier(), "."), tableSchema.Len(),
+		)
+	}
+
+	tableFields := tableSchema.Fields()
+	for i, field := range inputSchema.Fields() {
+		expected := bonobo.WithNullability(tableFields[i].Type, types.NullabilityRequired)
+		if !bonobo.WithNullability(field.Type, types.NullabilityRequired).Equals(expected) {
+			return fmt.Errorf(
+				"cannot write field %s of type %s to field %s of type %s in table %s",
+				field.Name, field.Type, tableFields[i].Name, tableFields[i].Type, strings.Join(w.table.Identifier(), "."),
+			)
+		}
+	}
+
+	return nil
+}
+
+// DdlObject is the kind of object a Ddl operates on.
+type DdlObject proto.DdlRel_DdlObject
+
+const (
+	DdlObjectTable = DdlObject(proto.DdlRel_DDL_OBJECT_TABLE)
+	DdlObjectView  = DdlObject(proto.DdlRel_DDL_OBJECT_VIEW)
+)
+
+func (o DdlObject) String() string {
+	name, _ := strings.CutPrefix(proto.DdlRel_DdlObject(o).String(), "DDL_OBJECT_")
+	return name
+}
+
+// DdlOp determines how a Ddl changes the definition of its object.
+type DdlOp proto.DdlRel_DdlOp
+
+const (
+	DdlOpCreate          = DdlOp(proto.DdlRel_DDL_OP_CREATE)
+	DdlOpCreateOrReplace = DdlOp(proto.DdlRel_DDL_OP_CREATE_OR_REPLACE)
+	DdlOpAlter           = DdlOp(proto.DdlRel_DDL_OP_ALTER)
+	DdlOpDrop            = DdlOp(proto.DdlRel_DDL_OP_DROP)
+	DdlOpDropIfExist     = DdlOp(proto.DdlRel_DDL_OP_DROP_IF_EXIST)
+)
+
+func (op DdlOp) String() string {
+	name, _ := strings.CutPrefix(proto.DdlRel_DdlOp(op).String(), "DDL_OP_")
+	return name
+}
+
+// NewTableDdlOperation changes the definition of the table with the provided
+// identifier. The schema is the definition of the table after the change, and
+// may be nil when dropping the table.
+func NewTableDdlOperation(identifier Identifier, op DdlOp, schema *bonobo.Schema) *Ddl {
+	return &Ddl{identifier: identifier, object: DdlObjectTable, op: op, schema: schema}
+}
+
+// NewViewDdlOperation changes the definition of the view with the provided
+// identifier to the records of definition, which may be nil when dropping
+// the view.
+func NewViewDdlOperation(identifier Identifier, op DdlOp, definition Relation) *Ddl {
+	return &Ddl{identifier: identifier, object: DdlObjectView, op: op, definition: definition}
+}
+
+type Ddl struct {
+	identifier Identifier
+	object     DdlObject
+	op         DdlOp
+
+	schema     *bonobo.Schema
+	definition Relation
+}
+
+func (d *Ddl) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	var (
+		tableSchema    *proto.NamedStruct
+		viewDefinition *proto.Rel
+		err            error
+	)
+
+	if d.schema != nil {
+		tableSchema, err = d.schema.ToProto()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if d.definition != nil {
+		viewDefinition, err = d.definition.ToProto(extensions)
+		if err != nil {
+			return nil, err
+		}
+
+		schema, err := d.definition.Schema()
+		if err != nil {
+			return nil, err
+		}
+		tableSchema, err = schema.ToProto()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &proto.Rel{
+		RelType: &proto.Rel_Ddl{
+			Ddl: &proto.DdlRel{
+				WriteType: &proto.DdlRel_NamedObject{
+					NamedObject: &proto.NamedObjectWrite{
+						Names: d.identifier,
+					},
+				},
+				TableSchema:    tableSchema,
+				Object:         proto.DdlRel_DdlObject(d.object),
+				Op:             proto.DdlRel_DdlOp(d.op),
+				ViewDefinition: viewDefinition,
+			},
+		},
+	}, nil
+}
+
+// Schema implements Relation.
+//
+// A Ddl does not output any records.
+func (d *Ddl) Schema() (*bonobo.Schema, error) {
+	return bonobo.NewSchema(nil), nil
+}
+
+func (d *Ddl) Children() []Relation {
+	if d.definition != nil {
+		return []Relation{d.definition}
+	}
+	return nil
+}
+
+func (d *Ddl) String() string {
+	return fmt.Sprintf("Ddl: op=%s, object=%s, name=%s", d.op, d.object, strings.Join(d.identifier, "."))
+}
+
+// NewTableAliasOperation names the output of input so that its fields can be
+// referenced by qualified Column expressions, as in FROM table1 AS t. It has
+// no effect on the serialized plan.
+func NewTableAliasOperation(input Relation, alias string) *TableAlias {
+	return &TableAlias{input: input, alias: alias}
+}
+
+type TableAlias struct {
+	input Relation
+	alias string
+}
+
+func (a *TableAlias) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	return a.input.ToProto(extensions)
+}
+
+func (a *TableAlias) Schema() (*bonobo.Schema, error) {
+	return a.input.Schema()
+}
+
+func (a *TableAlias) Children() []Relation {
+	return []Relation{a.input}
+}
+
+func (a *TableAlias) String() string {
+	return fmt.Sprintf("TableAlias: %s", a.alias)
+}
+
+// qualifiedRelation is implemented by relations whose output fields may be
+// referenced with a table qualifier.
+type qualifiedRelation interface {
+	fieldQualifiers() ([][]string, error)
+}
+
+// fieldQualifiers returns the table qualifiers for each field in the schema of
+// rel. Fields that cannot be referenced with a qualifier have none.
+func fieldQualifiers(rel Relation) ([][]string, error) {
+	if r, ok := rel.(qualifiedRelation); ok {
+		return r.fieldQualifiers()
+	}
+
+	schema, err := rel.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	return make([][]string, schema.Len()), nil
+}
+
+func repeatQualifier(rel Relation, qualifier string) ([][]string, error) {
+	schema, err := rel.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	qualifiers := make([][]string, schema.Len())
+	if qualifier == "" {
+		return qualifiers, nil
+	}
+	for i := range qualifiers {
+		qualifiers[i] = []string{qualifier}
+	}
+
+	return qualifiers, nil
+}
+
+// matchesQualifier reports whether a reference qualified by qualifier may
+// refer to a field of any of the tables identified by tableQualifiers. Any
+// suffix of a table's identifier may be used, so test_db.main.table1,
+// main.table1 and table1 all refer to the same table.
+func matchesQualifier(tableQualifiers []string, qualifier string) bool {
+	return slices.ContainsFunc(tableQualifiers, func(tableQualifier string) bool {
+		return tableQualifier == qualifier || strings.HasSuffix(tableQualifier, "."+qualifier)
+	})
+}
+
+func (r *Read) fieldQualifiers() ([][]string, error) {
+	var qualifier string
+	if t, ok := r.table.(NamedTable); ok {
+		qualifier = strings.Join(t.Identifier(), ".")
+	}
+
+	return repeatQualifier(r, qualifier)
+}
+
+func (a *TableAlias) fieldQualifiers() ([][]string, error) {
+	return repeatQualifier(a, a.alias)
+}
+
+func (p *Projection) fieldQualifiers() ([][]string, error) {
+	inputQualifiers, err := fieldQualifiers(p.input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Columns passed through unmodified keep the qualifiers of the input field.
+	// A qualified reference to a USING column keeps only the qualifier it was
+	// referenced by, so t1.a and t2.a remain distinguishable.
+	qualifiers := make([][]string, len(p.exprs))
+	for i, expr := range p.exprs {
+		col, ok := expr.(*Column)
+		if !ok {
+			continue
+		}
+
+		index, err := col.index(p.input)
+		if err != nil {
+			return nil, err
+		}
+		qualifiers[i] = inputQualifiers[index]
+		if col.qualifier != "" {
+			qualifiers[i] = slices.DeleteFunc(slices.Clone(qualifiers[i]), func(qualifier string) bool {
+				return !matchesQualifier([]string{qualifier}, col.qualifier)
+			})
+		}
+	}
+
+	return qualifiers, nil
+}
+
+func (s *Selection) fieldQualifiers() ([][]string, error) {
+	return fieldQualifiers(s.input)
+}
+
+func (f *Fetch) fieldQualifiers() ([][]string, error) {
+	return fieldQualifiers(f.input)
+}
+
+func (s *Sort) fieldQualifiers() ([][]string, error) {
+	return fieldQualifiers(s.input)
+}
+
+func (c *Cross) fieldQualifiers() ([][]string, error) {
+	left, err := fieldQualifiers(c.left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := fieldQualifiers(c.right)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Concat(left, right), nil
+}
+
+func (j *Join) fieldQualifiers() ([][]string, error) {
+	left, err := fieldQualifiers(j.left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := fieldQualifiers(j.right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch j.joinType {
+	case JoinTypeLeftSemi, JoinTypeLeftAnti:
+		return left, nil
+	case JoinTypeRightSemi, JoinTypeRightAnti:
+		return right, nil
+	case JoinTypeLeftMark:
+		return slices.Concat(left, [][]string{nil}), nil
+	case JoinTypeRightMark:
+		return slices.Concat(right, [][]string{nil}), nil
+	default:
+		return slices.Concat(left, right), nil
+	}
+}
+
+// fieldQualifiers implements qualifiedRelation. A USING column merges a field
+// of each input, so it may be qualified by either input.
+func (j *JoinUsing) fieldQualifiers() ([][]string, error) {
+	layout, err := j.layout()
+	if err != nil {
+		return nil, err
+	}
+
+	joinQualifiers, err := fieldQualifiers(layout.join)
+	if err != nil {
+		return nil, err
+	}
+
+	qualifiers := make([][]string, 0, len(layout.using)+len(layout.keep))
+	for _, pair := range layout.using {
+		qualifiers = append(qualifiers, slices.Concat(joinQualifiers[pair[0]], joinQualifiers[pair[1]]))
+	}
+	for _, index := range layout.keep {
+		qualifiers = append(qualifiers, joinQualifiers[index])
+	}
+
+	return qualifiers, nil
+}
+
+func SetCatalogForPlan(plan *Plan, catalog Catalog) {
+	for _, relation := range plan.Relations() {
+		SetCatalogForRelation(relation, catalog)
+	}
+}
+
+func SetCatalogForRelation(plan Relation, catalog Catalog) {
+	switch r := plan.(type) {
+	case *Read:
+		SetCatalogForTable(r.table, catalog)
+	case *Write:
+		if r.op != WriteOpCTAS {
+			SetCatalogForTable(r.table, catalog)
+		}
+	}
+
+	for _, subquery := range relationSubqueries(plan) {
+		SetCatalogForRelation(subquery, catalog)
 	}
 
 	for _, child := range plan.Children() {
@@ -197,3 +1569,13 @@ func SetCatalogForTable(table Table, catalog Catalog) {
 var _ Relation = (*Read)(nil)
 var _ Relation = (*Projection)(nil)
 var _ Relation = (*Selection)(nil)
+var _ Relation = (*Fetch)(nil)
+var _ Relation = (*Aggregate)(nil)
+var _ Relation = (*Sort)(nil)
+var _ Relation = (*Join)(nil)
+var _ Relation = (*JoinUsing)(nil)
+var _ Relation = (*Cross)(nil)
+var _ Relation = (*Set)(nil)
+var _ Relation = (*Write)(nil)
+var _ Relation = (*Ddl)(nil)
+var _ Relation = (*TableAlias)(nil)
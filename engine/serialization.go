@@ -3,13 +3,13 @@ package engine
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/joellubi/bonobo"
 	"github.com/joellubi/bonobo/substrait"
 
 	"github.com/substrait-io/substrait-go/v3/proto"
-	"github.com/substrait-io/substrait-go/v3/types"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
@@ -47,11 +47,11 @@ func formatSchema(schema *bonobo.Schema) string {
 		return "None"
 	}
 	var bldr strings.Builder
-	for i, typ := range schema.Struct.Types {
+	for i, field := range schema.Fields() {
 		if i != 0 {
 			bldr.WriteString(", ")
 		}
-		fmt.Fprintf(&bldr, "%s: %s", schema.Names[i], typ)
+		fmt.Fprintf(&bldr, "%s: %s", field.Name, field.Type)
 	}
 	return bldr.String()
 }
@@ -95,6 +95,19 @@ func FromProto(plan *proto.Plan) (*Plan, error) {
 
 type planBuilder struct {
 	extensions substrait.ExtensionRegistry
+
+	// scopes are the inputs of the relations whose expressions are being
+	// built, innermost last. There is one scope for each subquery that the
+	// expression being built is nested in, which outer references resolve
+	// against.
+	scopes []Relation
+}
+
+// pushScope sets input as the relation that the expressions being built are
+// evaluated against until the returned function is called.
+func (bldr *planBuilder) pushScope(input Relation) func() {
+	bldr.scopes = append(bldr.scopes, input)
+	return func() { bldr.scopes = bldr.scopes[:len(bldr.scopes)-1] }
 }
 
 func (bldr *planBuilder) RelRoot(rel *proto.Rel, names []string) (Relation, error) {
@@ -108,9 +121,17 @@ func (bldr *planBuilder) RelRoot(rel *proto.Rel, names []string) (Relation, erro
 		return nil, err
 	}
 
+	// The names include those of nested struct fields, so only the top-level
+	// names are compared with the fields of the relation
+	if len(names) != len(schema.Names) {
+		return nil, fmt.Errorf("cannot construct RelRoot from proto: found %d names for schema %s", len(names), schema)
+	}
+	rootSchema := &bonobo.Schema{Names: names, Struct: schema.Struct}
+	rootFields := rootSchema.Fields()
+
 	var aliasing bool
 	for i, field := range schema.Fields() {
-		if names[i] != field.Name {
+		if rootFields[i].Name != field.Name {
 			aliasing = true
 			break
 		}
@@ -119,7 +140,7 @@ func (bldr *planBuilder) RelRoot(rel *proto.Rel, names []string) (Relation, erro
 	if aliasing {
 		exprs := make([]Expr, schema.Len())
 		for i := range exprs {
-			exprs[i] = NewAliasExpr(NewColumnIndexExpr(i), names[i])
+			exprs[i] = NewAliasExpr(NewColumnIndexExpr(i), rootFields[i].Name)
 		}
 
 		r = NewProjectionOperation(r, exprs)
@@ -136,6 +157,24 @@ func (bldr *planBuilder) Rel(rel *proto.Rel) (Relation, error) {
 		return bldr.Project(r.Project)
 	case *proto.Rel_Filter:
 		return bldr.Filter(r.Filter)
+	case *proto.Rel_Fetch:
+		return bldr.Fetch(r.Fetch)
+	case *proto.Rel_Aggregate:
+		return bldr.Aggregate(r.Aggregate)
+	case *proto.Rel_Sort:
+		return bldr.Sort(r.Sort)
+	case *proto.Rel_Join:
+		return bldr.Join(r.Join)
+	case *proto.Rel_Cross:
+		return bldr.Cross(r.Cross)
+	case *proto.Rel_Set:
+		return bldr.Set(r.Set)
+	case *proto.Rel_Write:
+		return bldr.Write(r.Write)
+	case *proto.Rel_Ddl:
+		return bldr.Ddl(r.Ddl)
+	case *proto.Rel_Window:
+		return bldr.ConsistentPartitionWindow(r.Window)
 	default:
 		return nil, fmt.Errorf("cannot construct Plan from proto: unrecognized rel type: %T", r)
 	}
@@ -150,21 +189,21 @@ func (bldr *planBuilder) Expr(expr *proto.Expression) (Expr, error) {
 	case *proto.Expression_ScalarFunction_:
 		return bldr.ScalarFunctionExpr(e.ScalarFunction)
 	case *proto.Expression_WindowFunction_:
-		return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_WindowFunction") // TODO
+		return bldr.WindowFunctionExpr(e.WindowFunction)
 	case *proto.Expression_IfThen_:
-		return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_IfThen") // TODO
+		return bldr.IfThenExpr(e.IfThen)
 	case *proto.Expression_SwitchExpression_:
-		return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_SwitchExpression") // TODO
+		return bldr.SwitchExpr(e.SwitchExpression)
 	case *proto.Expression_SingularOrList_:
-		return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_SingularOrList") // TODO
+		return bldr.SingularOrListExpr(e.SingularOrList)
 	case *proto.Expression_MultiOrList_:
-		return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_MultiOrList") // TODO
+		return bldr.MultiOrListExpr(e.MultiOrList)
 	case *proto.Expression_Cast_:
-		return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_Cast") // TODO
+		return bldr.CastExpr(e.Cast)
 	case *proto.Expression_Subquery_:
-		return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_Subquery") // TODO
+		return bldr.SubqueryExpr(e.Subquery)
 	case *proto.Expression_Nested_:
-		return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_Nested") // TODO
+		return bldr.NestedExpr(e.Nested)
 	case *proto.Expression_Enum_:
 		return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_Enum") // TODO
 	default:
@@ -173,26 +212,220 @@ func (bldr *planBuilder) Expr(expr *proto.Expression) (Expr, error) {
 }
 
 func (bldr *planBuilder) LiteralExpr(expr *proto.Expression_Literal) (Expr, error) {
-	switch e := expr.GetLiteralType().(type) {
-	case *proto.Expression_Literal_Boolean:
-		return NewLiteralExpr(e.Boolean), nil
-	case *proto.Expression_Literal_I8:
-		return NewLiteralExpr(e.I8), nil
-	case *proto.Expression_Literal_I16:
-		return NewLiteralExpr(e.I16), nil
-	case *proto.Expression_Literal_I32:
-		return NewLiteralExpr(e.I32), nil
-	case *proto.Expression_Literal_I64:
-		return NewLiteralExpr(e.I64), nil
-	case *proto.Expression_Literal_Fp32:
-		return NewLiteralExpr(e.Fp32), nil
-	case *proto.Expression_Literal_Fp64:
-		return NewLiteralExpr(e.Fp64), nil
-	case *proto.Expression_Literal_String_:
-		return NewLiteralExpr(e.String_), nil
+	return literalFromProto(expr, &bldr.extensions)
+}
+
+func (bldr *planBuilder) IfThenExpr(expr *proto.Expression_IfThen) (Expr, error) {
+	clauses := make([]IfClause, len(expr.GetIfs()))
+	for i, clause := range expr.GetIfs() {
+		cond, err := bldr.Expr(clause.GetIf())
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := bldr.Expr(clause.GetThen())
+		if err != nil {
+			return nil, err
+		}
+
+		clauses[i] = IfClause{If: cond, Then: result}
+	}
+
+	var els Expr
+	if expr.GetElse() != nil {
+		var err error
+		els, err = bldr.Expr(expr.GetElse())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewIfThenExpr(clauses, els), nil
+}
+
+func (bldr *planBuilder) SwitchExpr(expr *proto.Expression_SwitchExpression) (Expr, error) {
+	match, err := bldr.Expr(expr.GetMatch())
+	if err != nil {
+		return nil, err
+	}
+
+	cases := make([]SwitchCase, len(expr.GetIfs()))
+	for i, c := range expr.GetIfs() {
+		value, err := bldr.LiteralExpr(c.GetIf())
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := bldr.Expr(c.GetThen())
+		if err != nil {
+			return nil, err
+		}
+
+		lit, ok := value.(*Literal)
+		if !ok {
+			return nil, fmt.Errorf("failed to build Expr: switch value is not a literal: %s", value)
+		}
+
+		cases[i] = SwitchCase{Value: lit, Then: result}
+	}
+
+	var els Expr
+	if expr.GetElse() != nil {
+		els, err = bldr.Expr(expr.GetElse())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewSwitchExpr(match, cases, els), nil
+}
+
+func (bldr *planBuilder) SingularOrListExpr(expr *proto.Expression_SingularOrList) (Expr, error) {
+	value, err := bldr.Expr(expr.GetValue())
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := bldr.Exprs(expr.GetOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewInListExpr(value, options...), nil
+}
+
+func (bldr *planBuilder) MultiOrListExpr(expr *proto.Expression_MultiOrList) (Expr, error) {
+	values, err := bldr.Exprs(expr.GetValue())
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([][]Expr, len(expr.GetOptions()))
+	for i, option := range expr.GetOptions() {
+		options[i], err = bldr.Exprs(option.GetFields())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewMultiInListExpr(values, options...), nil
+}
+
+func (bldr *planBuilder) Exprs(exprs []*proto.Expression) ([]Expr, error) {
+	result := make([]Expr, len(exprs))
+	for i, expr := range exprs {
+		var err error
+		result[i], err = bldr.Expr(expr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (bldr *planBuilder) SubqueryExpr(expr *proto.Expression_Subquery) (Expr, error) {
+	switch e := expr.GetSubqueryType().(type) {
+	case *proto.Expression_Subquery_Scalar_:
+		rel, err := bldr.Rel(e.Scalar.GetInput())
+		if err != nil {
+			return nil, err
+		}
+
+		return NewScalarSubqueryExpr(rel), nil
+	case *proto.Expression_Subquery_InPredicate_:
+		needles, err := bldr.Exprs(e.InPredicate.GetNeedles())
+		if err != nil {
+			return nil, err
+		}
+
+		haystack, err := bldr.Rel(e.InPredicate.GetHaystack())
+		if err != nil {
+			return nil, err
+		}
+
+		return NewInSubqueryExpr(needles, haystack), nil
+	case *proto.Expression_Subquery_SetPredicate_:
+		rel, err := bldr.Rel(e.SetPredicate.GetTuples())
+		if err != nil {
+			return nil, err
+		}
+
+		return NewSetPredicateSubqueryExpr(PredicateOp(e.SetPredicate.GetPredicateOp()), rel), nil
+	case *proto.Expression_Subquery_SetComparison_:
+		left, err := bldr.Expr(e.SetComparison.GetLeft())
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := bldr.Rel(e.SetComparison.GetRight())
+		if err != nil {
+			return nil, err
+		}
+
+		return NewSetComparisonSubqueryExpr(
+			left,
+			ComparisonOp(e.SetComparison.GetComparisonOp()),
+			ReductionOp(e.SetComparison.GetReductionOp()),
+			right,
+		), nil
+	default:
+		return nil, fmt.Errorf("unrecognized proto.Expression_Subquery type: %T", e)
+	}
+}
+
+func (bldr *planBuilder) NestedExpr(expr *proto.Expression_Nested) (Expr, error) {
+	var nested *Nested
+	switch e := expr.GetNestedType().(type) {
+	case *proto.Expression_Nested_Struct_:
+		fields, err := bldr.Exprs(e.Struct.GetFields())
+		if err != nil {
+			return nil, err
+		}
+
+		nested = NewStructExpr(fields...)
+	case *proto.Expression_Nested_List_:
+		values, err := bldr.Exprs(e.List.GetValues())
+		if err != nil {
+			return nil, err
+		}
+
+		nested = NewListExpr(values...)
+	case *proto.Expression_Nested_Map_:
+		entries := make([]MapEntry, len(e.Map.GetKeyValues()))
+		for i, kv := range e.Map.GetKeyValues() {
+			key, err := bldr.Expr(kv.GetKey())
+			if err != nil {
+				return nil, err
+			}
+			value, err := bldr.Expr(kv.GetValue())
+			if err != nil {
+				return nil, err
+			}
+			entries[i] = MapEntry{Key: key, Value: value}
+		}
+
+		nested = NewMapExpr(entries...)
 	default:
-		return nil, fmt.Errorf("unrecognized proto.Expression_Literal type: %T", e)
+		return nil, fmt.Errorf("unrecognized proto.Expression_Nested type: %T", e)
+	}
+	nested.nullable = expr.GetNullable()
+
+	return nested, nil
+}
+
+func (bldr *planBuilder) CastExpr(expr *proto.Expression_Cast) (Expr, error) {
+	input, err := bldr.Expr(expr.GetInput())
+	if err != nil {
+		return nil, err
 	}
+
+	typ, err := bonobo.TypeFromProto(expr.GetType())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCastExpr(input, typ, CastFailureBehavior(expr.GetFailureBehavior())), nil
 }
 
 func (bldr *planBuilder) ScalarFunctionExpr(expr *proto.Expression_ScalarFunction) (Expr, error) {
@@ -203,7 +436,10 @@ func (bldr *planBuilder) ScalarFunctionExpr(expr *proto.Expression_ScalarFunctio
 		return nil, err
 	}
 
-	output := types.TypeFromProto(expr.GetOutputType())
+	output, err := bonobo.TypeFromProto(expr.GetOutputType())
+	if err != nil {
+		return nil, err
+	}
 
 	args := make([]Expr, len(expr.Arguments))
 	for i, arg := range expr.Arguments {
@@ -216,6 +452,118 @@ func (bldr *planBuilder) ScalarFunctionExpr(expr *proto.Expression_ScalarFunctio
 	return NewAnonymousFunction(uri, ext.Name, output, args...)
 }
 
+func (bldr *planBuilder) AggregateFunctionExpr(expr *proto.AggregateFunction) (AggregateExpr, error) {
+	// TODO: expr.Options, expr.Sorts
+
+	ext, uri, err := bldr.extensions.GetExtensionByReference(expr.GetFunctionReference())
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := bonobo.TypeFromProto(expr.GetOutputType())
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]Expr, len(expr.Arguments))
+	for i, arg := range expr.Arguments {
+		args[i], err = bldr.FunctionArgumentExpr(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fn, err := NewAnonymousAggregateFunction(uri, ext.Name, output, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return fn.
+		WithPhase(AggregationPhase(expr.GetPhase())).
+		WithInvocation(AggregationInvocation(expr.GetInvocation())), nil
+}
+
+func (bldr *planBuilder) WindowFunctionExpr(expr *proto.Expression_WindowFunction) (*WindowFunction, error) {
+	// TODO: expr.Options
+
+	ext, uri, err := bldr.extensions.GetExtensionByReference(expr.GetFunctionReference())
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := bonobo.TypeFromProto(expr.GetOutputType())
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]Expr, len(expr.Arguments))
+	for i, arg := range expr.Arguments {
+		args[i], err = bldr.FunctionArgumentExpr(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	partitions, err := bldr.Exprs(expr.GetPartitions())
+	if err != nil {
+		return nil, err
+	}
+
+	sorts := make([]SortField, len(expr.GetSorts()))
+	for i, sort := range expr.GetSorts() {
+		sorts[i], err = bldr.SortField(sort)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lower, err := bldr.WindowBound(expr.GetLowerBound())
+	if err != nil {
+		return nil, err
+	}
+
+	upper, err := bldr.WindowBound(expr.GetUpperBound())
+	if err != nil {
+		return nil, err
+	}
+
+	fn, err := NewAnonymousWindowFunction(uri, ext.Name, output, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(partitions) > 0 {
+		fn = fn.WithPartitions(partitions...)
+	}
+	if len(sorts) > 0 {
+		fn = fn.WithSorts(sorts...)
+	}
+
+	return fn.
+		WithBounds(BoundsType(expr.GetBoundsType()), lower, upper).
+		WithPhase(AggregationPhase(expr.GetPhase())).
+		WithInvocation(AggregationInvocation(expr.GetInvocation())), nil
+}
+
+func (bldr *planBuilder) WindowBound(bound *proto.Expression_WindowFunction_Bound) (WindowBound, error) {
+	if bound == nil {
+		return WindowBound{}, nil
+	}
+
+	switch b := bound.GetKind().(type) {
+	case *proto.Expression_WindowFunction_Bound_Preceding_:
+		return PrecedingBound(b.Preceding.GetOffset()), nil
+	case *proto.Expression_WindowFunction_Bound_Following_:
+		return FollowingBound(b.Following.GetOffset()), nil
+	case *proto.Expression_WindowFunction_Bound_CurrentRow_:
+		return CurrentRowBound(), nil
+	case *proto.Expression_WindowFunction_Bound_Unbounded_:
+		return UnboundedBound(), nil
+	default:
+		return WindowBound{}, fmt.Errorf("unrecognized proto.Expression_WindowFunction_Bound kind: %T", b)
+	}
+}
+
 func (bldr *planBuilder) FunctionArgumentExpr(expr *proto.FunctionArgument) (Expr, error) {
 	switch e := expr.GetArgType().(type) {
 	case *proto.FunctionArgument_Enum:
@@ -230,85 +578,521 @@ func (bldr *planBuilder) FunctionArgumentExpr(expr *proto.FunctionArgument) (Exp
 }
 
 func (bldr *planBuilder) FieldReferenceExpr(expr *proto.Expression_FieldReference) (Expr, error) {
-	if expr.RootType != nil {
-		return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_FieldReference.RootType")
-	}
-
+	var segments []PathSegment
 	switch e := expr.GetReferenceType().(type) {
 	case *proto.Expression_FieldReference_DirectReference:
-		return bldr.ReferenceSegmentExpr(e.DirectReference)
+		var err error
+		segments, err = bldr.PathSegments(e.DirectReference)
+		if err != nil {
+			return nil, err
+		}
 	case *proto.Expression_FieldReference_MaskedReference:
 		return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_FieldReference_MaskedReference")
 	default:
 		return nil, fmt.Errorf("unrecognized proto.Expression_FieldReference type: %T", e)
 	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("failed to build Expr: field reference has no segments")
+	}
+
+	if r, ok := expr.GetRootType().(*proto.Expression_FieldReference_Expression); ok {
+		root, err := bldr.Expr(r.Expression)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewFieldPathExpr(root, segments...), nil
+	}
+
+	// The first segment references a field of the input of the expression
+	if segments[0].kind != segmentStructField {
+		return nil, fmt.Errorf("failed to build Expr: field reference must begin with a struct field, found %s", segments[0])
+	}
+	var ref Expr = NewColumnIndexExpr(segments[0].index)
+	if len(segments) > 1 {
+		ref = NewFieldPathExpr(ref, segments[1:]...)
+	}
+
+	switch r := expr.GetRootType().(type) {
+	case nil, *proto.Expression_FieldReference_RootReference_:
+		return ref, nil
+	case *proto.Expression_FieldReference_OuterReference_:
+		steps := int(r.OuterReference.GetStepsOut())
+		if steps < 1 || steps >= len(bldr.scopes) {
+			return nil, fmt.Errorf("failed to build Expr: outer reference steps out of %d subqueries, expression is nested in %d", steps, len(bldr.scopes)-1)
+		}
+
+		return NewOuterReferenceExpr(bldr.scopes[len(bldr.scopes)-1-steps], steps, ref), nil
+	default:
+		return nil, fmt.Errorf("unrecognized proto.Expression_FieldReference root type: %T", r)
+	}
 }
 
-func (bldr *planBuilder) ReferenceSegmentExpr(expr *proto.Expression_ReferenceSegment) (Expr, error) {
-	switch e := expr.GetReferenceType().(type) {
-	case *proto.Expression_ReferenceSegment_ListElement_:
-		return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_ReferenceSegment_ListElement")
-	case *proto.Expression_ReferenceSegment_MapKey_:
-		return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_ReferenceSegment_MapKey")
-	case *proto.Expression_ReferenceSegment_StructField_:
-		if e.StructField.Child != nil {
-			return nil, fmt.Errorf("failed to build Expr: FromProto not implemented: Expression_ReferenceSegment.Child")
-		}
-		return NewColumnIndexExpr(int(e.StructField.Field)), nil
+// PathSegments flattens a chain of reference segments.
+func (bldr *planBuilder) PathSegments(expr *proto.Expression_ReferenceSegment) ([]PathSegment, error) {
+	var segments []PathSegment
+	for expr != nil {
+		switch e := expr.GetReferenceType().(type) {
+		case *proto.Expression_ReferenceSegment_ListElement_:
+			segments = append(segments, ListElementSegment(int(e.ListElement.GetOffset())))
+			expr = e.ListElement.GetChild()
+		case *proto.Expression_ReferenceSegment_MapKey_:
+			key, err := bldr.LiteralExpr(e.MapKey.GetMapKey())
+			if err != nil {
+				return nil, err
+			}
+			lit, ok := key.(*Literal)
+			if !ok {
+				return nil, fmt.Errorf("failed to build Expr: unsupported map key: %s", key)
+			}
+
+			segments = append(segments, MapKeySegment(lit))
+			expr = e.MapKey.GetChild()
+		case *proto.Expression_ReferenceSegment_StructField_:
+			segments = append(segments, StructFieldSegment(int(e.StructField.GetField())))
+			expr = e.StructField.GetChild()
+		default:
+			return nil, fmt.Errorf("unrecognized proto.Expression_ReferenceSegment type: %T", e)
+		}
+	}
+
+	return segments, nil
+}
+
+func (bldr *planBuilder) SortField(field *proto.SortField) (SortField, error) {
+	expr, err := bldr.Expr(field.GetExpr())
+	if err != nil {
+		return SortField{}, err
+	}
+
+	switch k := field.GetSortKind().(type) {
+	case *proto.SortField_Direction:
+		return NewSortField(expr, SortDirection(k.Direction)), nil
+	case *proto.SortField_ComparisonFunctionReference:
+		return SortField{}, fmt.Errorf("failed to build SortField: FromProto not implemented: SortField_ComparisonFunctionReference")
 	default:
-		return nil, fmt.Errorf("unrecognized proto.Expression_ReferenceSegment type: %T", e)
+		return SortField{}, fmt.Errorf("unrecognized proto.SortField kind: %T", k)
 	}
 }
 
 func (bldr *planBuilder) Read(rel *proto.ReadRel) (*Read, error) {
-	schema := bonobo.NewSchemaFromProto(rel.GetBaseSchema())
+	schema, err := bonobo.NewSchemaFromProto(rel.GetBaseSchema())
+	if err != nil {
+		return nil, err
+	}
 
 	var table Table
 	switch t := rel.GetReadType().(type) {
 	case *proto.ReadRel_NamedTable_:
 		table = NewNamedTable(t.NamedTable.GetNames(), NewAnonymousCatalog(schema))
 	case *proto.ReadRel_VirtualTable_:
-		return nil, fmt.Errorf("cannot construct Read operation from proto: unimplemented VirtualTable")
+		table, err = bldr.VirtualTable(schema, t.VirtualTable)
 	case *proto.ReadRel_LocalFiles_:
-		return nil, fmt.Errorf("cannot construct Read operation from proto: unimplemented LocalFiles")
+		table, err = bldr.LocalFiles(rel.GetBaseSchema(), t.LocalFiles)
 	case *proto.ReadRel_ExtensionTable_:
-		return nil, fmt.Errorf("cannot construct Read operation from proto: unimplemented ExtensionTable")
+		table, err = bldr.ExtensionTable(rel.GetBaseSchema(), t.ExtensionTable)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	read := NewReadOperation(table)
+	defer bldr.pushScope(NewReadOperation(table))()
+
+	if mask := rel.GetProjection(); mask != nil {
+		projection, err := bldr.ReadProjection(mask)
+		if err != nil {
+			return nil, err
+		}
+		read = read.WithProjection(projection...)
+	}
+
+	if rel.GetFilter() != nil {
+		filter, err := bldr.Expr(rel.GetFilter())
+		if err != nil {
+			return nil, err
+		}
+		read = read.WithFilter(filter)
+	}
+
+	if rel.GetBestEffortFilter() != nil {
+		filter, err := bldr.Expr(rel.GetBestEffortFilter())
+		if err != nil {
+			return nil, err
+		}
+		read = read.WithBestEffortFilter(filter)
 	}
 
-	return NewReadOperation(table), nil
+	return read, nil
 }
 
-func (bldr *planBuilder) Project(rel *proto.ProjectRel) (*Projection, error) {
-	var err error
+// ReadProjection returns the indices of the fields selected by mask. Only
+// selections of top-level fields are supported.
+func (bldr *planBuilder) ReadProjection(mask *proto.Expression_MaskExpression) ([]int, error) {
+	items := mask.GetSelect().GetStructItems()
+	projection := make([]int, len(items))
+	for i, item := range items {
+		if item.GetChild() != nil {
+			return nil, fmt.Errorf("cannot construct Read projection from proto: nested field selection is not supported")
+		}
+		projection[i] = int(item.GetField())
+	}
+
+	return projection, nil
+}
+
+func (bldr *planBuilder) VirtualTable(schema *bonobo.Schema, tbl *proto.ReadRel_VirtualTable) (Table, error) {
+	rows := make([][]*proto.Expression_Literal, 0, len(tbl.GetValues())+len(tbl.GetExpressions()))
+	for _, row := range tbl.GetValues() {
+		rows = append(rows, row.GetFields())
+	}
+	for i, row := range tbl.GetExpressions() {
+		fields := make([]*proto.Expression_Literal, len(row.GetFields()))
+		for j, field := range row.GetFields() {
+			lit := field.GetLiteral()
+			if lit == nil {
+				return nil, fmt.Errorf("cannot construct VirtualTable from proto: row %d, field %d is not a literal", i, j)
+			}
+			fields[j] = lit
+		}
+		rows = append(rows, fields)
+	}
+
+	if schema.Len() == 0 && len(rows) == 0 {
+		return NewVirtualTable(nil), nil
+	}
+
+	rec, err := recordFromProto(schema, rows, &bldr.extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewVirtualTable(rec), nil
+}
+
+func (bldr *planBuilder) LocalFiles(baseSchema *proto.NamedStruct, tbl *proto.ReadRel_LocalFiles) (Table, error) {
+	if len(tbl.GetItems()) == 0 {
+		return nil, fmt.Errorf("cannot construct LocalFiles from proto: no items")
+	}
 
-	exprs := make([]Expr, len(rel.GetExpressions()))
-	for i, expr := range rel.GetExpressions() {
-		exprs[i], err = bldr.Expr(expr)
+	var format FileFormat
+	items := make([]FileItem, len(tbl.GetItems()))
+	for i, p := range tbl.GetItems() {
+		item, itemFormat, err := fileItemFromProto(p)
 		if err != nil {
+			return nil, fmt.Errorf("cannot construct LocalFiles from proto: %w", err)
+		}
+
+		if format == nil {
+			format = itemFormat
+		} else if !reflect.DeepEqual(format, itemFormat) {
+			return nil, fmt.Errorf("cannot construct LocalFiles from proto: items with different file formats are not supported")
+		}
+
+		items[i] = item
+	}
+
+	var schema *bonobo.Schema
+	if baseSchema != nil {
+		var err error
+		if schema, err = bonobo.NewSchemaFromProto(baseSchema); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewLocalFilesTable(items, format, schema), nil
+}
+
+func (bldr *planBuilder) ExtensionTable(baseSchema *proto.NamedStruct, tbl *proto.ReadRel_ExtensionTable) (Table, error) {
+	var schema *bonobo.Schema
+	if baseSchema != nil {
+		var err error
+		if schema, err = bonobo.NewSchemaFromProto(baseSchema); err != nil {
 			return nil, err
 		}
 	}
 
+	table, err := extensionTableFromProto(schema, tbl, DefaultExtensionTableCodecs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot construct ExtensionTable from proto: %w", err)
+	}
+
+	return table, nil
+}
+
+func (bldr *planBuilder) Project(rel *proto.ProjectRel) (*Projection, error) {
 	input, err := bldr.Rel(rel.GetInput())
 	if err != nil {
 		return nil, err
 	}
+	defer bldr.pushScope(input)()
+
+	exprs, err := bldr.Exprs(rel.GetExpressions())
+	if err != nil {
+		return nil, err
+	}
 
 	return NewProjectionOperation(input, exprs), nil
 }
 
 func (bldr *planBuilder) Filter(rel *proto.FilterRel) (*Selection, error) {
-	var err error
+	input, err := bldr.Rel(rel.GetInput())
+	if err != nil {
+		return nil, err
+	}
+	defer bldr.pushScope(input)()
 
 	expr, err := bldr.Expr(rel.GetCondition())
 	if err != nil {
 		return nil, err
 	}
 
+	return NewSelectionOperation(input, expr), nil
+}
+
+func (bldr *planBuilder) Fetch(rel *proto.FetchRel) (*Fetch, error) {
 	input, err := bldr.Rel(rel.GetInput())
 	if err != nil {
 		return nil, err
 	}
 
-	return NewSelectionOperation(input, expr), nil
+	return NewFetchOperation(input, rel.GetOffset(), rel.GetCount()), nil
+}
+
+func (bldr *planBuilder) Aggregate(rel *proto.AggregateRel) (*Aggregate, error) {
+	input, err := bldr.Rel(rel.GetInput())
+	if err != nil {
+		return nil, err
+	}
+	defer bldr.pushScope(input)()
+
+	groupingExprs := make([]Expr, len(rel.GetGroupingExpressions()))
+	for i, expr := range rel.GetGroupingExpressions() {
+		groupingExprs[i], err = bldr.Expr(expr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	groupingSets := make([][]Expr, len(rel.GetGroupings()))
+	for i, grouping := range rel.GetGroupings() {
+		// Groupings may either reference the shared grouping expressions
+		// or use the deprecated form with their own expressions inline
+		set := make([]Expr, 0, len(grouping.GetExpressionReferences()))
+		for _, ref := range grouping.GetExpressionReferences() {
+			if int(ref) >= len(groupingExprs) {
+				return nil, fmt.Errorf("cannot construct Aggregate operation from proto: invalid grouping expression reference: %d", ref)
+			}
+			set = append(set, groupingExprs[ref])
+		}
+
+		for _, e := range grouping.GetGroupingExpressions() {
+			expr, err := bldr.Expr(e)
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, expr)
+		}
+
+		groupingSets[i] = set
+	}
+
+	measures := make([]AggregateExpr, len(rel.GetMeasures()))
+	for i, measure := range rel.GetMeasures() {
+		if measure.GetFilter() != nil {
+			return nil, fmt.Errorf("cannot construct Aggregate operation from proto: FromProto not implemented: AggregateRel_Measure.Filter")
+		}
+
+		measures[i], err = bldr.AggregateFunctionExpr(measure.GetMeasure())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewAggregateOperation(input, groupingSets, measures), nil
+}
+
+func (bldr *planBuilder) Sort(rel *proto.SortRel) (*Sort, error) {
+	input, err := bldr.Rel(rel.GetInput())
+	if err != nil {
+		return nil, err
+	}
+	defer bldr.pushScope(input)()
+
+	fields := make([]SortField, len(rel.GetSorts()))
+	for i, sort := range rel.GetSorts() {
+		fields[i], err = bldr.SortField(sort)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewSortOperation(input, fields), nil
+}
+
+func (bldr *planBuilder) ConsistentPartitionWindow(rel *proto.ConsistentPartitionWindowRel) (*ConsistentPartitionWindow, error) {
+	input, err := bldr.Rel(rel.GetInput())
+	if err != nil {
+		return nil, err
+	}
+	defer bldr.pushScope(input)()
+
+	partitions, err := bldr.Exprs(rel.GetPartitionExpressions())
+	if err != nil {
+		return nil, err
+	}
+
+	sorts := make([]SortField, len(rel.GetSorts()))
+	for i, sort := range rel.GetSorts() {
+		sorts[i], err = bldr.SortField(sort)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The functions of the relation are window functions without partitions
+	// or sorts of their own
+	functions := make([]*WindowFunction, len(rel.GetWindowFunctions()))
+	for i, fn := range rel.GetWindowFunctions() {
+		functions[i], err = bldr.WindowFunctionExpr(&proto.Expression_WindowFunction{
+			FunctionReference: fn.GetFunctionReference(),
+			Arguments:         fn.GetArguments(),
+			Options:           fn.GetOptions(),
+			OutputType:        fn.GetOutputType(),
+			Phase:             fn.GetPhase(),
+			Invocation:        fn.GetInvocation(),
+			BoundsType:        fn.GetBoundsType(),
+			LowerBound:        fn.GetLowerBound(),
+			UpperBound:        fn.GetUpperBound(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewConsistentPartitionWindowOperation(input, partitions, sorts, functions), nil
+}
+
+func (bldr *planBuilder) Join(rel *proto.JoinRel) (*Join, error) {
+	left, err := bldr.Rel(rel.GetLeft())
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := bldr.Rel(rel.GetRight())
+	if err != nil {
+		return nil, err
+	}
+
+	join := NewJoinOperation(left, right, JoinType(rel.GetType()), nil, nil)
+
+	popScope := bldr.pushScope(join.conditionInput())
+	condition, err := bldr.Expr(rel.GetExpression())
+	popScope()
+	if err != nil {
+		return nil, err
+	}
+
+	var postJoinFilter Expr
+	if rel.GetPostJoinFilter() != nil {
+		popScope := bldr.pushScope(join)
+		postJoinFilter, err = bldr.Expr(rel.GetPostJoinFilter())
+		popScope()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewJoinOperation(left, right, JoinType(rel.GetType()), condition, postJoinFilter), nil
+}
+
+func (bldr *planBuilder) Cross(rel *proto.CrossRel) (*Cross, error) {
+	left, err := bldr.Rel(rel.GetLeft())
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := bldr.Rel(rel.GetRight())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCrossOperation(left, right), nil
+}
+
+func (bldr *planBuilder) Write(rel *proto.WriteRel) (*Write, error) {
+	schema, err := bonobo.NewSchemaFromProto(rel.GetTableSchema())
+	if err != nil {
+		return nil, err
+	}
+
+	var table NamedTable
+	switch t := rel.GetWriteType().(type) {
+	case *proto.WriteRel_NamedTable:
+		table = NewNamedTable(t.NamedTable.GetNames(), NewAnonymousCatalog(schema))
+	case *proto.WriteRel_ExtensionTable:
+		return nil, fmt.Errorf("cannot construct Write operation from proto: unimplemented ExtensionTable")
+	default:
+		return nil, fmt.Errorf("cannot construct Write operation from proto: unrecognized write type: %T", t)
+	}
+
+	input, err := bldr.Rel(rel.GetInput())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWriteOperation(input, table, WriteOp(rel.GetOp()), WriteOutputMode(rel.GetOutput())), nil
+}
+
+func (bldr *planBuilder) Ddl(rel *proto.DdlRel) (*Ddl, error) {
+	var identifier Identifier
+	switch t := rel.GetWriteType().(type) {
+	case *proto.DdlRel_NamedObject:
+		identifier = t.NamedObject.GetNames()
+	case *proto.DdlRel_ExtensionObject:
+		return nil, fmt.Errorf("cannot construct Ddl operation from proto: unimplemented ExtensionObject")
+	default:
+		return nil, fmt.Errorf("cannot construct Ddl operation from proto: unrecognized write type: %T", t)
+	}
+
+	if rel.GetTableDefaults() != nil {
+		return nil, fmt.Errorf("cannot construct Ddl operation from proto: unimplemented TableDefaults")
+	}
+
+	switch object := DdlObject(rel.GetObject()); object {
+	case DdlObjectTable:
+		var schema *bonobo.Schema
+		if rel.GetTableSchema() != nil {
+			var err error
+			if schema, err = bonobo.NewSchemaFromProto(rel.GetTableSchema()); err != nil {
+				return nil, err
+			}
+		}
+		return NewTableDdlOperation(identifier, DdlOp(rel.GetOp()), schema), nil
+	case DdlObjectView:
+		var definition Relation
+		if rel.GetViewDefinition() != nil {
+			r, err := bldr.Rel(rel.GetViewDefinition())
+			if err != nil {
+				return nil, err
+			}
+			definition = r
+		}
+		return NewViewDdlOperation(identifier, DdlOp(rel.GetOp()), definition), nil
+	default:
+		return nil, fmt.Errorf("cannot construct Ddl operation from proto: unrecognized object: %s", object)
+	}
+}
+
+func (bldr *planBuilder) Set(rel *proto.SetRel) (*Set, error) {
+	inputs := make([]Relation, len(rel.GetInputs()))
+	for i, input := range rel.GetInputs() {
+		r, err := bldr.Rel(input)
+		if err != nil {
+			return nil, err
+		}
+		inputs[i] = r
+	}
+
+	return NewSetOperation(inputs, SetOp(rel.GetOp())), nil
 }
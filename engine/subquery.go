@@ -0,0 +1,368 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joellubi/bonobo"
+	"github.com/joellubi/bonobo/substrait"
+
+	"github.com/substrait-io/substrait-go/v3/proto"
+	"github.com/substrait-io/substrait-go/v3/types"
+)
+
+// PredicateOp is the property of the records of a subquery tested by a set
+// predicate.
+type PredicateOp proto.Expression_Subquery_SetPredicate_PredicateOp
+
+const (
+	PredicateOpUnspecified = PredicateOp(proto.Expression_Subquery_SetPredicate_PREDICATE_OP_UNSPECIFIED)
+	PredicateOpExists      = PredicateOp(proto.Expression_Subquery_SetPredicate_PREDICATE_OP_EXISTS)
+	PredicateOpUnique      = PredicateOp(proto.Expression_Subquery_SetPredicate_PREDICATE_OP_UNIQUE)
+)
+
+func (op PredicateOp) String() string {
+	name, _ := strings.CutPrefix(proto.Expression_Subquery_SetPredicate_PredicateOp(op).String(), "PREDICATE_OP_")
+	return name
+}
+
+// ComparisonOp is the comparison made between a value and each record of a
+// subquery by a set comparison.
+type ComparisonOp proto.Expression_Subquery_SetComparison_ComparisonOp
+
+const (
+	ComparisonOpUnspecified = ComparisonOp(proto.Expression_Subquery_SetComparison_COMPARISON_OP_UNSPECIFIED)
+	ComparisonOpEq          = ComparisonOp(proto.Expression_Subquery_SetComparison_COMPARISON_OP_EQ)
+	ComparisonOpNe          = ComparisonOp(proto.Expression_Subquery_SetComparison_COMPARISON_OP_NE)
+	ComparisonOpLt          = ComparisonOp(proto.Expression_Subquery_SetComparison_COMPARISON_OP_LT)
+	ComparisonOpGt          = ComparisonOp(proto.Expression_Subquery_SetComparison_COMPARISON_OP_GT)
+	ComparisonOpLe          = ComparisonOp(proto.Expression_Subquery_SetComparison_COMPARISON_OP_LE)
+	ComparisonOpGe          = ComparisonOp(proto.Expression_Subquery_SetComparison_COMPARISON_OP_GE)
+)
+
+func (op ComparisonOp) String() string {
+	name, _ := strings.CutPrefix(proto.Expression_Subquery_SetComparison_ComparisonOp(op).String(), "COMPARISON_OP_")
+	return name
+}
+
+// ReductionOp is how the comparisons made by a set comparison are combined,
+// either requiring ANY or ALL of them to be true.
+type ReductionOp proto.Expression_Subquery_SetComparison_ReductionOp
+
+const (
+	ReductionOpUnspecified = ReductionOp(proto.Expression_Subquery_SetComparison_REDUCTION_OP_UNSPECIFIED)
+	ReductionOpAny         = ReductionOp(proto.Expression_Subquery_SetComparison_REDUCTION_OP_ANY)
+	ReductionOpAll         = ReductionOp(proto.Expression_Subquery_SetComparison_REDUCTION_OP_ALL)
+)
+
+func (op ReductionOp) String() string {
+	name, _ := strings.CutPrefix(proto.Expression_Subquery_SetComparison_ReductionOp(op).String(), "REDUCTION_OP_")
+	return name
+}
+
+type subqueryKind int
+
+const (
+	subqueryScalar subqueryKind = iota
+	subqueryInPredicate
+	subquerySetPredicate
+	subquerySetComparison
+)
+
+// NewScalarSubqueryExpr evaluates to the value output by rel, which must
+// output a single field and at most one record. The result is null if rel
+// outputs no records.
+func NewScalarSubqueryExpr(rel Relation) *Subquery {
+	return &Subquery{kind: subqueryScalar, rel: rel}
+}
+
+// NewInSubqueryExpr is true if needles are equal to the fields of any record
+// output by haystack, like (needles...) IN (SELECT ...).
+func NewInSubqueryExpr(needles []Expr, haystack Relation) *Subquery {
+	return &Subquery{kind: subqueryInPredicate, rel: haystack, needles: needles}
+}
+
+// NewSetPredicateSubqueryExpr tests a property of the records output by rel,
+// such as whether there are any for EXISTS.
+func NewSetPredicateSubqueryExpr(op PredicateOp, rel Relation) *Subquery {
+	return &Subquery{kind: subquerySetPredicate, rel: rel, predicate: op}
+}
+
+// NewSetComparisonSubqueryExpr compares left to the single field of each
+// record output by right and combines the results with reduction, like
+// left > ANY (SELECT ...).
+func NewSetComparisonSubqueryExpr(left Expr, op ComparisonOp, reduction ReductionOp, right Relation) *Subquery {
+	return &Subquery{
+		kind:       subquerySetComparison,
+		rel:        right,
+		needles:    []Expr{left},
+		comparison: op,
+		reduction:  reduction,
+	}
+}
+
+// Subquery is an expression evaluated using the records output by a nested
+// relation. Expressions within the nested relation may refer to the input of
+// the expression with an OuterReference.
+type Subquery struct {
+	kind subqueryKind
+	rel  Relation
+
+	// needles are the values compared to the records of rel, which is the
+	// left side of a set comparison.
+	needles []Expr
+
+	predicate  PredicateOp
+	comparison ComparisonOp
+	reduction  ReductionOp
+}
+
+// Field implements Expr.
+func (expr *Subquery) Field(input Relation) (bonobo.Field, error) {
+	typ, err := expr.resultType(input)
+	if err != nil {
+		return bonobo.Field{}, err
+	}
+
+	return bonobo.Field{Name: expr.String(), Type: typ}, nil
+}
+
+// resultType checks the values compared to the records of the subquery
+// against its schema. Comparisons result in null if any of the values
+// compared may be null.
+func (expr *Subquery) resultType(input Relation) (bonobo.Type, error) {
+	schema, err := expr.rel.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr.kind {
+	case subqueryScalar:
+		if schema.Len() != 1 {
+			return nil, fmt.Errorf("engine: scalar subquery must output a single field, found %d", schema.Len())
+		}
+		return bonobo.WithNullability(schema.Struct.Types[0], types.NullabilityNullable), nil
+	case subquerySetPredicate:
+		return bonobo.Types.BooleanType(false), nil
+	}
+
+	if len(expr.needles) != schema.Len() {
+		return nil, fmt.Errorf("engine: subquery must output %d fields to compare to (%s), found %d", len(expr.needles), ExprList(expr.needles), schema.Len())
+	}
+
+	nullable := false
+	for i, needle := range expr.needles {
+		field, err := needle.Field(input)
+		if err != nil {
+			return nil, err
+		}
+
+		typ, err := unifyTypes(field.Type, schema.Struct.Types[i])
+		if err != nil {
+			return nil, fmt.Errorf("engine: subquery field %s does not match %s: %w", schema.Fields()[i].Name, needle, err)
+		}
+		nullable = nullable || typ.GetNullability() == types.NullabilityNullable
+	}
+
+	return bonobo.Types.BooleanType(nullable), nil
+}
+
+// String implements Expr.
+func (expr *Subquery) String() string {
+	switch expr.kind {
+	case subqueryInPredicate:
+		return fmt.Sprintf("(%s) IN (%s)", ExprList(expr.needles), expr.rel)
+	case subquerySetPredicate:
+		return fmt.Sprintf("%s (%s)", expr.predicate, expr.rel)
+	case subquerySetComparison:
+		return fmt.Sprintf("%s %s %s (%s)", expr.needles[0], expr.comparison, expr.reduction, expr.rel)
+	default:
+		return fmt.Sprintf("(%s)", expr.rel)
+	}
+}
+
+// ToProto implements Expr.
+func (expr *Subquery) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
+	if _, err := expr.resultType(input); err != nil {
+		return nil, err
+	}
+
+	rel, err := expr.rel.ToProto(extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	needles, err := exprsToProto(input, extensions, expr.needles)
+	if err != nil {
+		return nil, err
+	}
+
+	var subquery proto.Expression_Subquery
+	switch expr.kind {
+	case subqueryScalar:
+		subquery.SubqueryType = &proto.Expression_Subquery_Scalar_{
+			Scalar: &proto.Expression_Subquery_Scalar{Input: rel},
+		}
+	case subqueryInPredicate:
+		subquery.SubqueryType = &proto.Expression_Subquery_InPredicate_{
+			InPredicate: &proto.Expression_Subquery_InPredicate{Needles: needles, Haystack: rel},
+		}
+	case subquerySetPredicate:
+		subquery.SubqueryType = &proto.Expression_Subquery_SetPredicate_{
+			SetPredicate: &proto.Expression_Subquery_SetPredicate{
+				PredicateOp: proto.Expression_Subquery_SetPredicate_PredicateOp(expr.predicate),
+				Tuples:      rel,
+			},
+		}
+	case subquerySetComparison:
+		subquery.SubqueryType = &proto.Expression_Subquery_SetComparison_{
+			SetComparison: &proto.Expression_Subquery_SetComparison{
+				ReductionOp:  proto.Expression_Subquery_SetComparison_ReductionOp(expr.reduction),
+				ComparisonOp: proto.Expression_Subquery_SetComparison_ComparisonOp(expr.comparison),
+				Left:         needles[0],
+				Right:        rel,
+			},
+		}
+	}
+
+	return &proto.Expression{
+		RexType: &proto.Expression_Subquery_{Subquery: &subquery},
+	}, nil
+}
+
+// NewOuterReferenceExpr references a field of outer from within a subquery.
+// The outer relation is the input of the expression containing the subquery
+// stepsOut subquery boundaries out from the reference, and ref is resolved
+// against it. The ref must be a Column or ColumnIndex.
+func NewOuterReferenceExpr(outer Relation, stepsOut int, ref Expr) *OuterReference {
+	return &OuterReference{outer: outer, stepsOut: stepsOut, ref: ref}
+}
+
+type OuterReference struct {
+	outer    Relation
+	stepsOut int
+	ref      Expr
+}
+
+// Field implements Expr.
+func (expr *OuterReference) Field(input Relation) (bonobo.Field, error) {
+	return expr.ref.Field(expr.outer)
+}
+
+// String implements Expr.
+func (expr *OuterReference) String() string {
+	return fmt.Sprintf("outer[%d](%s)", expr.stepsOut, expr.ref)
+}
+
+// ToProto implements Expr.
+func (expr *OuterReference) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
+	if expr.stepsOut < 1 {
+		return nil, fmt.Errorf("engine: outer reference %s must step out of at least one subquery", expr.ref)
+	}
+
+	ref, err := expr.ref.ToProto(expr.outer, extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	selection := ref.GetSelection()
+	if selection == nil {
+		return nil, fmt.Errorf("engine: outer reference must be a field reference, found %s", expr.ref)
+	}
+
+	selection.RootType = &proto.Expression_FieldReference_OuterReference_{
+		OuterReference: &proto.Expression_FieldReference_OuterReference{StepsOut: uint32(expr.stepsOut)},
+	}
+
+	return ref, nil
+}
+
+// relationSubqueries returns the relations nested in the expressions of rel,
+// not including those of its children.
+func relationSubqueries(rel Relation) []Relation {
+	var exprs []Expr
+	switch r := rel.(type) {
+	case *Read:
+		exprs = append(exprs, r.filter, r.bestEffortFilter)
+	case *Projection:
+		exprs = r.exprs
+	case *Selection:
+		exprs = append(exprs, r.expr)
+	case *Aggregate:
+		exprs = r.groupingExprs
+		for _, measure := range r.measures {
+			if f, ok := measure.(*AggregateFunction); ok {
+				exprs = append(exprs, f.args...)
+			}
+		}
+	case *Sort:
+		for _, field := range r.fields {
+			exprs = append(exprs, field.Expr)
+		}
+	case *Join:
+		exprs = append(exprs, r.condition, r.postJoinFilter)
+	case *ConsistentPartitionWindow:
+		exprs = append(exprs, r.partitions...)
+		for _, sort := range r.sorts {
+			exprs = append(exprs, sort.Expr)
+		}
+		for _, fn := range r.functions {
+			exprs = append(exprs, fn.children()...)
+		}
+	}
+
+	var subqueries []Relation
+	for _, expr := range exprs {
+		subqueries = appendSubqueries(subqueries, expr)
+	}
+
+	return subqueries
+}
+
+func appendSubqueries(subqueries []Relation, expr Expr) []Relation {
+	var children []Expr
+	switch e := expr.(type) {
+	case *Subquery:
+		subqueries = append(subqueries, e.rel)
+		children = e.needles
+	case *Alias:
+		children = append(children, e.child)
+	case *Cast:
+		children = append(children, e.child)
+	case *Function:
+		children = e.args
+	case *WindowFunction:
+		children = e.children()
+	case *IfThen:
+		for _, clause := range e.clauses {
+			children = append(children, clause.If, clause.Then)
+		}
+		children = append(children, e.els)
+	case *Switch:
+		children = append(children, e.match)
+		for _, c := range e.cases {
+			children = append(children, c.Then)
+		}
+		children = append(children, e.els)
+	case *InList:
+		children = append([]Expr{e.value}, e.options...)
+	case *MultiInList:
+		children = append(children, e.values...)
+		for _, option := range e.options {
+			children = append(children, option...)
+		}
+	case *Nested:
+		children = e.children()
+	case *FieldPath:
+		children = append(children, e.root)
+	}
+
+	for _, child := range children {
+		subqueries = appendSubqueries(subqueries, child)
+	}
+
+	return subqueries
+}
+
+var _ Expr = (*Subquery)(nil)
+var _ Expr = (*OuterReference)(nil)
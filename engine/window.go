@@ -0,0 +1,518 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/joellubi/bonobo"
+	"github.com/joellubi/bonobo/substrait"
+
+	"github.com/substrait-io/substrait-go/v3/proto"
+)
+
+var (
+	ErrWindowFunctionOrdering = errors.New("engine: window function must not have its own partitions or sorts when computed by a ConsistentPartitionWindow")
+)
+
+// BoundsType determines whether the offsets of the bounds of a window count
+// records, or values of the expression that the records are sorted by.
+type BoundsType proto.Expression_WindowFunction_BoundsType
+
+const (
+	BoundsTypeUnspecified = BoundsType(proto.Expression_WindowFunction_BOUNDS_TYPE_UNSPECIFIED)
+	BoundsTypeRows        = BoundsType(proto.Expression_WindowFunction_BOUNDS_TYPE_ROWS)
+	BoundsTypeRange       = BoundsType(proto.Expression_WindowFunction_BOUNDS_TYPE_RANGE)
+)
+
+func (t BoundsType) String() string {
+	name, _ := strings.CutPrefix(proto.Expression_WindowFunction_BoundsType(t).String(), "BOUNDS_TYPE_")
+	return name
+}
+
+type windowBoundKind int
+
+const (
+	boundUnset windowBoundKind = iota
+	boundOffset
+	boundUnbounded
+)
+
+// WindowBound is the first or last record of the window that a window
+// function is computed over, relative to the current record. The zero value
+// leaves the bound unspecified.
+type WindowBound struct {
+	kind windowBoundKind
+
+	// offset is negative for preceding records and positive for following
+	// records, so the current record has an offset of 0
+	offset int64
+}
+
+// PrecedingBound bounds the window offset records before the current record.
+func PrecedingBound(offset int64) WindowBound {
+	return WindowBound{kind: boundOffset, offset: -offset}
+}
+
+// FollowingBound bounds the window offset records after the current record.
+func FollowingBound(offset int64) WindowBound {
+	return WindowBound{kind: boundOffset, offset: offset}
+}
+
+// CurrentRowBound bounds the window at the current record.
+func CurrentRowBound() WindowBound {
+	return WindowBound{kind: boundOffset}
+}
+
+// UnboundedBound extends the window to the start of the partition when it is
+// the lower bound, or to its end when it is the upper bound.
+func UnboundedBound() WindowBound {
+	return WindowBound{kind: boundUnbounded}
+}
+
+// format describes the bound, which is the lower bound of the window if lower
+// is set.
+func (b WindowBound) format(lower bool) string {
+	switch {
+	case b.kind == boundUnbounded && lower:
+		return "UNBOUNDED PRECEDING"
+	case b.kind == boundUnbounded:
+		return "UNBOUNDED FOLLOWING"
+	case b.offset < 0:
+		return fmt.Sprintf("%d PRECEDING", -b.offset)
+	case b.offset > 0:
+		return fmt.Sprintf("%d FOLLOWING", b.offset)
+	default:
+		return "CURRENT ROW"
+	}
+}
+
+func (b WindowBound) toProto() *proto.Expression_WindowFunction_Bound {
+	switch {
+	case b.kind == boundUnset:
+		return nil
+	case b.kind == boundUnbounded:
+		return &proto.Expression_WindowFunction_Bound{
+			Kind: &proto.Expression_WindowFunction_Bound_Unbounded_{
+				Unbounded: &proto.Expression_WindowFunction_Bound_Unbounded{},
+			},
+		}
+	case b.offset < 0:
+		return &proto.Expression_WindowFunction_Bound{
+			Kind: &proto.Expression_WindowFunction_Bound_Preceding_{
+				Preceding: &proto.Expression_WindowFunction_Bound_Preceding{Offset: -b.offset},
+			},
+		}
+	case b.offset > 0:
+		return &proto.Expression_WindowFunction_Bound{
+			Kind: &proto.Expression_WindowFunction_Bound_Following_{
+				Following: &proto.Expression_WindowFunction_Bound_Following{Offset: b.offset},
+			},
+		}
+	default:
+		return &proto.Expression_WindowFunction_Bound{
+			Kind: &proto.Expression_WindowFunction_Bound_CurrentRow_{
+				CurrentRow: &proto.Expression_WindowFunction_Bound_CurrentRow{},
+			},
+		}
+	}
+}
+
+func NewWindowFunctionExpr(uri, name string, args ...Expr) *WindowFunction {
+	return &WindowFunction{
+		uri:        uri,
+		name:       name,
+		args:       args,
+		repository: DefaultFunctionRepository,
+		phase:      AggregationPhaseInitialToResult,
+		invocation: AggregationInvocationAll,
+	}
+}
+
+func NewRowNumberFunctionExpr() *WindowFunction {
+	return NewWindowFunctionExpr(
+		"https://github.com/substrait-io/substrait/blob/main/extensions/functions_arithmetic.yaml",
+		"row_number",
+	)
+}
+
+func NewRankFunctionExpr() *WindowFunction {
+	return NewWindowFunctionExpr(
+		"https://github.com/substrait-io/substrait/blob/main/extensions/functions_arithmetic.yaml",
+		"rank",
+	)
+}
+
+func NewAnonymousWindowFunction(uri, signature string, output bonobo.Type, args ...Expr) (*WindowFunction, error) {
+	repo := substrait.NewAnonymousFunctionRepository(signature, output)
+
+	name, _, found := strings.Cut(signature, ":")
+	if !found {
+		return nil, fmt.Errorf("invalid function signature: %s", signature)
+	}
+
+	return &WindowFunction{
+		uri:        uri,
+		name:       name,
+		args:       args,
+		repository: repo,
+		phase:      AggregationPhaseInitialToResult,
+		invocation: AggregationInvocationAll,
+	}, nil
+}
+
+// Over returns a window function that computes the aggregate over the window
+// of records around each record, rather than combining all of them into a
+// single value.
+func (f *AggregateFunction) Over() *WindowFunction {
+	return &WindowFunction{
+		uri:        f.uri,
+		name:       f.name,
+		args:       f.args,
+		repository: f.repository,
+		phase:      f.phase,
+		invocation: f.invocation,
+	}
+}
+
+// WindowFunction is an expression computed for each record over a window of
+// the records in the same partition, such as rank() or a running total. The
+// records of each partition are those with the same values for partitions,
+// ordered by sorts, and the window spans the records from the lower bound to
+// the upper bound.
+type WindowFunction struct {
+	uri, name  string
+	args       []Expr
+	repository substrait.FunctionRepository
+
+	partitions ExprList
+	sorts      []SortField
+
+	boundsType   BoundsType
+	lower, upper WindowBound
+
+	phase      AggregationPhase
+	invocation AggregationInvocation
+}
+
+// WithPartitions returns a copy of the function that partitions the records
+// by the values of exprs.
+func (f *WindowFunction) WithPartitions(exprs ...Expr) *WindowFunction {
+	out := *f
+	out.partitions = exprs
+	return &out
+}
+
+// WithSorts returns a copy of the function that orders the records of each
+// partition by fields.
+func (f *WindowFunction) WithSorts(fields ...SortField) *WindowFunction {
+	out := *f
+	out.sorts = fields
+	return &out
+}
+
+// WithBounds returns a copy of the function computed over the window from
+// lower to upper, with offsets counted as described by typ.
+func (f *WindowFunction) WithBounds(typ BoundsType, lower, upper WindowBound) *WindowFunction {
+	out := *f
+	out.boundsType = typ
+	out.lower = lower
+	out.upper = upper
+	return &out
+}
+
+// WithPhase returns a copy of the function that computes the provided phase
+// of the aggregation.
+func (f *WindowFunction) WithPhase(phase AggregationPhase) *WindowFunction {
+	out := *f
+	out.phase = phase
+	return &out
+}
+
+// WithInvocation returns a copy of the function with the provided invocation.
+func (f *WindowFunction) WithInvocation(invocation AggregationInvocation) *WindowFunction {
+	out := *f
+	out.invocation = invocation
+	return &out
+}
+
+// Distinct returns a copy of the function that only considers distinct values.
+func (f *WindowFunction) Distinct() *WindowFunction {
+	return f.WithInvocation(AggregationInvocationDistinct)
+}
+
+// Field implements Expr.
+func (f *WindowFunction) Field(input Relation) (bonobo.Field, error) {
+	_, outputType, err := f.resolve(input)
+	if err != nil {
+		return bonobo.Field{}, err
+	}
+
+	return bonobo.Field{Name: f.String(), Type: outputType}, nil
+}
+
+// String implements Expr.
+func (f *WindowFunction) String() string {
+	args := make([]string, len(f.args))
+	for i, arg := range f.args {
+		args[i] = arg.String()
+	}
+
+	var distinct string
+	if f.invocation == AggregationInvocationDistinct {
+		distinct = "DISTINCT "
+	}
+
+	return fmt.Sprintf("%s(%s%s) OVER (%s)", f.name, distinct, strings.Join(args, ", "), f.window())
+}
+
+// window describes the partitions, sorts and bounds of the window.
+func (f *WindowFunction) window() string {
+	var clauses []string
+	if len(f.partitions) > 0 {
+		clauses = append(clauses, fmt.Sprintf("PARTITION BY %s", f.partitions))
+	}
+
+	if len(f.sorts) > 0 {
+		sorts := make([]string, len(f.sorts))
+		for i, sort := range f.sorts {
+			sorts[i] = sort.String()
+		}
+		clauses = append(clauses, fmt.Sprintf("ORDER BY %s", strings.Join(sorts, ", ")))
+	}
+
+	if f.lower.kind != boundUnset || f.upper.kind != boundUnset {
+		clauses = append(clauses, fmt.Sprintf("%s BETWEEN %s AND %s", f.boundsType, f.lower.format(true), f.upper.format(false)))
+	}
+
+	return strings.Join(clauses, " ")
+}
+
+// ToProto implements Expr.
+func (f *WindowFunction) ToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.Expression, error) {
+	impl, outputType, err := f.resolve(input)
+	if err != nil {
+		return nil, err
+	}
+
+	functionArgs, err := functionArguments(input, extensions, f.args)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions, err := exprsToProto(input, extensions, f.partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	sorts := make([]*proto.SortField, len(f.sorts))
+	for i, sort := range f.sorts {
+		sorts[i], err = sort.ToProto(input, extensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	outputTypeProto, err := bonobo.TypeToProto(outputType)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := extensions.RegisterFunction(f.uri, impl.Signature())
+
+	return &proto.Expression{
+		RexType: &proto.Expression_WindowFunction_{
+			WindowFunction: &proto.Expression_WindowFunction{
+				FunctionReference: ref,
+				Arguments:         functionArgs,
+				OutputType:        outputTypeProto,
+				Phase:             proto.AggregationPhase(f.phase),
+				Sorts:             sorts,
+				Invocation:        proto.AggregateFunction_AggregationInvocation(f.invocation),
+				Partitions:        partitions,
+				BoundsType:        proto.Expression_WindowFunction_BoundsType(f.boundsType),
+				LowerBound:        f.lower.toProto(),
+				UpperBound:        f.upper.toProto(),
+			},
+		},
+	}, nil
+}
+
+// checkRelFunction returns an error if the function cannot be computed by a
+// ConsistentPartitionWindow, which partitions and sorts the records for it.
+func (f *WindowFunction) checkRelFunction() error {
+	if len(f.partitions) > 0 || len(f.sorts) > 0 {
+		return fmt.Errorf("%w: %s", ErrWindowFunctionOrdering, f)
+	}
+
+	return nil
+}
+
+// relFunctionToProto serializes the function as one of those computed by a
+// ConsistentPartitionWindow, which partitions and sorts the records for it.
+func (f *WindowFunction) relFunctionToProto(input Relation, extensions *substrait.ExtensionRegistry) (*proto.ConsistentPartitionWindowRel_WindowRelFunction, error) {
+	if err := f.checkRelFunction(); err != nil {
+		return nil, err
+	}
+
+	expr, err := f.ToProto(input, extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	fn := expr.GetWindowFunction()
+	return &proto.ConsistentPartitionWindowRel_WindowRelFunction{
+		FunctionReference: fn.GetFunctionReference(),
+		Arguments:         fn.GetArguments(),
+		OutputType:        fn.GetOutputType(),
+		Phase:             fn.GetPhase(),
+		Invocation:        fn.GetInvocation(),
+		LowerBound:        fn.GetLowerBound(),
+		UpperBound:        fn.GetUpperBound(),
+		BoundsType:        fn.GetBoundsType(),
+	}, nil
+}
+
+// resolve finds the implementation matching the function arguments and
+// determines the output type for the phase being computed. Aggregate
+// functions may be computed over a window as well as window functions.
+func (f *WindowFunction) resolve(input Relation) (substrait.FunctionImplementation, bonobo.Type, error) {
+	args, err := argumentTypes(input, f.args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	impl, err := f.repository.GetImplementation(f.uri, f.name, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outputType, err := aggregateOutputType(impl, f.phase, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return impl, outputType, nil
+}
+
+// children lists the expressions that the function is computed from,
+// including those that partition and sort the records.
+func (f *WindowFunction) children() []Expr {
+	children := make([]Expr, 0, len(f.args)+len(f.partitions)+len(f.sorts))
+	children = append(children, f.args...)
+	children = append(children, f.partitions...)
+	for _, sort := range f.sorts {
+		children = append(children, sort.Expr)
+	}
+	return children
+}
+
+// NewConsistentPartitionWindowOperation computes functions over the records
+// of input that have the same values for partitions, ordered by sorts. The
+// functions must not have partitions or sorts of their own. The output has
+// the fields of input followed by a field for each function.
+func NewConsistentPartitionWindowOperation(input Relation, partitions []Expr, sorts []SortField, functions []*WindowFunction) *ConsistentPartitionWindow {
+	return &ConsistentPartitionWindow{input: input, partitions: partitions, sorts: sorts, functions: functions}
+}
+
+type ConsistentPartitionWindow struct {
+	input Relation
+
+	partitions ExprList
+	sorts      []SortField
+	functions  []*WindowFunction
+}
+
+func (w *ConsistentPartitionWindow) ToProto(extensions *substrait.ExtensionRegistry) (*proto.Rel, error) {
+	functions := make([]*proto.ConsistentPartitionWindowRel_WindowRelFunction, len(w.functions))
+	for i, fn := range w.functions {
+		var err error
+		functions[i], err = fn.relFunctionToProto(w.input, extensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	partitions, err := exprsToProto(w.input, extensions, w.partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	sorts := make([]*proto.SortField, len(w.sorts))
+	for i, sort := range w.sorts {
+		sorts[i], err = sort.ToProto(w.input, extensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	childRel, err := w.input.ToProto(extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.Rel{
+		RelType: &proto.Rel_Window{
+			Window: &proto.ConsistentPartitionWindowRel{
+				Input:                childRel,
+				WindowFunctions:      functions,
+				PartitionExpressions: partitions,
+				Sorts:                sorts,
+			},
+		},
+	}, nil
+}
+
+func (w *ConsistentPartitionWindow) Schema() (*bonobo.Schema, error) {
+	schema, err := w.input.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := schema.Fields()
+	for _, fn := range w.functions {
+		if err := fn.checkRelFunction(); err != nil {
+			return nil, err
+		}
+
+		f, err := fn.Field(w.input)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+
+	return bonobo.NewSchema(fields), nil
+}
+
+func (w *ConsistentPartitionWindow) Children() []Relation {
+	return []Relation{w.input}
+}
+
+func (w *ConsistentPartitionWindow) String() string {
+	sorts := make([]string, len(w.sorts))
+	for i, sort := range w.sorts {
+		sorts[i] = sort.String()
+	}
+
+	functions := make([]string, len(w.functions))
+	for i, fn := range w.functions {
+		functions[i] = fn.String()
+	}
+
+	return fmt.Sprintf("ConsistentPartitionWindow: partitions=[%s], sorts=[%s], functions=[%s]", w.partitions, strings.Join(sorts, ", "), strings.Join(functions, ", "))
+}
+
+func (w *ConsistentPartitionWindow) fieldQualifiers() ([][]string, error) {
+	qualifiers, err := fieldQualifiers(w.input)
+	if err != nil {
+		return nil, err
+	}
+
+	// The fields computed by the functions cannot be qualified
+	return slices.Concat(qualifiers, make([][]string, len(w.functions))), nil
+}
+
+var _ Expr = (*WindowFunction)(nil)
+var _ Relation = (*ConsistentPartitionWindow)(nil)
@@ -13,8 +13,16 @@ import (
 	"github.com/joellubi/bonobo/df"
 	"github.com/joellubi/bonobo/engine"
 
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/decimal128"
+	"github.com/apache/arrow/go/v17/arrow/memory"
 	"github.com/stretchr/testify/require"
 	"github.com/substrait-io/substrait-go/v3/proto"
+	"github.com/substrait-io/substrait-go/v3/types"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 var update = flag.Bool("update", false, "update golden files")
@@ -39,6 +47,22 @@ func (*testCatalog) Schema(identifier []string) (*bonobo.Schema, error) {
 				{Name: "col5", Type: bonobo.Types.DateType(false)},
 			},
 		)
+	case "test_db.main.table2":
+		schema = bonobo.NewSchema(
+			[]bonobo.Field{
+				{Name: "id", Type: bonobo.Types.Int64Type(false)},
+				{Name: "flag", Type: bonobo.Types.BooleanType(false)},
+			},
+		)
+	case "test_db.main.table3":
+		schema = bonobo.NewSchema(
+			[]bonobo.Field{
+				{Name: "id", Type: bonobo.Types.Int64Type(true)},
+				{Name: "label", Type: bonobo.Types.StringType(true)},
+			},
+		)
+	case "test_db.main.events":
+		schema = testEventsSchema()
 	default:
 		err = fmt.Errorf("table not found: %s", fqTableName)
 	}
@@ -46,8 +70,70 @@ func (*testCatalog) Schema(identifier []string) (*bonobo.Schema, error) {
 	return schema, err
 }
 
+// testEventsSchema has a field of each of the types that are not used by the
+// other tables, including nested types.
+func testEventsSchema() *bonobo.Schema {
+	return bonobo.NewSchema(
+		[]bonobo.Field{
+			{Name: "id", Type: bonobo.Types.UUIDType(false)},
+			{Name: "at_time", Type: bonobo.Types.TimeType(true)},
+			{Name: "at_ts", Type: bonobo.Types.TimestampType(false)},
+			{Name: "at_ts_tz", Type: bonobo.Types.TimestampTzType(true)},
+			{Name: "at_ts_ns", Type: bonobo.Types.PrecisionTimestampType(9, false)},
+			{Name: "at_ts_tz_us", Type: bonobo.Types.PrecisionTimestampTzType(6, true)},
+			{Name: "age", Type: bonobo.Types.IntervalYearType(true)},
+			{Name: "elapsed", Type: bonobo.Types.IntervalDayType(3, false)},
+			{Name: "period", Type: bonobo.Types.IntervalCompoundType(6, true)},
+			{Name: "payload", Type: bonobo.Types.BinaryType(true)},
+			{Name: "digest", Type: bonobo.Types.FixedBinaryType(32, false)},
+			{Name: "code", Type: bonobo.Types.FixedCharType(3, false)},
+			{Name: "label", Type: bonobo.Types.VarCharType(64, true)},
+			bonobo.NewListField("tags", bonobo.Field{Type: bonobo.Types.StringType(false)}, true),
+			bonobo.NewMapField(
+				"attributes",
+				bonobo.Field{Type: bonobo.Types.StringType(false)},
+				bonobo.Field{Type: bonobo.Types.Int64Type(true)},
+				false,
+			),
+			bonobo.NewStructField(
+				"source",
+				[]bonobo.Field{
+					{Name: "host", Type: bonobo.Types.StringType(false)},
+					bonobo.NewStructField(
+						"location",
+						[]bonobo.Field{
+							{Name: "lat", Type: bonobo.Types.DoubleType(false)},
+							{Name: "lon", Type: bonobo.Types.DoubleType(false)},
+						},
+						true,
+					),
+				},
+				false,
+			),
+		},
+	)
+}
+
 var _ engine.Catalog = (*testCatalog)(nil)
 
+// readFilterExists filters table1 by a subquery on table2 that is correlated
+// with it by an outer reference.
+func readFilterExists() df.DataFrame {
+	outer := df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil))
+	subquery := df.QueryContext().
+		Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)).
+		Filter(
+			engine.NewFunctionExpr(
+				"https://github.com/substrait-io/substrait/blob/main/extensions/functions_comparison.yaml",
+				"equal",
+				df.ColIdx(0),
+				df.Outer(outer.LogicalPlan(), 1, df.ColIdx(2)),
+			),
+		)
+
+	return outer.Filter(df.Exists(subquery))
+}
+
 var testcases = []struct {
 	Name           string
 	Input          df.DataFrame
@@ -154,36 +240,1317 @@ var testcases = []struct {
 			Select(df.Add(df.ColIdx(2), df.Lit(1))),
 		Catalog: &testCatalog{},
 	},
-	// {
-	// 	Name: "read_project_plus_one_alias",
-	// 	Input: df.QueryContext().
-	// 		Read(
-	// 			engine.NewNamedTable(
-	// 				[]string{"test_db", "main", "table1"},
-	// 				nil,
-	// 			),
-	// 		).
-	// 		Select(
-	// 			df.As(
-	// 				df.Add(df.ColIdx(2), df.Lit(1)),
-	// 				"custom_name",
-	// 			),
-	// 		),
-	// 	ExpectedOutput: df.QueryContext().
-	// 		Read(
-	// 			engine.NewNamedTable(
-	// 				[]string{"test_db", "main", "table1"},
-	// 				nil,
-	// 			),
-	// 		).
-	// 		Select(
-	// 			df.As(
-	// 				df.Add(df.ColIdx(2), df.Lit(1)),
-	// 				"custom_name",
-	// 			),
-	// 		),
-	// 	Catalog: &testCatalog{},
-	// },
+	{
+		Name: "read_project_fetch",
+		Input: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Select(df.ColIdx(1)).
+			Limit(5, 10),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Select(df.ColIdx(1)).
+			Limit(5, 10),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_aggregate",
+		Input: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Aggregate(
+				[]engine.Expr{df.ColIdx(1)},
+				[]engine.AggregateExpr{df.Sum(df.ColIdx(2))},
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Aggregate(
+				[]engine.Expr{df.ColIdx(1)},
+				[]engine.AggregateExpr{df.Sum(df.ColIdx(2))},
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_aggregate_grouping_sets",
+		Input: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			AggregateGroupingSets(
+				[][]engine.Expr{
+					{df.ColIdx(0), df.ColIdx(1)},
+					{df.ColIdx(1)},
+					{},
+				},
+				[]engine.AggregateExpr{df.Sum(df.ColIdx(2))},
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			AggregateGroupingSets(
+				[][]engine.Expr{
+					{df.ColIdx(0), df.ColIdx(1)},
+					{df.ColIdx(1)},
+					{},
+				},
+				[]engine.AggregateExpr{df.Sum(df.ColIdx(2))},
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_aggregate_distinct_partial",
+		Input: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Aggregate(
+				[]engine.Expr{df.ColIdx(1)},
+				[]engine.AggregateExpr{
+					df.Sum(df.ColIdx(2)).Distinct(),
+					df.Sum(df.ColIdx(2)).WithPhase(engine.AggregationPhaseInitialToIntermediate),
+				},
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Aggregate(
+				[]engine.Expr{df.ColIdx(1)},
+				[]engine.AggregateExpr{
+					df.Sum(df.ColIdx(2)).Distinct(),
+					df.Sum(df.ColIdx(2)).WithPhase(engine.AggregationPhaseInitialToIntermediate),
+				},
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_sort",
+		Input: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Sort(df.Desc(df.ColIdx(2)), df.Asc(df.ColIdx(1))),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Sort(df.Desc(df.ColIdx(2)), df.Asc(df.ColIdx(1))),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_join_inner",
+		Input: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Join(
+				df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)),
+				engine.JoinTypeInner,
+				df.ColIdx(6),
+				df.ColIdx(0),
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Join(
+				df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)),
+				engine.JoinTypeInner,
+				df.ColIdx(6),
+				df.ColIdx(0),
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_join_left",
+		Input: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Join(
+				df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)),
+				engine.JoinTypeLeft,
+				df.ColIdx(6),
+				nil,
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Join(
+				df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)),
+				engine.JoinTypeLeft,
+				df.ColIdx(6),
+				nil,
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_join_left_semi",
+		Input: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Join(
+				df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)),
+				engine.JoinTypeLeftSemi,
+				df.ColIdx(6),
+				nil,
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Join(
+				df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)),
+				engine.JoinTypeLeftSemi,
+				df.ColIdx(6),
+				nil,
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_union_all",
+		Input: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Select(df.Col("col3"), df.Col("col1")).
+			Union(
+				df.QueryContext().
+					Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)).
+					Select(df.Col("id"), df.Col("flag")),
+				df.QueryContext().
+					Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)),
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Select(df.ColIdx(2), df.ColIdx(0)).
+			Union(
+				df.QueryContext().
+					Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)).
+					Select(df.ColIdx(0), df.ColIdx(1)),
+				df.QueryContext().
+					Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)),
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_except",
+		Input: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)).
+			Except(
+				df.QueryContext().
+					Read(
+						engine.NewNamedTable(
+							[]string{"test_db", "main", "table1"},
+							nil,
+						),
+					).
+					Select(df.Col("col3"), df.Col("col1")),
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)).
+			Except(
+				df.QueryContext().
+					Read(
+						engine.NewNamedTable(
+							[]string{"test_db", "main", "table1"},
+							nil,
+						),
+					).
+					Select(df.ColIdx(2), df.ColIdx(0)),
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_cross_filter",
+		Input: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			CrossJoin(df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil))).
+			Filter(df.Col("flag")),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			CrossJoin(df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil))).
+			Filter(df.ColIdx(6)),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_project_write_insert",
+		Input: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Select(df.Col("col3"), df.Col("col1")).
+			Write(
+				engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil),
+				engine.WriteOpInsert,
+				engine.WriteOutputModeModifiedRecords,
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewNamedTable(
+					[]string{"test_db", "main", "table1"},
+					nil,
+				),
+			).
+			Select(df.ColIdx(2), df.ColIdx(0)).
+			Write(
+				engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil),
+				engine.WriteOpInsert,
+				engine.WriteOutputModeModifiedRecords,
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "ddl_create_table",
+		Input: df.QueryContext().
+			TableDdl(
+				[]string{"test_db", "main", "table4"},
+				engine.DdlOpCreate,
+				bonobo.NewSchema([]bonobo.Field{
+					{Name: "id", Type: bonobo.Types.Int64Type(false)},
+					{Name: "label", Type: bonobo.Types.StringType(true)},
+				}),
+			),
+		ExpectedOutput: df.QueryContext().
+			TableDdl(
+				[]string{"test_db", "main", "table4"},
+				engine.DdlOpCreate,
+				bonobo.NewSchema([]bonobo.Field{
+					{Name: "id", Type: bonobo.Types.Int64Type(false)},
+					{Name: "label", Type: bonobo.Types.StringType(true)},
+				}),
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "ddl_create_view",
+		Input: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Filter(df.Col("col1")).
+			ViewDdl([]string{"test_db", "main", "view1"}, engine.DdlOpCreateOrReplace),
+		ExpectedOutput: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Filter(df.ColIdx(0)).
+			ViewDdl([]string{"test_db", "main", "view1"}, engine.DdlOpCreateOrReplace),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_virtual_table_filter",
+		Input: df.QueryContext().
+			Read(engine.NewVirtualTable(testRecord())).
+			Filter(df.Col("active")),
+		ExpectedOutput: df.QueryContext().
+			Read(engine.NewVirtualTable(testRecord())).
+			Filter(df.Col("active")),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_local_files_parquet_folder",
+		Input: df.QueryContext().
+			Read(
+				engine.NewLocalFilesTable(
+					[]engine.FileItem{{Path: "testdata/localfiles/orders", PathType: engine.FilePathTypeURIFolder}},
+					engine.ParquetFormat{},
+					nil,
+				),
+			).
+			Select(df.Col("order_id"), df.Col("amount")),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewLocalFilesTable(
+					[]engine.FileItem{{Path: "testdata/localfiles/orders", PathType: engine.FilePathTypeURIFolder}},
+					engine.ParquetFormat{},
+					nil,
+				),
+			).
+			Select(df.Col("order_id"), df.Col("amount")),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_local_files_arrow_glob",
+		Input: df.QueryContext().
+			Read(
+				engine.NewLocalFilesTable(
+					[]engine.FileItem{{Path: "file:testdata/localfiles/*.arrow", PathType: engine.FilePathTypeURIPathGlob}},
+					engine.ArrowFormat{},
+					nil,
+				),
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewLocalFilesTable(
+					[]engine.FileItem{{Path: "file:testdata/localfiles/*.arrow", PathType: engine.FilePathTypeURIPathGlob}},
+					engine.ArrowFormat{},
+					nil,
+				),
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_local_files_delimited_text",
+		Input: df.QueryContext().
+			Read(
+				engine.NewLocalFilesTable(
+					[]engine.FileItem{
+						{Path: "s3://bucket/events/part-0.csv", PathType: engine.FilePathTypeURIFile, PartitionIndex: 0, Length: 1024},
+						{Path: "s3://bucket/events/part-0.csv", PathType: engine.FilePathTypeURIFile, PartitionIndex: 1, Start: 1024, Length: 1024},
+					},
+					engine.DelimitedTextFormat{FieldDelimiter: ",", Quote: "\"", HeaderLinesToSkip: 1, Escape: "\\"},
+					bonobo.NewSchema(
+						[]bonobo.Field{
+							{Name: "event", Type: bonobo.Types.StringType(false)},
+							{Name: "payload", Type: bonobo.Types.StringType(false)},
+						},
+					),
+				),
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				engine.NewLocalFilesTable(
+					[]engine.FileItem{
+						{Path: "s3://bucket/events/part-0.csv", PathType: engine.FilePathTypeURIFile, PartitionIndex: 0, Length: 1024},
+						{Path: "s3://bucket/events/part-0.csv", PathType: engine.FilePathTypeURIFile, PartitionIndex: 1, Start: 1024, Length: 1024},
+					},
+					engine.DelimitedTextFormat{FieldDelimiter: ",", Quote: "\"", HeaderLinesToSkip: 1, Escape: "\\"},
+					bonobo.NewSchema(
+						[]bonobo.Field{
+							{Name: "event", Type: bonobo.Types.StringType(false)},
+							{Name: "payload", Type: bonobo.Types.StringType(false)},
+						},
+					),
+				),
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_extension_table_filter",
+		Input: df.QueryContext().
+			Read(
+				mustExtensionTable(engine.NewExtensionTable(
+					snapshotCodec.TypeURL(),
+					testSnapshotRef(),
+					bonobo.NewSchema(
+						[]bonobo.Field{
+							{Name: "id", Type: bonobo.Types.Int64Type(false)},
+							{Name: "deleted", Type: bonobo.Types.BooleanType(false)},
+						},
+					),
+				)),
+			).
+			Filter(df.Col("deleted")),
+		ExpectedOutput: df.QueryContext().
+			Read(
+				mustExtensionTable(engine.NewExtensionTable(
+					snapshotCodec.TypeURL(),
+					testSnapshotRef(),
+					bonobo.NewSchema(
+						[]bonobo.Field{
+							{Name: "id", Type: bonobo.Types.Int64Type(false)},
+							{Name: "deleted", Type: bonobo.Types.BooleanType(false)},
+						},
+					),
+				)),
+			).
+			Filter(df.Col("deleted")),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_pushdown_projection_filter",
+		Input: df.QueryContext().
+			ReadOperation(
+				engine.NewReadOperation(
+					engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil),
+				).
+					WithProjection(2, 0).
+					WithFilter(df.Col("col1")).
+					WithBestEffortFilter(
+						engine.NewFunctionExpr(
+							"https://github.com/substrait-io/substrait/blob/main/extensions/functions_comparison.yaml",
+							"gt",
+							df.Col("col3"),
+							df.Lit(int64(10)),
+						),
+					),
+			).
+			Select(df.Col("col1")),
+		ExpectedOutput: df.QueryContext().
+			ReadOperation(
+				engine.NewReadOperation(
+					engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil),
+				).
+					WithProjection(2, 0).
+					WithFilter(df.Col("col1")).
+					WithBestEffortFilter(
+						engine.NewFunctionExpr(
+							"https://github.com/substrait-io/substrait/blob/main/extensions/functions_comparison.yaml",
+							"gt",
+							df.Col("col3"),
+							df.Lit(int64(10)),
+						),
+					),
+			).
+			Select(df.Col("col1")),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_project_cast",
+		Input: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(
+				df.Cast(df.ColIdx(1), bonobo.Types.DateType(true), engine.CastFailureReturnNull),
+				df.Cast(df.ColIdx(2), bonobo.Types.DecimalType(20, 0, false), engine.CastFailureUnspecified),
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(
+				df.Cast(df.ColIdx(1), bonobo.Types.DateType(true), engine.CastFailureReturnNull),
+				df.Cast(df.ColIdx(2), bonobo.Types.DecimalType(20, 0, false), engine.CastFailureUnspecified),
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_project_if_then",
+		Input: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(
+				df.IfThen(
+					[]engine.IfClause{
+						{If: df.ColIdx(0), Then: df.ColIdx(1)},
+					},
+					df.Lit("none"),
+				),
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(
+				df.IfThen(
+					[]engine.IfClause{
+						{If: df.ColIdx(0), Then: df.ColIdx(1)},
+					},
+					df.Lit("none"),
+				),
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_project_switch",
+		Input: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(
+				df.Switch(
+					df.ColIdx(2),
+					[]engine.SwitchCase{
+						{Value: df.Lit(int64(1)), Then: df.ColIdx(4)},
+					},
+					nil,
+				),
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(
+				df.Switch(
+					df.ColIdx(2),
+					[]engine.SwitchCase{
+						{Value: df.Lit(int64(1)), Then: df.ColIdx(4)},
+					},
+					nil,
+				),
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_filter_in_list",
+		Input: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Filter(df.In(df.ColIdx(2), df.Lit(int64(1)), df.Lit(int64(2)))).
+			Filter(
+				df.MultiIn(
+					[]engine.Expr{df.ColIdx(1), df.ColIdx(2)},
+					[]engine.Expr{df.Lit("a"), df.Lit(int64(1))},
+					[]engine.Expr{df.Lit("b"), df.Lit(int64(2))},
+				),
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Filter(df.In(df.ColIdx(2), df.Lit(int64(1)), df.Lit(int64(2)))).
+			Filter(
+				df.MultiIn(
+					[]engine.Expr{df.ColIdx(1), df.ColIdx(2)},
+					[]engine.Expr{df.Lit("a"), df.Lit(int64(1))},
+					[]engine.Expr{df.Lit("b"), df.Lit(int64(2))},
+				),
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name:           "read_filter_correlated_exists",
+		Input:          readFilterExists(),
+		ExpectedOutput: readFilterExists(),
+		Catalog:        &testCatalog{},
+	},
+	{
+		Name: "read_project_nested",
+		Input: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(
+				df.List(df.ColIdx(2), df.Lit(int64(1))),
+				df.Struct(df.ColIdx(0), df.ColIdx(1)),
+				df.Map(
+					engine.MapEntry{Key: df.Lit("a"), Value: df.ColIdx(3)},
+					engine.MapEntry{Key: df.Lit("b"), Value: df.ColIdx(3)},
+				),
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(
+				df.List(df.ColIdx(2), df.Lit(int64(1))),
+				df.Struct(df.ColIdx(0), df.ColIdx(1)),
+				df.Map(
+					engine.MapEntry{Key: df.Lit("a"), Value: df.ColIdx(3)},
+					engine.MapEntry{Key: df.Lit("b"), Value: df.ColIdx(3)},
+				),
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_project_field_paths",
+		Input: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(
+				df.As(df.Struct(df.ColIdx(0), df.ColIdx(2)), "s"),
+				df.Path(df.List(df.ColIdx(2), df.Lit(int64(1))), engine.ListElementSegment(1)),
+				df.Path(
+					df.Map(engine.MapEntry{Key: df.Lit("a"), Value: df.ColIdx(3)}),
+					engine.MapKeySegment(df.Lit("a")),
+				),
+			).
+			Select(df.Path(df.ColIdx(0), engine.StructFieldSegment(1)), df.ColIdx(1), df.ColIdx(2)),
+		ExpectedOutput: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(
+				df.As(df.Struct(df.ColIdx(0), df.ColIdx(2)), "s"),
+				df.Path(df.List(df.ColIdx(2), df.Lit(int64(1))), engine.ListElementSegment(1)),
+				df.Path(
+					df.Map(engine.MapEntry{Key: df.Lit("a"), Value: df.ColIdx(3)}),
+					engine.MapKeySegment(df.Lit("a")),
+				),
+			).
+			Select(df.Path(df.ColIdx(0), engine.StructFieldSegment(1)), df.ColIdx(1), df.ColIdx(2)),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_window",
+		Input: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Window(
+				[]engine.Expr{df.ColIdx(0)},
+				[]engine.SortField{df.Asc(df.ColIdx(4))},
+				[]*engine.WindowFunction{
+					df.Rank(),
+					df.Sum(df.ColIdx(2)).Over().WithBounds(engine.BoundsTypeRows, engine.UnboundedBound(), engine.CurrentRowBound()),
+				},
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Window(
+				[]engine.Expr{df.ColIdx(0)},
+				[]engine.SortField{df.Asc(df.ColIdx(4))},
+				[]*engine.WindowFunction{
+					df.Rank(),
+					df.Sum(df.ColIdx(2)).Over().WithBounds(engine.BoundsTypeRows, engine.UnboundedBound(), engine.CurrentRowBound()),
+				},
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_project_window_functions",
+		Input: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(
+				df.ColIdx(1),
+				df.Sum(df.ColIdx(2)).Over().
+					WithPartitions(df.ColIdx(0)).
+					WithSorts(df.Asc(df.ColIdx(4))).
+					WithBounds(engine.BoundsTypeRows, engine.PrecedingBound(2), engine.FollowingBound(1)),
+				df.RowNumber().WithSorts(df.Desc(df.ColIdx(2))),
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(
+				df.ColIdx(1),
+				df.Sum(df.ColIdx(2)).Over().
+					WithPartitions(df.ColIdx(0)).
+					WithSorts(df.Asc(df.ColIdx(4))).
+					WithBounds(engine.BoundsTypeRows, engine.PrecedingBound(2), engine.FollowingBound(1)),
+				df.RowNumber().WithSorts(df.Desc(df.ColIdx(2))),
+			),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_project_literals",
+		Input: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(testLiterals()...),
+		ExpectedOutput: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "table1"}, nil)).
+			Select(testLiterals()...),
+		Catalog: &testCatalog{},
+	},
+	{
+		Name: "read_project_nested_types",
+		Input: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "events"}, nil)).
+			Select(
+				df.ColIdx(0),
+				df.ColIdx(4),
+				df.ColIdx(8),
+				df.Path(df.ColIdx(13), engine.ListElementSegment(0)),
+				df.Path(df.ColIdx(15), engine.StructFieldSegment(1), engine.StructFieldSegment(0)),
+			),
+		ExpectedOutput: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"test_db", "main", "events"}, nil)).
+			Select(
+				df.ColIdx(0),
+				df.ColIdx(4),
+				df.ColIdx(8),
+				df.Path(df.ColIdx(13), engine.ListElementSegment(0)),
+				df.Path(df.ColIdx(15), engine.StructFieldSegment(1), engine.StructFieldSegment(0)),
+			),
+		Catalog: &testCatalog{},
+	},
+	// {
+	// 	Name: "read_project_plus_one_alias",
+	// 	Input: df.QueryContext().
+	// 		Read(
+	// 			engine.NewNamedTable(
+	// 				[]string{"test_db", "main", "table1"},
+	// 				nil,
+	// 			),
+	// 		).
+	// 		Select(
+	// 			df.As(
+	// 				df.Add(df.ColIdx(2), df.Lit(1)),
+	// 				"custom_name",
+	// 			),
+	// 		),
+	// 	ExpectedOutput: df.QueryContext().
+	// 		Read(
+	// 			engine.NewNamedTable(
+	// 				[]string{"test_db", "main", "table1"},
+	// 				nil,
+	// 			),
+	// 		).
+	// 		Select(
+	// 			df.As(
+	// 				df.Add(df.ColIdx(2), df.Lit(1)),
+	// 				"custom_name",
+	// 			),
+	// 		),
+	// 	Catalog: &testCatalog{},
+	// },
+}
+
+func testRecord() arrow.Record {
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+			{Name: "amount", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}, Nullable: true},
+			{Name: "active", Type: arrow.FixedWidthTypes.Boolean},
+		},
+		nil,
+	)
+
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer bldr.Release()
+
+	bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2, 3}, nil)
+	bldr.Field(1).(*array.StringBuilder).AppendValues([]string{"a", "", "c"}, []bool{true, false, true})
+	bldr.Field(2).(*array.Decimal128Builder).AppendValues(
+		[]decimal128.Num{decimal128.FromI64(1050), decimal128.FromI64(-25), {}},
+		[]bool{true, true, false},
+	)
+	bldr.Field(3).(*array.BooleanBuilder).AppendValues([]bool{true, false, true}, nil)
+
+	return bldr.NewRecord()
+}
+
+func TestJoinUsingRoundTrip(t *testing.T) {
+	for _, joinType := range []engine.JoinType{engine.JoinTypeInner, engine.JoinTypeLeft, engine.JoinTypeRight} {
+		t.Run(joinType.String(), func(t *testing.T) {
+			plan := engine.NewPlan(
+				df.QueryContext().
+					Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)).
+					JoinUsing(
+						df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "table3"}, nil)),
+						joinType,
+						"id",
+					).
+					Select(df.ColIdx(0), df.ColIdx(1), df.ColIdx(2)).
+					LogicalPlan(),
+			)
+			engine.SetCatalogForPlan(plan, &testCatalog{})
+
+			planProto, err := plan.ToProto()
+			require.NoError(t, err)
+
+			deserializedPlan, err := engine.FromProto(planProto)
+			require.NoError(t, err)
+
+			// The USING column has the type of the coalesce that is serialized
+			// for it, so the schema is unchanged by the round trip.
+			schema, err := plan.Relations()[0].Schema()
+			require.NoError(t, err)
+			deserializedSchema, err := deserializedPlan.Relations()[0].Schema()
+			require.NoError(t, err)
+			require.Equal(t, schema.String(), deserializedSchema.String())
+
+			roundTripped, err := deserializedPlan.ToProto()
+			require.NoError(t, err)
+
+			// The coalesced USING column is named after the function once
+			// deserialized, so the root gains a projection restoring its name.
+			expected := planProto.GetRelations()[0].GetRoot()
+			actual := roundTripped.GetRelations()[0].GetRoot()
+			require.Equal(t, expected.GetNames(), actual.GetNames())
+			require.True(
+				t,
+				protobuf.Equal(expected.GetInput(), actual.GetInput().GetProject().GetInput()),
+				"expected: %s\nfound: %s", expected.GetInput(), actual.GetInput(),
+			)
+		})
+	}
+}
+
+func TestJoinUsingQualifiedColumns(t *testing.T) {
+	join := df.QueryContext().
+		Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil)).
+		Alias("t1").
+		JoinUsing(
+			df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "table3"}, nil)).Alias("t2"),
+			engine.JoinTypeLeft,
+			"id",
+		)
+
+	for name, frame := range map[string]df.DataFrame{
+		"select": join.Select(
+			engine.NewQualifiedColumnExpr("t1", "id"),
+			engine.NewQualifiedColumnExpr("t2", "id"),
+			engine.NewQualifiedColumnExpr("t2", "label"),
+		),
+		"sort": join.
+			Sort(df.Asc(engine.NewQualifiedColumnExpr("t1", "id"))).
+			Select(engine.NewQualifiedColumnExpr("t2", "label")),
+	} {
+		t.Run(name, func(t *testing.T) {
+			plan := engine.NewPlan(frame.LogicalPlan())
+			engine.SetCatalogForPlan(plan, &testCatalog{})
+
+			_, err := plan.ToProto()
+			require.NoError(t, err)
+		})
+	}
+
+	_, err := join.Select(engine.NewQualifiedColumnExpr("t3", "id")).Schema()
+	require.ErrorContains(t, err, "input does not contain field: t3.id")
+}
+
+func TestVirtualTableRoundTrip(t *testing.T) {
+	rec := testRecord()
+	defer rec.Release()
+
+	actual := virtualTableRoundTrip(t, rec)
+	defer actual.Release()
+
+	require.Truef(t, array.RecordEqual(rec, actual), "expected: %s\nfound: %s", rec, actual)
+}
+
+func TestVirtualTableTypesRoundTrip(t *testing.T) {
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "time", Type: arrow.FixedWidthTypes.Time64us, Nullable: true},
+			{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Microsecond}, Nullable: true},
+			{Name: "ts_tz", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}, Nullable: true},
+			{Name: "bin", Type: arrow.BinaryTypes.Binary, Nullable: true},
+			{Name: "fixed_bin", Type: &arrow.FixedSizeBinaryType{ByteWidth: 2}, Nullable: true},
+			{Name: "months", Type: arrow.FixedWidthTypes.MonthInterval, Nullable: true},
+			{Name: "day_time", Type: arrow.FixedWidthTypes.DayTimeInterval, Nullable: true},
+			{Name: "month_day_nano", Type: arrow.FixedWidthTypes.MonthDayNanoInterval, Nullable: true},
+			{Name: "list", Type: arrow.ListOf(arrow.PrimitiveTypes.Int64), Nullable: true},
+			{Name: "struct", Type: arrow.StructOf(
+				arrow.Field{Name: "a", Type: arrow.PrimitiveTypes.Int32},
+				arrow.Field{Name: "b", Type: arrow.BinaryTypes.String, Nullable: true},
+			), Nullable: true},
+			{Name: "map", Type: arrow.MapOf(arrow.BinaryTypes.String, arrow.PrimitiveTypes.Int64), Nullable: true},
+		},
+		nil,
+	)
+
+	rec, _, err := array.RecordFromJSON(memory.DefaultAllocator, schema, strings.NewReader(`[
+		{
+			"time": "01:02:03.000001", "ts": "2023-11-14T22:13:20.123456", "ts_tz": "2023-11-14T22:13:20.123",
+			"bin": "AAEC", "fixed_bin": "AQI=", "months": {"months": 14},
+			"day_time": {"days": 1, "milliseconds": 1500},
+			"month_day_nano": {"months": 1, "days": 2, "nanoseconds": 3000000004},
+			"list": [1, null, 3], "struct": {"a": 1, "b": "x"}, "map": [{"key": "k", "value": 1}]
+		},
+		{
+			"time": "00:00:00", "ts": "1969-12-31T23:59:59.999999", "ts_tz": "1969-12-31T23:59:59.999", "bin": "", "fixed_bin": "AAA=", "months": {"months": -1},
+			"day_time": {"days": -1, "milliseconds": -1},
+			"month_day_nano": {"months": 0, "days": 0, "nanoseconds": -1},
+			"list": [], "struct": {"a": 2, "b": null}, "map": []
+		},
+		{
+			"time": null, "ts": null, "ts_tz": null, "bin": null, "fixed_bin": null, "months": null,
+			"day_time": null, "month_day_nano": null, "list": null, "struct": null, "map": null
+		}
+	]`))
+	require.NoError(t, err)
+	defer rec.Release()
+
+	actual := virtualTableRoundTrip(t, rec)
+	defer actual.Release()
+
+	require.Truef(t, array.RecordEqual(rec, actual), "expected: %s\nfound: %s", rec, actual)
+}
+
+func TestVirtualTableEncodedRoundTrip(t *testing.T) {
+	dictType := &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String}
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "dict", Type: dictType, Nullable: true},
+			{Name: "large", Type: arrow.BinaryTypes.LargeString, Nullable: true},
+			{Name: "large_list", Type: arrow.LargeListOf(arrow.PrimitiveTypes.Int64), Nullable: true},
+		},
+		nil,
+	)
+	rec, _, err := array.RecordFromJSON(memory.DefaultAllocator, schema, strings.NewReader(`[
+		{"dict": "a", "large": "x", "large_list": [1, 2]},
+		{"dict": null, "large": null, "large_list": null},
+		{"dict": "a", "large": "y", "large_list": []}
+	]`))
+	require.NoError(t, err)
+	defer rec.Release()
+
+	expectedSchema := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "dict", Type: arrow.BinaryTypes.String, Nullable: true},
+			{Name: "large", Type: arrow.BinaryTypes.String, Nullable: true},
+			{Name: "large_list", Type: arrow.ListOf(arrow.PrimitiveTypes.Int64), Nullable: true},
+		},
+		nil,
+	)
+	expected, _, err := array.RecordFromJSON(memory.DefaultAllocator, expectedSchema, strings.NewReader(`[
+		{"dict": "a", "large": "x", "large_list": [1, 2]},
+		{"dict": null, "large": null, "large_list": null},
+		{"dict": "a", "large": "y", "large_list": []}
+	]`))
+	require.NoError(t, err)
+	defer expected.Release()
+
+	actual := virtualTableRoundTrip(t, rec)
+	defer actual.Release()
+
+	require.Truef(t, array.RecordEqual(expected, actual), "expected: %s\nfound: %s", expected, actual)
+}
+
+func TestVirtualTableNullInRequiredColumn(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{{Name: "id", Type: arrow.PrimitiveTypes.Int64}}, nil)
+	rec, _, err := array.RecordFromJSON(memory.DefaultAllocator, schema, strings.NewReader(`[{"id": null}]`))
+	require.NoError(t, err)
+	defer rec.Release()
+
+	_, err = engine.NewVirtualTable(rec).ToProto(nil)
+	require.ErrorContains(t, err, "null value of non-nullable type")
+}
+
+// virtualTableRoundTrip serializes a plan reading rec from a virtual table
+// and returns the record read by the deserialized plan.
+func virtualTableRoundTrip(t *testing.T, rec arrow.Record) arrow.Record {
+	t.Helper()
+
+	plan := engine.NewPlan(df.QueryContext().Read(engine.NewVirtualTable(rec)).LogicalPlan())
+	planProto, err := plan.ToProto()
+	require.NoError(t, err)
+
+	deserializedPlan, err := engine.FromProto(planProto)
+	require.NoError(t, err)
+
+	read, ok := deserializedPlan.Relations()[0].(*engine.Read)
+	require.True(t, ok)
+
+	table, ok := read.Table().(interface{ Record() arrow.Record })
+	require.True(t, ok)
+
+	return table.Record()
+}
+
+var snapshotCodec = engine.NewProtoMessageCodec(&structpb.Struct{})
+
+func init() {
+	engine.DefaultExtensionTableCodecs.Register(snapshotCodec)
+}
+
+func testSnapshotRef() *structpb.Struct {
+	ref, err := structpb.NewStruct(map[string]any{
+		"table":       "warehouse.db.events",
+		"snapshot_id": "4871392058812371903",
+	})
+	if err != nil {
+		panic(err)
+	}
+	return ref
+}
+
+func TestLocalFilesTableMissingFormat(t *testing.T) {
+	table := engine.NewLocalFilesTable(
+		[]engine.FileItem{{Path: "/data/events.parquet", PathType: engine.FilePathTypeURIFile}},
+		nil,
+		nil,
+	)
+
+	_, err := table.ToProto(nil)
+	require.ErrorContains(t, err, "no file format")
+}
+
+func TestExtensionTableUnknownPayloadRoundTrip(t *testing.T) {
+	detail := &anypb.Any{
+		TypeUrl: "type.example.com/iceberg.SnapshotRef",
+		Value:   []byte{0x08, 0xbf, 0xc4, 0x9d, 0x8f, 0x9c, 0x9e, 0xd0, 0xcd, 0x43},
+	}
+
+	table, err := engine.NewExtensionTable(
+		detail.GetTypeUrl(),
+		detail,
+		bonobo.NewSchema([]bonobo.Field{{Name: "id", Type: bonobo.Types.Int64Type(false)}}),
+	)
+	require.NoError(t, err)
+
+	plan := engine.NewPlan(df.QueryContext().Read(table).LogicalPlan())
+	planProto, err := plan.ToProto()
+	require.NoError(t, err)
+
+	data, err := protobuf.Marshal(planProto)
+	require.NoError(t, err)
+
+	var unmarshalled proto.Plan
+	require.NoError(t, protobuf.Unmarshal(data, &unmarshalled))
+
+	deserializedPlan, err := engine.FromProto(&unmarshalled)
+	require.NoError(t, err)
+
+	roundTripped, err := deserializedPlan.ToProto()
+	require.NoError(t, err)
+
+	require.True(t, protobuf.Equal(planProto, roundTripped))
+	require.True(t, protobuf.Equal(detail, roundTripped.GetRelations()[0].GetRoot().GetInput().GetRead().GetExtensionTable().GetDetail()))
+}
+
+func TestExtensionTableMismatchedTypeURL(t *testing.T) {
+	detail := &anypb.Any{TypeUrl: "type.example.com/iceberg.SnapshotRef"}
+
+	_, err := engine.NewExtensionTable(snapshotCodec.TypeURL(), detail, nil)
+	require.ErrorContains(t, err, "has detail with type type.example.com/iceberg.SnapshotRef")
+}
+
+func mustExtensionTable(table *engine.ExtensionTable, err error) *engine.ExtensionTable {
+	if err != nil {
+		panic(err)
+	}
+	return table
+}
+
+func mustLiteral(lit *engine.Literal, err error) *engine.Literal {
+	if err != nil {
+		panic(err)
+	}
+	return lit
+}
+
+func mustAny(msg protobuf.Message) *anypb.Any {
+	value, err := anypb.New(msg)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// testLiterals has a literal of each kind that is supported.
+func testLiterals() []engine.Expr {
+	return []engine.Expr{
+		df.Null(bonobo.Types.DateType(false)),
+		df.Lit(int8(-8)),
+		df.Lit(float32(1.5)),
+		df.Lit(types.Date(19723)),
+		df.Lit(types.Time(45296789012)),
+		df.Lit(types.Timestamp(1704067200000000)),
+		df.Lit(types.TimestampTz(1704067200123456)),
+		df.Lit(&types.PrecisionTimestamp{
+			PrecisionTimestamp: &proto.Expression_Literal_PrecisionTimestamp{Precision: 9, Value: 1704067200123456789},
+		}),
+		df.Lit(&types.PrecisionTimestampTz{
+			PrecisionTimestampTz: &proto.Expression_Literal_PrecisionTimestamp{Precision: 3, Value: -1500},
+		}),
+		df.Lit(&types.IntervalYearToMonth{Years: 1, Months: 2}),
+		df.Lit(&types.IntervalDayToSecond{
+			Days:          3,
+			Seconds:       4,
+			Subseconds:    500,
+			PrecisionMode: &proto.Expression_Literal_IntervalDayToSecond_Precision{Precision: 3},
+		}),
+		df.Lit(&proto.Expression_Literal_IntervalCompound{
+			IntervalYearToMonth: &types.IntervalYearToMonth{Months: 6},
+			IntervalDayToSecond: &types.IntervalDayToSecond{
+				Days:          1,
+				PrecisionMode: &proto.Expression_Literal_IntervalDayToSecond_Precision{Precision: 6},
+			},
+		}),
+		mustLiteral(engine.NewDecimalLiteral(decimal128.FromI64(-123456), 10, 2)),
+		df.Lit([]byte{0xde, 0xad}),
+		df.Lit(types.FixedBinary{0x01, 0x02, 0x03}),
+		df.Lit(types.FixedChar("abc")),
+		df.Lit(&types.VarChar{Value: "hello", Length: 10}),
+		df.Lit(types.UUID{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}),
+		df.Lit(engine.ListValue{df.Lit(1), df.Null(bonobo.Types.Int64Type(false))}),
+		df.Lit(engine.StructValue{df.Lit("a"), df.Lit(true)}),
+		df.Lit(engine.MapValue{{Key: df.Lit("k"), Value: df.Lit(1.5)}}),
+		mustLiteral(engine.NewEmptyLiteral(&types.ListType{
+			Nullability: types.NullabilityRequired,
+			Type:        bonobo.Types.StringType(true),
+		})),
+		// The plan declares the point type with anchor 1, so the literals
+		// refer to it as 1 when they are deserialized.
+		df.Lit(&engine.UserDefinedValue{
+			TypeReference: 1,
+			URI:           "https://example.com/extension_types.yaml",
+			Name:          "point",
+			Struct:        engine.StructValue{df.Lit(int32(1)), df.Lit(int32(2))},
+		}),
+		df.Lit(&engine.UserDefinedValue{
+			TypeReference: 1,
+			URI:           "https://example.com/extension_types.yaml",
+			Name:          "point",
+			Value: mustAny(&structpb.ListValue{
+				Values: []*structpb.Value{structpb.NewNumberValue(3), structpb.NewNumberValue(4)},
+			}),
+		}),
+	}
+}
+
+func TestPrecisionTimestampLiteralString(t *testing.T) {
+	for value, expected := range map[int64]string{
+		86400123456789012: "1970-01-02 00:00:00.123456789::precisiontimestamp<12>",
+		-1:                "1969-12-31 23:59:59.999999999::precisiontimestamp<12>",
+	} {
+		lit := df.Lit(&types.PrecisionTimestamp{
+			PrecisionTimestamp: &proto.Expression_Literal_PrecisionTimestamp{Precision: 12, Value: value},
+		})
+		require.Equal(t, expected, lit.String())
+	}
+}
+
+func TestNewLiteralInvalid(t *testing.T) {
+	_, err := engine.NewLiteral(struct{}{})
+	require.ErrorContains(t, err, "invalid literal type: struct {}")
+
+	_, err = engine.NewLiteral(types.UUID{0x01})
+	require.ErrorContains(t, err, "uuid literal must be 16 bytes")
+
+	_, err = engine.NewLiteral(engine.ListValue{df.Lit(1), df.Lit("a")})
+	require.ErrorContains(t, err, "incompatible types")
+
+	_, err = engine.NewLiteral(&engine.UserDefinedValue{URI: "https://example.com/extension_types.yaml", Name: "point"})
+	require.ErrorContains(t, err, "user-defined literal of type point must have exactly one of a value or struct")
+
+	_, err = engine.NewDecimalLiteral(decimal128.FromI64(1000), 3, 0)
+	require.ErrorContains(t, err, "does not fit in precision 3")
+
+	_, err = engine.NewEmptyLiteral(bonobo.Types.Int64Type(false))
+	require.ErrorContains(t, err, "empty literal must be a list or map")
+
+	require.Panics(t, func() { engine.MustLiteral(struct{}{}) })
+}
+
+// TestIntervalDayNullability checks that combining nullable and required
+// interval_day types, whose WithNullability modifies the type in place, does
+// not change the types of the inputs.
+func TestIntervalDayNullability(t *testing.T) {
+	events := df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "events"}, nil))
+	engine.SetCatalogForRelation(events.LogicalPlan(), &testCatalog{})
+
+	null := df.Null(bonobo.Types.IntervalDayType(6, true))
+	ifThen := engine.NewIfThenExpr(
+		[]engine.IfClause{{If: df.Lit(true), Then: null}},
+		engine.MustLiteral(&types.IntervalDayToSecond{Days: 1}),
+	)
+	field, err := ifThen.Field(events.LogicalPlan())
+	require.NoError(t, err)
+	require.Equal(t, bonobo.Types.IntervalDayType(6, true), field.Type)
+
+	field, err = null.Field(events.LogicalPlan())
+	require.NoError(t, err)
+	require.Equal(t, bonobo.Types.IntervalDayType(6, true), field.Type)
+
+	primary := events.Select(df.Col("elapsed"))
+	secondary := events.Select(engine.NewAliasExpr(df.Null(bonobo.Types.IntervalDayType(3, true)), "elapsed"))
+	schema, err := primary.Union(secondary).Schema()
+	require.NoError(t, err)
+	require.Equal(t, bonobo.Types.IntervalDayType(3, true), schema.Fields()[0].Type)
+
+	for frame, expected := range map[df.DataFrame]bonobo.Type{
+		primary:   bonobo.Types.IntervalDayType(3, false),
+		secondary: bonobo.Types.IntervalDayType(3, true),
+	} {
+		schema, err := frame.Schema()
+		require.NoError(t, err)
+		require.Equal(t, expected, schema.Fields()[0].Type)
+	}
+}
+
+func TestColumnIndexOutOfRange(t *testing.T) {
+	read := df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "table2"}, nil))
+	engine.SetCatalogForRelation(read.LogicalPlan(), &testCatalog{})
+
+	for _, index := range []int{-1, 2} {
+		_, err := read.Select(df.ColIdx(index)).Schema()
+		require.ErrorContains(t, err, fmt.Sprintf("column index %d out of range for input with 2 fields", index))
+	}
+}
+
+func TestReadStringSchemaError(t *testing.T) {
+	plan := engine.NewPlan(
+		df.QueryContext().Read(engine.NewNamedTable([]string{"test_db", "main", "missing"}, nil)).LogicalPlan(),
+	)
+	engine.SetCatalogForPlan(plan, &testCatalog{})
+
+	require.Equal(
+		t,
+		"Read: schema=[error: table not found: test_db.main.missing], projection=None",
+		plan.Relations()[0].String(),
+	)
+}
+
+func TestSchemaFieldByPath(t *testing.T) {
+	schema := testEventsSchema()
+
+	field, err := schema.FieldByPath("source", "location", "lon")
+	require.NoError(t, err)
+	require.Equal(t, "lon", field.Name)
+	require.Equal(t, bonobo.Types.DoubleType(false), field.Type)
+
+	field, err = schema.FieldByPath("attributes")
+	require.NoError(t, err)
+	value, err := field.MapValue()
+	require.NoError(t, err)
+	require.Equal(t, bonobo.Types.Int64Type(true), value.Type)
+
+	location, err := schema.FieldByPath("source", "location")
+	require.NoError(t, err)
+	fields, err := location.StructFields()
+	require.NoError(t, err)
+	require.Len(t, fields, 2)
+	require.Equal(t, "lat", fields[0].Name)
+	require.Equal(t, location, bonobo.NewStructField("location", fields, true))
+
+	_, err = schema.FieldByPath("source", "missing")
+	require.ErrorContains(t, err, "missing")
+
+	_, err = schema.FieldByPath("tags", "element")
+	require.ErrorContains(t, err, "is not a struct")
+}
+
+func TestSchemaProtoRoundTrip(t *testing.T) {
+	schema := testEventsSchema()
+
+	schemaProto, err := schema.ToProto()
+	require.NoError(t, err)
+
+	roundTripped, err := bonobo.NewSchemaFromProto(schemaProto)
+	require.NoError(t, err)
+	require.Equal(t, schema.String(), roundTripped.String())
+	require.Equal(t, schema.Fields(), roundTripped.Fields())
+
+	schemaProto.Names = schemaProto.Names[1:]
+	_, err = bonobo.NewSchemaFromProto(schemaProto)
+	require.ErrorContains(t, err, "names")
 }
 
 func TestDataFrame(t *testing.T) {
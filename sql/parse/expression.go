@@ -63,16 +63,22 @@ func (s *SqlBinaryExpr) String() string {
 	return fmt.Sprintf("%s %s %s", s.Left, s.Op, s.Right)
 }
 
+// SqlFunctionExpr is a call to a function, such as sum(x). Over is set for
+// window functions, as in sum(x) OVER (ORDER BY y).
 type SqlFunctionExpr struct {
 	Name string
 	Args []SqlExpr
+	Over *SqlWindow
 }
 
 // Children implements SqlExpr.
 func (e *SqlFunctionExpr) Children() []SqlNode {
-	children := make([]SqlNode, len(e.Args))
-	for i, expr := range e.Args {
-		children[i] = expr
+	children := make([]SqlNode, 0, len(e.Args)+1)
+	for _, expr := range e.Args {
+		children = append(children, expr)
+	}
+	if e.Over != nil {
+		children = append(children, e.Over)
 	}
 	return children
 }
@@ -82,9 +88,344 @@ func (e *SqlFunctionExpr) String() string {
 	for i, arg := range e.Args {
 		args[i] = arg.String()
 	}
+	if e.Over != nil {
+		return fmt.Sprintf("%s(%s) OVER (%s)", e.Name, strings.Join(args, ", "), e.Over)
+	}
 	return fmt.Sprintf("%s(%s)", e.Name, strings.Join(args, ", "))
 }
 
+// SqlWindow is the OVER clause of a window function, which determines the
+// records that the function is computed over for each record. Frame is nil
+// unless ROWS or RANGE was specified.
+type SqlWindow struct {
+	PartitionBy []SqlExpr
+	OrderBy     []*SqlSortItem
+	Frame       *SqlWindowFrame
+}
+
+func (w *SqlWindow) Children() []SqlNode {
+	children := make([]SqlNode, 0, len(w.PartitionBy)+len(w.OrderBy))
+	for _, expr := range w.PartitionBy {
+		children = append(children, expr)
+	}
+	for _, item := range w.OrderBy {
+		children = append(children, item)
+	}
+	return children
+}
+
+func (w *SqlWindow) String() string {
+	var clauses []string
+	if len(w.PartitionBy) > 0 {
+		exprs := make([]string, len(w.PartitionBy))
+		for i, expr := range w.PartitionBy {
+			exprs[i] = expr.String()
+		}
+		clauses = append(clauses, "PARTITION BY "+strings.Join(exprs, ", "))
+	}
+	if len(w.OrderBy) > 0 {
+		items := make([]string, len(w.OrderBy))
+		for i, item := range w.OrderBy {
+			items[i] = item.String()
+		}
+		clauses = append(clauses, "ORDER BY "+strings.Join(items, ", "))
+	}
+	if w.Frame != nil {
+		clauses = append(clauses, w.Frame.String())
+	}
+	return strings.Join(clauses, " ")
+}
+
+// SqlWindowFrame limits the records of a window to those between two bounds
+// relative to the current record, as in ROWS BETWEEN 1 PRECEDING AND CURRENT
+// ROW. Units is either ROWS or RANGE, and End is nil if only the start of
+// the frame was specified.
+type SqlWindowFrame struct {
+	Units string
+	Start *SqlFrameBound
+	End   *SqlFrameBound
+}
+
+func (f *SqlWindowFrame) String() string {
+	if f.End == nil {
+		return fmt.Sprintf("%s %s", f.Units, f.Start)
+	}
+	return fmt.Sprintf("%s BETWEEN %s AND %s", f.Units, f.Start, f.End)
+}
+
+// SqlFrameBound is the start or end of a window frame. Kind is one of
+// PRECEDING, FOLLOWING, CURRENT ROW, UNBOUNDED PRECEDING or UNBOUNDED
+// FOLLOWING, and Offset is the distance from the current record for
+// PRECEDING and FOLLOWING.
+type SqlFrameBound struct {
+	Kind   string
+	Offset int
+}
+
+func (b *SqlFrameBound) String() string {
+	if b.Kind == "PRECEDING" || b.Kind == "FOLLOWING" {
+		return fmt.Sprintf("%d %s", b.Offset, b.Kind)
+	}
+	return b.Kind
+}
+
+// SqlCast converts an expression to a type, as in CAST(x AS DATE) or x::DATE.
+// Try is set for TRY_CAST, which results in null if the conversion fails.
+type SqlCast struct {
+	Expr SqlExpr
+	Type *SqlType
+	Try  bool
+}
+
+func (e *SqlCast) Children() []SqlNode {
+	return []SqlNode{e.Expr, e.Type}
+}
+
+func (e *SqlCast) String() string {
+	name := "CAST"
+	if e.Try {
+		name = "TRY_CAST"
+	}
+	return fmt.Sprintf("%s(%s AS %s)", name, e.Expr, e.Type)
+}
+
+// SqlCase is a CASE expression. Each When is evaluated in order and the
+// result of the first that is true is returned, or Else if none are. If
+// Operand is set, as in CASE x WHEN 1 THEN ..., the condition of each When
+// is a value compared to the operand.
+type SqlCase struct {
+	Operand SqlExpr
+	Whens   []*SqlWhen
+	Else    SqlExpr
+}
+
+func (e *SqlCase) Children() []SqlNode {
+	children := make([]SqlNode, 0, len(e.Whens)+2)
+	if e.Operand != nil {
+		children = append(children, e.Operand)
+	}
+	for _, when := range e.Whens {
+		children = append(children, when)
+	}
+	if e.Else != nil {
+		children = append(children, e.Else)
+	}
+	return children
+}
+
+func (e *SqlCase) String() string {
+	var bldr strings.Builder
+	bldr.WriteString("CASE")
+	if e.Operand != nil {
+		fmt.Fprintf(&bldr, " %s", e.Operand)
+	}
+	for _, when := range e.Whens {
+		fmt.Fprintf(&bldr, " %s", when)
+	}
+	if e.Else != nil {
+		fmt.Fprintf(&bldr, " ELSE %s", e.Else)
+	}
+	bldr.WriteString(" END")
+	return bldr.String()
+}
+
+// SqlWhen is a WHEN ... THEN ... clause of a CASE expression.
+type SqlWhen struct {
+	Cond   SqlExpr
+	Result SqlExpr
+}
+
+func (e *SqlWhen) Children() []SqlNode {
+	return []SqlNode{e.Cond, e.Result}
+}
+
+func (e *SqlWhen) String() string {
+	return fmt.Sprintf("WHEN %s THEN %s", e.Cond, e.Result)
+}
+
+// SqlInList tests whether an expression is equal to any in a list, as in
+// x IN (1, 2, 3), or to any record of Query, as in x IN (SELECT ...). Not is
+// set for NOT IN. The expression and the items of the list are SqlTuples
+// when comparing several values at once, as in (x, y) IN ((1, 2), (3, 4)).
+type SqlInList struct {
+	Expr  SqlExpr
+	List  []SqlExpr
+	Query *SqlQuery
+	Not   bool
+}
+
+func (e *SqlInList) Children() []SqlNode {
+	children := make([]SqlNode, 0, len(e.List)+2)
+	children = append(children, e.Expr)
+	for _, item := range e.List {
+		children = append(children, item)
+	}
+	if e.Query != nil {
+		children = append(children, e.Query)
+	}
+	return children
+}
+
+func (e *SqlInList) String() string {
+	op := "IN"
+	if e.Not {
+		op = "NOT IN"
+	}
+	if e.Query != nil {
+		return fmt.Sprintf("%s %s (%s)", e.Expr, op, e.Query)
+	}
+	return fmt.Sprintf("%s %s %s", e.Expr, op, &SqlTuple{Exprs: e.List})
+}
+
+// SqlExists tests whether a subquery outputs any records. Not is set for
+// NOT EXISTS.
+type SqlExists struct {
+	Query *SqlQuery
+	Not   bool
+}
+
+func (e *SqlExists) Children() []SqlNode {
+	return []SqlNode{e.Query}
+}
+
+func (e *SqlExists) String() string {
+	if e.Not {
+		return fmt.Sprintf("NOT EXISTS (%s)", e.Query)
+	}
+	return fmt.Sprintf("EXISTS (%s)", e.Query)
+}
+
+// SqlSetComparison compares an expression to each record of a subquery, as
+// in x > ANY (SELECT ...). Quantifier is either ANY or ALL, and SOME is
+// parsed as ANY.
+type SqlSetComparison struct {
+	Left       SqlExpr
+	Op         string
+	Quantifier string
+	Query      *SqlQuery
+}
+
+func (e *SqlSetComparison) Children() []SqlNode {
+	return []SqlNode{e.Left, e.Query}
+}
+
+func (e *SqlSetComparison) String() string {
+	return fmt.Sprintf("%s %s %s (%s)", e.Left, e.Op, e.Quantifier, e.Query)
+}
+
+// SqlTuple is a parenthesized list of expressions, such as (1, 2).
+type SqlTuple struct {
+	Exprs []SqlExpr
+}
+
+func (e *SqlTuple) Children() []SqlNode {
+	children := make([]SqlNode, len(e.Exprs))
+	for i, expr := range e.Exprs {
+		children[i] = expr
+	}
+	return children
+}
+
+func (e *SqlTuple) String() string {
+	exprs := make([]string, len(e.Exprs))
+	for i, expr := range e.Exprs {
+		exprs[i] = expr.String()
+	}
+	return fmt.Sprintf("(%s)", strings.Join(exprs, ", "))
+}
+
+// SqlSubscript selects an element of an array or the value of a key in a
+// map, as in a[1] or m['key'].
+type SqlSubscript struct {
+	Expr  SqlExpr
+	Index SqlExpr
+}
+
+func (e *SqlSubscript) Children() []SqlNode {
+	return []SqlNode{e.Expr, e.Index}
+}
+
+func (e *SqlSubscript) String() string {
+	return fmt.Sprintf("%s[%s]", e.Expr, e.Index)
+}
+
+// SqlFieldAccess selects a field of a struct by name, as in STRUCT(a, b).a.
+// The fields of a column are parsed as part of its identifier instead.
+type SqlFieldAccess struct {
+	Expr SqlExpr
+	Name string
+}
+
+func (e *SqlFieldAccess) Children() []SqlNode {
+	return []SqlNode{e.Expr}
+}
+
+func (e *SqlFieldAccess) String() string {
+	return fmt.Sprintf("%s.%s", e.Expr, e.Name)
+}
+
+// SqlArray is a list of expressions in brackets, such as [1, 2, 3].
+type SqlArray struct {
+	Elems []SqlExpr
+}
+
+func (e *SqlArray) Children() []SqlNode {
+	children := make([]SqlNode, len(e.Elems))
+	for i, elem := range e.Elems {
+		children[i] = elem
+	}
+	return children
+}
+
+func (e *SqlArray) String() string {
+	elems := make([]string, len(e.Elems))
+	for i, elem := range e.Elems {
+		elems[i] = elem.String()
+	}
+	return fmt.Sprintf("[%s]", strings.Join(elems, ", "))
+}
+
+// SqlStruct builds a struct with a field for each expression, as in
+// STRUCT(1, 'a') or ROW(1, 'a').
+type SqlStruct struct {
+	Fields []SqlExpr
+}
+
+func (e *SqlStruct) Children() []SqlNode {
+	children := make([]SqlNode, len(e.Fields))
+	for i, field := range e.Fields {
+		children[i] = field
+	}
+	return children
+}
+
+func (e *SqlStruct) String() string {
+	return fmt.Sprintf("STRUCT%s", &SqlTuple{Exprs: e.Fields})
+}
+
+// SqlMap builds a map from alternating keys and values, as in
+// MAP('a', 1, 'b', 2). Keys and Values have the same length.
+type SqlMap struct {
+	Keys   []SqlExpr
+	Values []SqlExpr
+}
+
+func (e *SqlMap) Children() []SqlNode {
+	children := make([]SqlNode, 0, 2*len(e.Keys))
+	for i := range e.Keys {
+		children = append(children, e.Keys[i], e.Values[i])
+	}
+	return children
+}
+
+func (e *SqlMap) String() string {
+	entries := make([]string, len(e.Keys))
+	for i := range e.Keys {
+		entries[i] = fmt.Sprintf("%s, %s", e.Keys[i], e.Values[i])
+	}
+	return fmt.Sprintf("MAP(%s)", strings.Join(entries, ", "))
+}
+
 type SqlAlias struct {
 	Name  string
 	Input SqlExpr
@@ -98,9 +439,81 @@ func (e *SqlAlias) String() string {
 	return fmt.Sprintf("%s AS %s", e.Input.String(), e.Name)
 }
 
+// SqlSortItem is a single expression in an ORDER BY clause. Direction is
+// either ASC or DESC and Nulls is either FIRST or LAST, and each is empty
+// if it was not specified.
+type SqlSortItem struct {
+	Expr      SqlExpr
+	Direction string
+	Nulls     string
+}
+
+func (e *SqlSortItem) Children() []SqlNode {
+	return []SqlNode{e.Expr}
+}
+
+func (e *SqlSortItem) String() string {
+	s := e.Expr.String()
+	if e.Direction != "" {
+		s += " " + e.Direction
+	}
+	if e.Nulls != "" {
+		s += " NULLS " + e.Nulls
+	}
+	return s
+}
+
+// SqlType is the name of a data type along with its parameters, such as the
+// precision and scale of DECIMAL(38, 8).
+type SqlType struct {
+	Name   string
+	Params []int
+}
+
+func (*SqlType) Children() []SqlNode {
+	return nil
+}
+
+func (t *SqlType) String() string {
+	if len(t.Params) == 0 {
+		return t.Name
+	}
+
+	params := make([]string, len(t.Params))
+	for i, param := range t.Params {
+		params[i] = fmt.Sprint(param)
+	}
+	return fmt.Sprintf("%s(%s)", t.Name, strings.Join(params, ", "))
+}
+
+// SqlColumnDef is the definition of a column in CREATE TABLE.
+type SqlColumnDef struct {
+	Name    string
+	Type    *SqlType
+	NotNull bool
+}
+
+func (c *SqlColumnDef) Children() []SqlNode {
+	return []SqlNode{c.Type}
+}
+
+func (c *SqlColumnDef) String() string {
+	s := fmt.Sprintf("%s %s", c.Name, c.Type)
+	if c.NotNull {
+		s += " NOT NULL"
+	}
+	return s
+}
+
 var _ SqlExpr = (*SqlIdentifier)(nil)
 var _ SqlExpr = (*SqlStringLiteral)(nil)
 var _ SqlExpr = (*SqlIntLiteral)(nil)
 var _ SqlExpr = (*SqlBinaryExpr)(nil)
 var _ SqlExpr = (*SqlFunctionExpr)(nil)
+var _ SqlExpr = (*SqlCast)(nil)
+var _ SqlExpr = (*SqlCase)(nil)
+var _ SqlExpr = (*SqlWhen)(nil)
 var _ SqlExpr = (*SqlAlias)(nil)
+var _ SqlExpr = (*SqlSortItem)(nil)
+var _ SqlExpr = (*SqlType)(nil)
+var _ SqlExpr = (*SqlColumnDef)(nil)
@@ -3,7 +3,9 @@ package parse
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/joellubi/bonobo/sql/token"
 )
@@ -29,30 +31,167 @@ func Parse(tokens token.TokenStream) (*SqlQuery, error) {
 	var (
 		block SqlExpr
 		bldr  SqlQueryBuilder
-		err   error
+
+		operands  []*SqlQuery
+		operators []SqlSetOperation
 	)
 
+	query := func() *SqlQuery {
+		return combineSetOperands(append(operands, bldr.Query()), operators)
+	}
+
 	parser := NewExprParser(tokens)
 	for {
+		op, found, err := parseSetOperator(tokens)
+		if err != nil {
+			return query(), err
+		}
+		if found {
+			operands = append(operands, bldr.Query())
+			operators = append(operators, op)
+			continue
+		}
+
 		block, err = parser.Parse(token.HighestPrec)
 		if err == ErrEndOfTokenStream {
-			return bldr.Query(), nil
+			operands = append(operands, bldr.Query())
+			for _, operand := range operands {
+				if err := checkStatement(operand); err != nil {
+					return combineSetOperands(operands, operators), err
+				}
+			}
+
+			combined := combineSetOperands(operands, operators)
+			return combined, checkStatement(combined)
 		}
 		if err != nil {
-			return bldr.Query(), err
+			return query(), err
 		}
 
 		switch b := block.(type) {
 		case *sqlSelectRelation:
-			bldr.Select(b)
+			err = bldr.Select(b)
 		case *sqlFromRelation:
-			bldr.From(b)
+			err = bldr.From(b)
 		case *sqlWhereRelation:
-			bldr.Where(b)
+			err = bldr.Where(b)
+		case *sqlOrderByRelation:
+			err = bldr.OrderBy(b)
+		case *sqlLimitRelation:
+			err = bldr.Limit(b)
+		case *sqlOffsetRelation:
+			err = bldr.Offset(b)
+		case *sqlInsertRelation:
+			err = bldr.Insert(b)
+		case *sqlCreateRelation:
+			err = bldr.Create(b)
+		case *sqlDropRelation:
+			err = bldr.Drop(b)
 		default:
 			return nil, fmt.Errorf("parse: expected valid sql relation, found %[1]T: %[1]s", b)
 		}
+		if err != nil {
+			return query(), err
+		}
+	}
+}
+
+// parseSetOperator consumes a set operator between two queries, such as
+// UNION ALL, if one is next in the token stream.
+func parseSetOperator(tokens token.TokenStream) (SqlSetOperation, bool, error) {
+	tok, more := tokens.Peek()
+	if !more {
+		return SqlSetOperation{}, false, nil
+	}
+
+	switch tok.Name {
+	case token.UNION, token.INTERSECT, token.EXCEPT:
+	default:
+		return SqlSetOperation{}, false, nil
+	}
+	tokens.Next()
+
+	op := SqlSetOperation{Op: tok.Name.String()}
+	if next, more := tokens.Peek(); more {
+		switch next.Name {
+		case token.ALL:
+			tokens.Next()
+			op.All = true
+		case token.DISTINCT:
+			tokens.Next()
+		}
+	}
+
+	if next, more := tokens.Peek(); !more || next.Name != token.SELECT && next.Name != token.FROM {
+		return op, false, fmt.Errorf("parse: expected query to follow %s, found %s", op.Op, next.String())
+	}
+
+	return op, true, nil
+}
+
+// combineSetOperands nests queries separated by set operators into a single
+// query. INTERSECT binds more tightly than UNION and EXCEPT, and operators of
+// equal precedence are evaluated from left to right. Any ORDER BY, LIMIT or
+// OFFSET following the last query applies to the combined result.
+func combineSetOperands(operands []*SqlQuery, operators []SqlSetOperation) *SqlQuery {
+	if len(operators) == 0 {
+		return operands[0]
+	}
+
+	last := operands[len(operands)-1]
+	orderBy, limit, offset := last.OrderBy, last.Limit, last.Offset
+	last.OrderBy, last.Limit, last.Offset = nil, nil, nil
+
+	// Any INSERT or CREATE preceding the first query writes the combined result,
+	// and any DROP is kept with it so that the combination is rejected
+	first := operands[0]
+	insert, create, drop := first.Insert, first.Create, first.Drop
+	first.Insert, first.Create, first.Drop = nil, nil, nil
+
+	combine := func(op SqlSetOperation, left, right *SqlQuery) *SqlQuery {
+		op.Left, op.Right = left, right
+		return &SqlQuery{Set: &op}
+	}
+
+	queries := []*SqlQuery{operands[0]}
+	var ops []SqlSetOperation
+	for i, op := range operators {
+		if op.Op == token.INTERSECT.String() {
+			queries[len(queries)-1] = combine(op, queries[len(queries)-1], operands[i+1])
+			continue
+		}
+		queries = append(queries, operands[i+1])
+		ops = append(ops, op)
+	}
+
+	query := queries[0]
+	for i, op := range ops {
+		query = combine(op, query, queries[i+1])
+	}
+
+	query.OrderBy, query.Limit, query.Offset = orderBy, limit, offset
+	query.Insert, query.Create, query.Drop = insert, create, drop
+	return query
+}
+
+// checkStatement returns an error if a DROP, or a CREATE with a column list,
+// is combined with the clauses of a query, whose result it would discard.
+func checkStatement(query *SqlQuery) error {
+	var statement string
+	switch {
+	case query.Drop != nil:
+		statement = "DROP"
+	case query.Create != nil && query.Create.Columns != nil:
+		statement = "CREATE with a column list"
+	default:
+		return nil
+	}
+
+	if query.Set != nil || query.Read != nil || query.Projection != nil || query.Filter != nil ||
+		query.OrderBy != nil || query.Limit != nil || query.Offset != nil {
+		return fmt.Errorf("parse: %s cannot be combined with SELECT, FROM, WHERE, ORDER BY, LIMIT or OFFSET", statement)
 	}
+	return nil
 }
 
 func NewExprParser(tokens token.TokenStream) PrattParser {
@@ -62,15 +201,30 @@ func NewExprParser(tokens token.TokenStream) PrattParser {
 type exprParser struct {
 	tokens token.TokenStream
 	depth  int
+
+	// castDepth is the number of CAST expressions being parsed. Identifiers
+	// within a CAST are not aliased, since AS precedes the target type.
+	castDepth int
 }
 
 // Parse implements Parser.
 func (p *exprParser) Parse(precedence int) (SqlExpr, error) {
+	depth := p.depth
 	if err := p.consumeLeftParens(); err != nil {
 		return nil, err
 	}
 
-	expr, err := p.ParsePrefix()
+	var (
+		expr SqlExpr
+		err  error
+	)
+	if p.depth > depth && p.nextIsQuery() {
+		// The innermost parenthesis encloses a subquery rather than an expression
+		p.depth--
+		expr, err = p.parseNestedQuery()
+	} else {
+		expr, err = p.ParsePrefix()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -112,8 +266,42 @@ func (p *exprParser) ParsePrefix() (SqlExpr, error) {
 		return p.parseFrom()
 	case token.WHERE:
 		return p.parseWhere()
+	case token.ORDER:
+		return p.parseOrderBy()
+	case token.LIMIT:
+		return p.parseLimit()
+	case token.OFFSET:
+		return p.parseOffset()
+	case token.INSERT:
+		return p.parseInsert()
+	case token.CREATE:
+		return p.parseCreate()
+	case token.DROP:
+		return p.parseDrop()
+	case token.CAST, token.TRY_CAST:
+		return p.parseCast(tok.Name == token.TRY_CAST)
+	case token.CASE:
+		return p.parseCase()
+	case token.EXISTS:
+		return p.parseExists(false)
+	case token.NOT:
+		if _, err := p.expectToken(token.EXISTS); err != nil {
+			return nil, fmt.Errorf("expected EXISTS to follow NOT: %w", err)
+		}
+		return p.parseExists(true)
+	case token.LBRACK:
+		return p.parseArray()
+	case token.STRUCT, token.ROW:
+		return p.parseStruct(tok.Name)
+	case token.MAP:
+		return p.parseMap()
 	case token.IDENT:
+		if next, more := p.tokens.Peek(); more && next.Name == token.LPAREN {
+			return p.parseFunction(tok.Val)
+		}
 		return p.parseIdentifier(tok.Val)
+	case token.STRING:
+		return &SqlStringLiteral{Value: tok.Val}, nil
 	case token.INT:
 		val, err := strconv.Atoi(tok.Val)
 		if err != nil {
@@ -131,7 +319,49 @@ func (p *exprParser) ParseInfix(left SqlExpr, precedence int) (SqlExpr, error) {
 	if !more {
 		return nil, ErrEndOfTokenStream
 	}
-	if !tok.IsOperator() {
+
+	switch tok.Name {
+	case token.DCOLON:
+		typ, err := p.parseType()
+		if err != nil {
+			return nil, fmt.Errorf("expected type to follow %s: %w", tok.Name, err)
+		}
+		return &SqlCast{Expr: left, Type: typ}, nil
+	case token.IN:
+		return p.parseInList(left, false)
+	case token.NOT:
+		if _, err := p.expectToken(token.IN); err != nil {
+			return nil, fmt.Errorf("expected IN to follow NOT: %w", err)
+		}
+		return p.parseInList(left, true)
+	case token.LBRACK:
+		return p.parseSubscript(left)
+	case token.PERIOD:
+		name, err := p.expectToken(token.IDENT)
+		if err != nil {
+			return nil, fmt.Errorf("expected field name to follow .: %w", err)
+		}
+		return &SqlFieldAccess{Expr: left, Name: name.Val}, nil
+	case token.COMMA:
+		// Only reached within parentheses, where a comma separates the
+		// expressions of a tuple
+		return p.parseTuple(left)
+	}
+
+	switch tok.Name {
+	case token.EQL, token.NEQ, token.LSS, token.GTR, token.LEQ, token.GEQ:
+		if quantifier, found := p.tryParseQuantifier(); found {
+			return p.parseSetComparison(left, tok.Val, quantifier)
+		}
+	}
+
+	op := tok.Val
+	switch {
+	case tok.IsOperator():
+	case tok.Name == token.AND, tok.Name == token.OR:
+		// Keywords are case-insensitive, so use the canonical name
+		op = tok.Name.String()
+	default:
 		return nil, fmt.Errorf("parse: unexpected token: expected operator, found: %s", tok.String())
 	}
 
@@ -142,7 +372,7 @@ func (p *exprParser) ParseInfix(left SqlExpr, precedence int) (SqlExpr, error) {
 
 	return &SqlBinaryExpr{
 		Left:  left,
-		Op:    tok.Val,
+		Op:    op,
 		Right: right,
 	}, nil
 }
@@ -188,14 +418,127 @@ func (p *exprParser) parseSelect() (*sqlSelectRelation, error) {
 }
 
 func (p *exprParser) parseFrom() (*sqlFromRelation, error) {
-	table, err := p.parseTableExpr()
+	table, err := p.parseJoinedTableExpr()
 	if err != nil {
 		return nil, err
 	}
 
+	// A comma-separated list of tables is the cross product of all of them
+	for {
+		if _, err := p.expectToken(token.COMMA); err != nil {
+			break
+		}
+
+		right, err := p.parseJoinedTableExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		table = &SqlJoin{Left: table, Right: right, Type: "CROSS"}
+	}
+
 	return SqlFromRelation(table), nil
 }
 
+func (p *exprParser) parseJoinedTableExpr() (SqlExpr, error) {
+	table, err := p.parseTableExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		joinType, found, err := p.tryParseJoinType()
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return table, nil
+		}
+
+		right, err := p.parseTableExpr()
+		if err != nil {
+			return nil, fmt.Errorf("expected table to follow JOIN: %w", err)
+		}
+
+		join := SqlJoin{Left: table, Right: right, Type: joinType}
+		if joinType != "CROSS" {
+			if err := p.parseJoinCriteria(&join); err != nil {
+				return nil, err
+			}
+		}
+
+		table = &join
+	}
+}
+
+// tryParseJoinType consumes the keywords preceding a table in a join, such as
+// LEFT OUTER JOIN, and returns the type of join they describe.
+func (p *exprParser) tryParseJoinType() (string, bool, error) {
+	tok, more := p.tokens.Peek()
+	if !more {
+		return "", false, nil
+	}
+
+	var joinType string
+	switch tok.Name {
+	case token.JOIN:
+		p.tokens.Next()
+		return "INNER", true, nil
+	case token.INNER, token.CROSS:
+		p.tokens.Next()
+		joinType = tok.Name.String()
+	case token.LEFT, token.RIGHT, token.FULL:
+		p.tokens.Next()
+		joinType = tok.Name.String()
+		p.expectToken(token.OUTER)
+	default:
+		return "", false, nil
+	}
+
+	if _, err := p.expectToken(token.JOIN); err != nil {
+		return "", false, fmt.Errorf("expected JOIN after %s: %w", joinType, err)
+	}
+
+	return joinType, true, nil
+}
+
+func (p *exprParser) parseJoinCriteria(join *SqlJoin) error {
+	if _, err := p.expectToken(token.ON); err == nil {
+		expr, err := p.parseUnaliasedExpr()
+		if err != nil {
+			return fmt.Errorf("expected expression to follow ON: %w", err)
+		}
+		join.On = expr
+		return nil
+	}
+
+	if _, err := p.expectToken(token.USING); err != nil {
+		return fmt.Errorf("expected ON or USING to follow %s JOIN: %w", join.Type, err)
+	}
+
+	if _, err := p.expectToken(token.LPAREN); err != nil {
+		return fmt.Errorf("expected column list to follow USING: %w", err)
+	}
+
+	for {
+		tok, err := p.expectToken(token.IDENT)
+		if err != nil {
+			return fmt.Errorf("expected column name in USING: %w", err)
+		}
+		join.Using = append(join.Using, tok.Val)
+
+		if _, err := p.expectToken(token.COMMA); err != nil {
+			break
+		}
+	}
+
+	if _, err := p.expectToken(token.RPAREN); err != nil {
+		return fmt.Errorf("expected USING column list to be closed: %w", err)
+	}
+
+	return nil
+}
+
 func (p *exprParser) parseTableExpr() (SqlExpr, error) {
 	tok, more := p.tokens.Peek()
 	if !more {
@@ -217,16 +560,8 @@ func (p *exprParser) parseSubquery() (*SqlQuery, error) {
 		return nil, err
 	}
 
-	subquery, err := Parse(p.tokens)
-	if err == nil {
-		return nil, fmt.Errorf("parse: subquery was not closed")
-	}
-
-	if !errors.Is(err, ErrUnexpectedCloseParen) {
-		return nil, err
-	}
-
-	if _, err := p.expectToken(token.RPAREN); err != nil {
+	subquery, err := p.parseNestedQuery()
+	if err != nil {
 		return nil, err
 	}
 
@@ -242,6 +577,31 @@ func (p *exprParser) parseSubquery() (*SqlQuery, error) {
 	return subquery, nil
 }
 
+// parseNestedQuery parses a query through the parenthesis that closes it,
+// which follows the opening parenthesis.
+func (p *exprParser) parseNestedQuery() (*SqlQuery, error) {
+	query, err := Parse(p.tokens)
+	if err == nil {
+		return nil, fmt.Errorf("parse: subquery was not closed")
+	}
+
+	if !errors.Is(err, ErrUnexpectedCloseParen) {
+		return nil, err
+	}
+
+	if _, err := p.expectToken(token.RPAREN); err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+// nextIsQuery reports whether the next token starts a query.
+func (p *exprParser) nextIsQuery() bool {
+	tok, more := p.tokens.Peek()
+	return more && (tok.Name == token.SELECT || tok.Name == token.FROM)
+}
+
 func (p *exprParser) parseWhere() (*sqlWhereRelation, error) {
 	expr, err := p.parseExpr()
 	if err != nil {
@@ -250,77 +610,103 @@ func (p *exprParser) parseWhere() (*sqlWhereRelation, error) {
 	return SqlWhereRelation(expr), nil
 }
 
-func (p *exprParser) parseIdentifier(names ...string) (SqlExpr, error) {
-	var err error
+func (p *exprParser) parseOrderBy() (*sqlOrderByRelation, error) {
+	if _, err := p.expectToken(token.BY); err != nil {
+		return nil, err
+	}
 
-	if len(names) > 0 {
-		// Partially-consumed identifier; already at end or period delimits next part
-		_, err = p.expectToken(token.PERIOD)
+	items, err := p.parseSortItems()
+	if err != nil {
+		return nil, err
 	}
 
-	if err == nil {
-		for {
-			tok, err := p.expectToken(token.IDENT)
-			if err != nil {
-				return nil, err
-			}
-			names = append(names, tok.Val)
+	return SqlOrderByRelation(items), nil
+}
 
-			_, err = p.expectToken(token.PERIOD)
-			if err != nil {
-				break
-			}
+// parseSortItems parses the comma-separated items following ORDER BY.
+func (p *exprParser) parseSortItems() ([]*SqlSortItem, error) {
+	items := make([]*SqlSortItem, 0)
+	for {
+		item, err := p.parseSortItem()
+		if err != nil {
+			return nil, fmt.Errorf("expected at least one expression in ORDER BY: %w", err)
+		}
+		items = append(items, item)
+
+		if _, err := p.expectToken(token.COMMA); err != nil {
+			break
 		}
 	}
 
-	identifier := SqlIdentifier{Names: names}
+	return items, nil
+}
 
-	alias, found, err := p.tryParseAlias()
+func (p *exprParser) parseSortItem() (*SqlSortItem, error) {
+	expr, err := p.parseUnaliasedExpr()
 	if err != nil {
 		return nil, err
 	}
 
-	if found {
-		identifier.Alias = alias
+	item := SqlSortItem{Expr: expr}
+
+	tok, more := p.tokens.Peek()
+	if more && (tok.Name == token.ASC || tok.Name == token.DESC) {
+		p.tokens.Next()
+		item.Direction = tok.Name.String()
 	}
 
-	return &identifier, nil
+	if _, err := p.expectToken(token.NULLS); err == nil {
+		// FIRST and LAST are not reserved, so they are lexed as identifiers
+		tok, err := p.expectToken(token.IDENT)
+		if err != nil {
+			return nil, fmt.Errorf("expected FIRST or LAST after NULLS: %w", err)
+		}
+
+		switch nulls := strings.ToUpper(tok.Val); nulls {
+		case "FIRST", "LAST":
+			item.Nulls = nulls
+		default:
+			return nil, fmt.Errorf("parse: expected FIRST or LAST after NULLS, found %s", tok.String())
+		}
+	}
+
+	return &item, nil
 }
 
-func (p *exprParser) parseExprList() ([]SqlExpr, error) {
-	exprs := make([]SqlExpr, 0)
-	expr, err := p.parseExpr()
+func (p *exprParser) parseLimit() (*sqlLimitRelation, error) {
+	count, err := p.parseNonNegativeInt()
 	if err != nil {
-		return nil, fmt.Errorf("expected at least one expression in SELECT: %w", err)
+		return nil, fmt.Errorf("expected row count to follow LIMIT: %w", err)
 	}
-	exprs = append(exprs, expr)
+	return SqlLimitRelation(count), nil
+}
 
-	for tok, more := p.tokens.Peek(); more && tok.Name == token.COMMA; tok, more = p.tokens.Peek() {
-		p.tokens.Next()
+func (p *exprParser) parseOffset() (*sqlOffsetRelation, error) {
+	offset, err := p.parseNonNegativeInt()
+	if err != nil {
+		return nil, fmt.Errorf("expected row count to follow OFFSET: %w", err)
+	}
+	return SqlOffsetRelation(offset), nil
+}
 
-		expr, err = p.parseExpr()
-		if err == ErrEndOfTokenStream {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-		exprs = append(exprs, expr)
+func (p *exprParser) parseNonNegativeInt() (int, error) {
+	tok, err := p.expectToken(token.INT)
+	if err != nil {
+		return 0, err
 	}
 
-	return exprs, nil
+	return strconv.Atoi(tok.Val)
 }
 
-func (p *exprParser) parseExpr() (SqlExpr, error) {
-	depthStart := p.depth
-
-	expr, err := p.Parse(token.LowestPrec)
+func (p *exprParser) parseIdentifier(names ...string) (SqlExpr, error) {
+	names, err := p.parseNames(names...)
 	if err != nil {
 		return nil, err
 	}
 
-	if p.depth != depthStart {
-		return nil, fmt.Errorf("parse: invalid expression, unmatched parentheses")
+	identifier := SqlIdentifier{Names: names}
+	if p.castDepth > 0 {
+		return &identifier, nil
 	}
 
 	alias, found, err := p.tryParseAlias()
@@ -329,7 +715,761 @@ func (p *exprParser) parseExpr() (SqlExpr, error) {
 	}
 
 	if found {
-		expr = &SqlAlias{Name: alias, Input: expr}
+		identifier.Alias = alias
+	}
+
+	return &identifier, nil
+}
+
+// parseNames parses the period-delimited parts of an identifier, such as
+// schema.table, that has not been aliased.
+func (p *exprParser) parseNames(names ...string) ([]string, error) {
+	var err error
+
+	if len(names) > 0 {
+		// Partially-consumed identifier; already at end or period delimits next part
+		_, err = p.expectToken(token.PERIOD)
+	}
+
+	if err == nil {
+		for {
+			tok, err := p.expectToken(token.IDENT)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, tok.Val)
+
+			_, err = p.expectToken(token.PERIOD)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// parseCast parses the parenthesized expression and target type following
+// CAST or TRY_CAST.
+func (p *exprParser) parseCast(try bool) (*SqlCast, error) {
+	name := token.CAST
+	if try {
+		name = token.TRY_CAST
+	}
+
+	if _, err := p.expectToken(token.LPAREN); err != nil {
+		return nil, fmt.Errorf("expected ( to follow %s: %w", name, err)
+	}
+
+	p.castDepth++
+	expr, err := p.parseNestedExpr()
+	p.castDepth--
+	if err != nil {
+		return nil, fmt.Errorf("expected expression to follow %s: %w", name, err)
+	}
+
+	if _, err := p.expectToken(token.AS); err != nil {
+		return nil, fmt.Errorf("expected AS to follow expression in %s: %w", name, err)
+	}
+
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, fmt.Errorf("expected type to follow AS in %s: %w", name, err)
+	}
+
+	if _, err := p.expectToken(token.RPAREN); err != nil {
+		return nil, fmt.Errorf("expected %s to be closed: %w", name, err)
+	}
+
+	return &SqlCast{Expr: expr, Type: typ, Try: try}, nil
+}
+
+// parseCase parses the clauses of a CASE expression through the closing END.
+func (p *exprParser) parseCase() (*SqlCase, error) {
+	var expr SqlCase
+	if tok, more := p.tokens.Peek(); more && tok.Name != token.WHEN {
+		operand, err := p.parseNestedExpr()
+		if err != nil {
+			return nil, fmt.Errorf("expected WHEN or operand to follow CASE: %w", err)
+		}
+		expr.Operand = operand
+	}
+
+	for {
+		if _, err := p.expectToken(token.WHEN); err != nil {
+			break
+		}
+
+		cond, err := p.parseNestedExpr()
+		if err != nil {
+			return nil, fmt.Errorf("expected condition to follow WHEN: %w", err)
+		}
+
+		if _, err := p.expectToken(token.THEN); err != nil {
+			return nil, fmt.Errorf("expected THEN to follow WHEN condition: %w", err)
+		}
+
+		result, err := p.parseNestedExpr()
+		if err != nil {
+			return nil, fmt.Errorf("expected result to follow THEN: %w", err)
+		}
+
+		expr.Whens = append(expr.Whens, &SqlWhen{Cond: cond, Result: result})
+	}
+
+	if len(expr.Whens) == 0 {
+		tok, _ := p.tokens.Peek()
+		return nil, fmt.Errorf("parse: expected WHEN to follow CASE, found %s", tok.String())
+	}
+
+	if _, err := p.expectToken(token.ELSE); err == nil {
+		result, err := p.parseNestedExpr()
+		if err != nil {
+			return nil, fmt.Errorf("expected result to follow ELSE: %w", err)
+		}
+		expr.Else = result
+	}
+
+	if _, err := p.expectToken(token.END); err != nil {
+		return nil, fmt.Errorf("expected END to close CASE: %w", err)
+	}
+
+	return &expr, nil
+}
+
+// parseInList parses the parenthesized list following IN or NOT IN.
+func (p *exprParser) parseInList(expr SqlExpr, not bool) (*SqlInList, error) {
+	if _, err := p.expectToken(token.LPAREN); err != nil {
+		return nil, fmt.Errorf("expected ( to follow IN: %w", err)
+	}
+
+	list := SqlInList{Expr: expr, Not: not}
+	if p.nextIsQuery() {
+		query, err := p.parseNestedQuery()
+		if err != nil {
+			return nil, err
+		}
+		list.Query = query
+		return &list, nil
+	}
+
+	for {
+		item, err := p.parseNestedExpr()
+		if err != nil {
+			return nil, fmt.Errorf("expected expression in IN list: %w", err)
+		}
+		list.List = append(list.List, item)
+
+		if _, err := p.expectToken(token.COMMA); err != nil {
+			break
+		}
+	}
+
+	if _, err := p.expectToken(token.RPAREN); err != nil {
+		return nil, fmt.Errorf("expected IN list to be closed: %w", err)
+	}
+
+	return &list, nil
+}
+
+// parseExists parses the parenthesized subquery following EXISTS or
+// NOT EXISTS.
+func (p *exprParser) parseExists(not bool) (*SqlExists, error) {
+	if _, err := p.expectToken(token.LPAREN); err != nil {
+		return nil, fmt.Errorf("expected ( to follow EXISTS: %w", err)
+	}
+
+	if !p.nextIsQuery() {
+		tok, _ := p.tokens.Peek()
+		return nil, fmt.Errorf("parse: expected subquery to follow EXISTS, found %s", tok.String())
+	}
+
+	query, err := p.parseNestedQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SqlExists{Query: query, Not: not}, nil
+}
+
+// tryParseQuantifier consumes ANY, SOME or ALL following a comparison
+// operator if it is next in the token stream.
+func (p *exprParser) tryParseQuantifier() (string, bool) {
+	tok, more := p.tokens.Peek()
+	if !more {
+		return "", false
+	}
+
+	switch tok.Name {
+	case token.ANY, token.SOME:
+		p.tokens.Next()
+		return token.ANY.String(), true
+	case token.ALL:
+		p.tokens.Next()
+		return token.ALL.String(), true
+	default:
+		return "", false
+	}
+}
+
+// parseSetComparison parses the parenthesized subquery following a
+// comparison operator and ANY or ALL.
+func (p *exprParser) parseSetComparison(left SqlExpr, op, quantifier string) (*SqlSetComparison, error) {
+	if _, err := p.expectToken(token.LPAREN); err != nil {
+		return nil, fmt.Errorf("expected ( to follow %s: %w", quantifier, err)
+	}
+
+	if !p.nextIsQuery() {
+		tok, _ := p.tokens.Peek()
+		return nil, fmt.Errorf("parse: expected subquery to follow %s, found %s", quantifier, tok.String())
+	}
+
+	query, err := p.parseNestedQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SqlSetComparison{Left: left, Op: op, Quantifier: quantifier, Query: query}, nil
+}
+
+// parseTuple parses the expressions following the first in a parenthesized
+// list, through the closing parenthesis.
+func (p *exprParser) parseTuple(first SqlExpr) (*SqlTuple, error) {
+	tuple := SqlTuple{Exprs: []SqlExpr{first}}
+	for {
+		expr, err := p.parseNestedExpr()
+		if err != nil {
+			return nil, fmt.Errorf("expected expression in list: %w", err)
+		}
+		tuple.Exprs = append(tuple.Exprs, expr)
+
+		if _, err := p.expectToken(token.COMMA); err != nil {
+			break
+		}
+	}
+
+	if _, err := p.expectToken(token.RPAREN); err != nil {
+		return nil, fmt.Errorf("expected list to be closed: %w", err)
+	}
+	p.depth--
+
+	if err := p.consumeRightParens(); err != nil {
+		return nil, err
+	}
+
+	return &tuple, nil
+}
+
+// parseSubscript parses the index following the opening bracket of a
+// subscript through the closing bracket.
+func (p *exprParser) parseSubscript(expr SqlExpr) (*SqlSubscript, error) {
+	index, err := p.parseNestedExpr()
+	if err != nil {
+		return nil, fmt.Errorf("expected subscript to follow [: %w", err)
+	}
+
+	if _, err := p.expectToken(token.RBRACK); err != nil {
+		return nil, fmt.Errorf("expected subscript to be closed: %w", err)
+	}
+
+	return &SqlSubscript{Expr: expr, Index: index}, nil
+}
+
+// parseArray parses the elements of an array through the closing bracket.
+func (p *exprParser) parseArray() (*SqlArray, error) {
+	elems, err := p.parseDelimitedExprs(token.RBRACK)
+	if err != nil {
+		return nil, fmt.Errorf("expected array to be closed: %w", err)
+	}
+
+	return &SqlArray{Elems: elems}, nil
+}
+
+// parseStruct parses the parenthesized fields following STRUCT or ROW.
+func (p *exprParser) parseStruct(name token.TokenName) (*SqlStruct, error) {
+	if _, err := p.expectToken(token.LPAREN); err != nil {
+		return nil, fmt.Errorf("expected ( to follow %s: %w", name, err)
+	}
+
+	fields, err := p.parseDelimitedExprs(token.RPAREN)
+	if err != nil {
+		return nil, fmt.Errorf("expected %s to be closed: %w", name, err)
+	}
+
+	return &SqlStruct{Fields: fields}, nil
+}
+
+// parseMap parses the parenthesized keys and values following MAP.
+func (p *exprParser) parseMap() (*SqlMap, error) {
+	if _, err := p.expectToken(token.LPAREN); err != nil {
+		return nil, fmt.Errorf("expected ( to follow MAP: %w", err)
+	}
+
+	exprs, err := p.parseDelimitedExprs(token.RPAREN)
+	if err != nil {
+		return nil, fmt.Errorf("expected MAP to be closed: %w", err)
+	}
+
+	if len(exprs)%2 != 0 {
+		return nil, fmt.Errorf("parse: expected MAP to have a value for each key, found %d expressions", len(exprs))
+	}
+
+	var m SqlMap
+	for i := 0; i < len(exprs); i += 2 {
+		m.Keys = append(m.Keys, exprs[i])
+		m.Values = append(m.Values, exprs[i+1])
+	}
+
+	return &m, nil
+}
+
+// parseDelimitedExprs parses a possibly empty list of expressions separated by
+// commas, through the closing token.
+func (p *exprParser) parseDelimitedExprs(close token.TokenName) ([]SqlExpr, error) {
+	var exprs []SqlExpr
+	if _, err := p.expectToken(close); err == nil {
+		return exprs, nil
+	}
+
+	for {
+		expr, err := p.parseNestedExpr()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+
+		if _, err := p.expectToken(token.COMMA); err != nil {
+			break
+		}
+	}
+
+	if _, err := p.expectToken(close); err != nil {
+		return nil, err
+	}
+
+	return exprs, nil
+}
+
+// parseFunction parses the parenthesized arguments following the name of a
+// function, along with the OVER clause of a window function.
+func (p *exprParser) parseFunction(name string) (*SqlFunctionExpr, error) {
+	if _, err := p.expectToken(token.LPAREN); err != nil {
+		return nil, err
+	}
+
+	args, err := p.parseDelimitedExprs(token.RPAREN)
+	if err != nil {
+		return nil, fmt.Errorf("expected arguments of %s to be closed: %w", name, err)
+	}
+
+	fn := SqlFunctionExpr{Name: name, Args: args}
+	if _, err := p.expectToken(token.OVER); err == nil {
+		fn.Over, err = p.parseWindow()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &fn, nil
+}
+
+// parseWindow parses the parenthesized window specification following OVER.
+func (p *exprParser) parseWindow() (*SqlWindow, error) {
+	if _, err := p.expectToken(token.LPAREN); err != nil {
+		return nil, fmt.Errorf("expected ( to follow OVER: %w", err)
+	}
+
+	// The closing parenthesis ends the window rather than an expression within it
+	depth := p.depth
+	p.depth = 0
+	defer func() { p.depth = depth }()
+
+	var window SqlWindow
+	if _, err := p.expectToken(token.PARTITION); err == nil {
+		if _, err := p.expectToken(token.BY); err != nil {
+			return nil, err
+		}
+
+		for {
+			expr, err := p.parseUnaliasedExpr()
+			if err != nil {
+				return nil, fmt.Errorf("expected at least one expression in PARTITION BY: %w", err)
+			}
+			window.PartitionBy = append(window.PartitionBy, expr)
+
+			if _, err := p.expectToken(token.COMMA); err != nil {
+				break
+			}
+		}
+	}
+
+	if _, err := p.expectToken(token.ORDER); err == nil {
+		if _, err := p.expectToken(token.BY); err != nil {
+			return nil, err
+		}
+
+		window.OrderBy, err = p.parseSortItems()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if tok, more := p.tokens.Peek(); more && (tok.Name == token.ROWS || tok.Name == token.RANGE) {
+		p.tokens.Next()
+
+		var err error
+		window.Frame, err = p.parseWindowFrame(tok.Name.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.expectToken(token.RPAREN); err != nil {
+		return nil, fmt.Errorf("expected window to be closed: %w", err)
+	}
+
+	return &window, nil
+}
+
+// parseWindowFrame parses the bounds of a frame following ROWS or RANGE.
+func (p *exprParser) parseWindowFrame(units string) (*SqlWindowFrame, error) {
+	frame := SqlWindowFrame{Units: units}
+
+	_, err := p.expectToken(token.BETWEEN)
+	between := err == nil
+
+	frame.Start, err = p.parseFrameBound()
+	if err != nil {
+		return nil, fmt.Errorf("expected frame bound to follow %s: %w", units, err)
+	}
+
+	if between {
+		if _, err := p.expectToken(token.AND); err != nil {
+			return nil, fmt.Errorf("expected AND to follow start of frame: %w", err)
+		}
+
+		frame.End, err = p.parseFrameBound()
+		if err != nil {
+			return nil, fmt.Errorf("expected frame bound to follow AND: %w", err)
+		}
+	}
+
+	if err := checkWindowFrame(&frame); err != nil {
+		return nil, err
+	}
+
+	return &frame, nil
+}
+
+// checkWindowFrame returns an error if the frame starts after it ends. A
+// frame without an end extends to the current row.
+func checkWindowFrame(frame *SqlWindowFrame) error {
+	end := frame.End
+	if end == nil {
+		end = &SqlFrameBound{Kind: "CURRENT ROW"}
+	}
+
+	if frame.Start.Kind == "UNBOUNDED FOLLOWING" {
+		return fmt.Errorf("parse: window frame cannot start at UNBOUNDED FOLLOWING")
+	}
+	if end.Kind == "UNBOUNDED PRECEDING" {
+		return fmt.Errorf("parse: window frame cannot end at UNBOUNDED PRECEDING")
+	}
+	if framePosition(frame.Start) > framePosition(end) {
+		return fmt.Errorf("parse: window frame cannot start at %s and end at %s", frame.Start, end)
+	}
+
+	return nil
+}
+
+// framePosition is the offset of bound from the current row, which is
+// negative for preceding records.
+func framePosition(bound *SqlFrameBound) int {
+	switch bound.Kind {
+	case "UNBOUNDED PRECEDING":
+		return math.MinInt
+	case "PRECEDING":
+		return -bound.Offset
+	case "FOLLOWING":
+		return bound.Offset
+	case "UNBOUNDED FOLLOWING":
+		return math.MaxInt
+	default:
+		return 0
+	}
+}
+
+// parseFrameBound parses UNBOUNDED PRECEDING, UNBOUNDED FOLLOWING, CURRENT
+// ROW, or an offset followed by PRECEDING or FOLLOWING.
+func (p *exprParser) parseFrameBound() (*SqlFrameBound, error) {
+	tok, more := p.tokens.Next()
+	if !more {
+		return nil, ErrEndOfTokenStream
+	}
+
+	var (
+		bound     SqlFrameBound
+		unbounded bool
+	)
+	switch {
+	case tok.Name == token.CURRENT:
+		if _, err := p.expectToken(token.ROW); err != nil {
+			return nil, fmt.Errorf("expected ROW to follow CURRENT: %w", err)
+		}
+		bound.Kind = "CURRENT ROW"
+		return &bound, nil
+	case tok.Name == token.INT:
+		offset, err := strconv.Atoi(tok.Val)
+		if err != nil {
+			return nil, err
+		}
+		bound.Offset = offset
+	case tok.Name == token.IDENT && strings.ToUpper(tok.Val) == "UNBOUNDED":
+		// UNBOUNDED is not reserved, so it is lexed as an identifier
+		unbounded = true
+	default:
+		return nil, fmt.Errorf("parse: unexpected token in window frame: %s", tok.String())
+	}
+
+	// PRECEDING and FOLLOWING are not reserved, so they are lexed as identifiers
+	dir, err := p.expectToken(token.IDENT)
+	if err != nil {
+		return nil, fmt.Errorf("expected PRECEDING or FOLLOWING: %w", err)
+	}
+
+	switch direction := strings.ToUpper(dir.Val); direction {
+	case "PRECEDING", "FOLLOWING":
+		bound.Kind = direction
+		if unbounded {
+			bound.Kind = "UNBOUNDED " + direction
+		}
+	default:
+		return nil, fmt.Errorf("parse: expected PRECEDING or FOLLOWING, found %s", dir.String())
+	}
+
+	return &bound, nil
+}
+
+// parseNestedExpr parses an expression embedded in a larger one, such as
+// the operand of CAST. Parentheses must be balanced within the expression.
+func (p *exprParser) parseNestedExpr() (SqlExpr, error) {
+	depth := p.depth
+	p.depth = 0
+	defer func() { p.depth = depth }()
+
+	return p.parseUnaliasedExpr()
+}
+
+func (p *exprParser) parseInsert() (*sqlInsertRelation, error) {
+	if _, err := p.expectToken(token.INTO); err != nil {
+		return nil, err
+	}
+
+	table, err := p.parseNames()
+	if err != nil {
+		return nil, fmt.Errorf("expected table name to follow INSERT INTO: %w", err)
+	}
+
+	return SqlInsertRelation(table), nil
+}
+
+func (p *exprParser) parseCreate() (*sqlCreateRelation, error) {
+	var orReplace bool
+	if _, err := p.expectToken(token.OR); err == nil {
+		if _, err := p.expectToken(token.REPLACE); err != nil {
+			return nil, err
+		}
+		orReplace = true
+	}
+
+	object, err := p.parseObjectKind()
+	if err != nil {
+		return nil, fmt.Errorf("expected TABLE or VIEW to follow CREATE: %w", err)
+	}
+
+	name, err := p.parseNames()
+	if err != nil {
+		return nil, fmt.Errorf("expected name to follow CREATE %s: %w", object, err)
+	}
+
+	// The query following AS is parsed as the rest of the statement
+	if _, err := p.expectToken(token.AS); err == nil {
+		return SqlCreateRelation(object, name, orReplace, nil), nil
+	}
+
+	if object != token.TABLE.String() {
+		return nil, fmt.Errorf("parse: expected AS to follow CREATE %s", object)
+	}
+
+	columns, err := p.parseColumnDefs()
+	if err != nil {
+		return nil, err
+	}
+
+	return SqlCreateRelation(object, name, orReplace, columns), nil
+}
+
+func (p *exprParser) parseDrop() (*sqlDropRelation, error) {
+	object, err := p.parseObjectKind()
+	if err != nil {
+		return nil, fmt.Errorf("expected TABLE or VIEW to follow DROP: %w", err)
+	}
+
+	var ifExists bool
+	if _, err := p.expectToken(token.IF); err == nil {
+		if _, err := p.expectToken(token.EXISTS); err != nil {
+			return nil, err
+		}
+		ifExists = true
+	}
+
+	name, err := p.parseNames()
+	if err != nil {
+		return nil, fmt.Errorf("expected name to follow DROP %s: %w", object, err)
+	}
+
+	return SqlDropRelation(object, name, ifExists), nil
+}
+
+func (p *exprParser) parseObjectKind() (string, error) {
+	tok, more := p.tokens.Peek()
+	if !more {
+		return "", ErrEndOfTokenStream
+	}
+
+	switch tok.Name {
+	case token.TABLE, token.VIEW:
+		p.tokens.Next()
+		return tok.Name.String(), nil
+	default:
+		return "", fmt.Errorf("parse: unexpected token: %s", tok.String())
+	}
+}
+
+func (p *exprParser) parseColumnDefs() ([]*SqlColumnDef, error) {
+	if _, err := p.expectToken(token.LPAREN); err != nil {
+		return nil, fmt.Errorf("expected AS or column definitions to follow CREATE TABLE: %w", err)
+	}
+
+	columns := make([]*SqlColumnDef, 0)
+	for {
+		tok, err := p.expectToken(token.IDENT)
+		if err != nil {
+			return nil, fmt.Errorf("expected column name: %w", err)
+		}
+
+		typ, err := p.parseType()
+		if err != nil {
+			return nil, fmt.Errorf("expected type of column %s: %w", tok.Val, err)
+		}
+
+		column := SqlColumnDef{Name: tok.Val, Type: typ}
+		if _, err := p.expectToken(token.NOT); err == nil {
+			if _, err := p.expectToken(token.NULL); err != nil {
+				return nil, err
+			}
+			column.NotNull = true
+		} else {
+			p.expectToken(token.NULL)
+		}
+		columns = append(columns, &column)
+
+		if _, err := p.expectToken(token.COMMA); err != nil {
+			break
+		}
+	}
+
+	if _, err := p.expectToken(token.RPAREN); err != nil {
+		return nil, fmt.Errorf("expected column definitions to be closed: %w", err)
+	}
+
+	return columns, nil
+}
+
+// parseType parses the name of a type along with any parameters enclosed in
+// parentheses. Type names are not reserved, so they are lexed as identifiers.
+func (p *exprParser) parseType() (*SqlType, error) {
+	tok, err := p.expectToken(token.IDENT)
+	if err != nil {
+		return nil, err
+	}
+
+	typ := SqlType{Name: strings.ToUpper(tok.Val)}
+	if _, err := p.expectToken(token.LPAREN); err != nil {
+		return &typ, nil
+	}
+
+	for {
+		param, err := p.parseNonNegativeInt()
+		if err != nil {
+			return nil, fmt.Errorf("expected integer parameter of type %s: %w", typ.Name, err)
+		}
+		typ.Params = append(typ.Params, param)
+
+		if _, err := p.expectToken(token.COMMA); err != nil {
+			break
+		}
+	}
+
+	if _, err := p.expectToken(token.RPAREN); err != nil {
+		return nil, fmt.Errorf("expected parameters of type %s to be closed: %w", typ.Name, err)
+	}
+
+	return &typ, nil
+}
+
+func (p *exprParser) parseExprList() ([]SqlExpr, error) {
+	exprs := make([]SqlExpr, 0)
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, fmt.Errorf("expected at least one expression in SELECT: %w", err)
+	}
+	exprs = append(exprs, expr)
+
+	for tok, more := p.tokens.Peek(); more && tok.Name == token.COMMA; tok, more = p.tokens.Peek() {
+		p.tokens.Next()
+
+		expr, err = p.parseExpr()
+		if err == ErrEndOfTokenStream {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	return exprs, nil
+}
+
+func (p *exprParser) parseExpr() (SqlExpr, error) {
+	expr, err := p.parseUnaliasedExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	alias, found, err := p.tryParseAlias()
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		expr = &SqlAlias{Name: alias, Input: expr}
+	}
+
+	return expr, nil
+}
+
+func (p *exprParser) parseUnaliasedExpr() (SqlExpr, error) {
+	depthStart := p.depth
+
+	expr, err := p.Parse(token.LowestPrec)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.depth != depthStart {
+		return nil, fmt.Errorf("parse: invalid expression, unmatched parentheses")
 	}
 
 	return expr, nil
@@ -441,6 +441,1474 @@ var testcases = []struct {
 		},
 		Error: true,
 	},
+	{
+		Name: "SELECT a FROM b LIMIT 10 OFFSET 5",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.LIMIT, Val: "LIMIT"},
+			{Name: token.INT, Val: "10"},
+			{Name: token.OFFSET, Val: "OFFSET"},
+			{Name: token.INT, Val: "5"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation(
+				[]parse.SqlExpr{
+					&parse.SqlIdentifier{Names: []string{"a"}},
+				},
+			),
+			Read:   parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+			Limit:  parse.SqlLimitRelation(10),
+			Offset: parse.SqlOffsetRelation(5),
+		},
+	},
+	{
+		Name: "SELECT a FROM b LIMIT c",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.LIMIT, Val: "LIMIT"},
+			{Name: token.IDENT, Val: "c"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT a FROM b LIMIT 1 LIMIT 2",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.LIMIT, Val: "LIMIT"},
+			{Name: token.INT, Val: "1"},
+			{Name: token.LIMIT, Val: "LIMIT"},
+			{Name: token.INT, Val: "2"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT a FROM b OFFSET 1 OFFSET 2",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.OFFSET, Val: "OFFSET"},
+			{Name: token.INT, Val: "1"},
+			{Name: token.OFFSET, Val: "OFFSET"},
+			{Name: token.INT, Val: "2"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT a, b c FROM d ORDER BY 1 DESC, c NULLS FIRST, a + 1",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "d"},
+			{Name: token.ORDER, Val: "ORDER"},
+			{Name: token.BY, Val: "BY"},
+			{Name: token.INT, Val: "1"},
+			{Name: token.DESC, Val: "DESC"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.NULLS, Val: "NULLS"},
+			{Name: token.IDENT, Val: "FIRST"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.ADD, Val: "+"},
+			{Name: token.INT, Val: "1"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation(
+				[]parse.SqlExpr{
+					&parse.SqlIdentifier{Names: []string{"a"}},
+					&parse.SqlIdentifier{Names: []string{"b"}, Alias: "c"},
+				},
+			),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+			OrderBy: parse.SqlOrderByRelation(
+				[]*parse.SqlSortItem{
+					{Expr: &parse.SqlIntLiteral{Value: 1}, Direction: "DESC"},
+					{Expr: &parse.SqlIdentifier{Names: []string{"c"}}, Nulls: "FIRST"},
+					{
+						Expr: &parse.SqlBinaryExpr{
+							Left:  &parse.SqlIdentifier{Names: []string{"a"}},
+							Op:    "+",
+							Right: &parse.SqlIntLiteral{Value: 1},
+						},
+					},
+				},
+			),
+		},
+	},
+	{
+		Name: "SELECT a FROM b ORDER BY a ORDER BY b",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.ORDER, Val: "ORDER"},
+			{Name: token.BY, Val: "BY"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.ORDER, Val: "ORDER"},
+			{Name: token.BY, Val: "BY"},
+			{Name: token.IDENT, Val: "b"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT a FROM b ORDER BY a NULLS",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.ORDER, Val: "ORDER"},
+			{Name: token.BY, Val: "BY"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.NULLS, Val: "NULLS"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT t.a, u.b FROM x.t JOIN u ON t.id = u.id AND t.c",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "t"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.IDENT, Val: "u"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "x"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.IDENT, Val: "t"},
+			{Name: token.JOIN, Val: "JOIN"},
+			{Name: token.IDENT, Val: "u"},
+			{Name: token.ON, Val: "ON"},
+			{Name: token.IDENT, Val: "t"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.IDENT, Val: "id"},
+			{Name: token.EQL, Val: "="},
+			{Name: token.IDENT, Val: "u"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.IDENT, Val: "id"},
+			{Name: token.AND, Val: "and"},
+			{Name: token.IDENT, Val: "t"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.IDENT, Val: "c"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation(
+				[]parse.SqlExpr{
+					&parse.SqlIdentifier{Names: []string{"t", "a"}},
+					&parse.SqlIdentifier{Names: []string{"u", "b"}},
+				},
+			),
+			Read: parse.SqlFromRelation(
+				&parse.SqlJoin{
+					Left:  &parse.SqlIdentifier{Names: []string{"x", "t"}},
+					Right: &parse.SqlIdentifier{Names: []string{"u"}},
+					Type:  "INNER",
+					On: &parse.SqlBinaryExpr{
+						Left: &parse.SqlBinaryExpr{
+							Left:  &parse.SqlIdentifier{Names: []string{"t", "id"}},
+							Op:    "=",
+							Right: &parse.SqlIdentifier{Names: []string{"u", "id"}},
+						},
+						Op:    "AND",
+						Right: &parse.SqlIdentifier{Names: []string{"t", "c"}},
+					},
+				},
+			),
+		},
+	},
+	{
+		Name: "SELECT a FROM b x LEFT OUTER JOIN c y USING (d, e) FULL JOIN f ON g CROSS JOIN h",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.IDENT, Val: "x"},
+			{Name: token.LEFT, Val: "LEFT"},
+			{Name: token.OUTER, Val: "OUTER"},
+			{Name: token.JOIN, Val: "JOIN"},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.IDENT, Val: "y"},
+			{Name: token.USING, Val: "USING"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "d"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.IDENT, Val: "e"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.FULL, Val: "FULL"},
+			{Name: token.JOIN, Val: "JOIN"},
+			{Name: token.IDENT, Val: "f"},
+			{Name: token.ON, Val: "ON"},
+			{Name: token.IDENT, Val: "g"},
+			{Name: token.CROSS, Val: "CROSS"},
+			{Name: token.JOIN, Val: "JOIN"},
+			{Name: token.IDENT, Val: "h"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation(
+				[]parse.SqlExpr{
+					&parse.SqlIdentifier{Names: []string{"a"}},
+				},
+			),
+			Read: parse.SqlFromRelation(
+				&parse.SqlJoin{
+					Left: &parse.SqlJoin{
+						Left: &parse.SqlJoin{
+							Left:  &parse.SqlIdentifier{Names: []string{"b"}, Alias: "x"},
+							Right: &parse.SqlIdentifier{Names: []string{"c"}, Alias: "y"},
+							Type:  "LEFT",
+							Using: []string{"d", "e"},
+						},
+						Right: &parse.SqlIdentifier{Names: []string{"f"}},
+						Type:  "FULL",
+						On:    &parse.SqlIdentifier{Names: []string{"g"}},
+					},
+					Right: &parse.SqlIdentifier{Names: []string{"h"}},
+					Type:  "CROSS",
+				},
+			),
+		},
+	},
+	{
+		Name: "SELECT a FROM b, (SELECT c FROM d) e WHERE a",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "d"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.IDENT, Val: "e"},
+			{Name: token.WHERE, Val: "WHERE"},
+			{Name: token.IDENT, Val: "a"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation(
+				[]parse.SqlExpr{
+					&parse.SqlIdentifier{Names: []string{"a"}},
+				},
+			),
+			Read: parse.SqlFromRelation(
+				&parse.SqlJoin{
+					Left: &parse.SqlIdentifier{Names: []string{"b"}},
+					Right: &parse.SqlQuery{
+						Alias: "e",
+						Projection: parse.SqlSelectRelation(
+							[]parse.SqlExpr{
+								&parse.SqlIdentifier{Names: []string{"c"}},
+							},
+						),
+						Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+					},
+					Type: "CROSS",
+				},
+			),
+			Filter: parse.SqlWhereRelation(&parse.SqlIdentifier{Names: []string{"a"}}),
+		},
+	},
+	{
+		Name: "SELECT a FROM b JOIN c",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.JOIN, Val: "JOIN"},
+			{Name: token.IDENT, Val: "c"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT a FROM b UNION ALL SELECT a FROM c INTERSECT SELECT a FROM d ORDER BY a LIMIT 1",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.UNION, Val: "UNION"},
+			{Name: token.ALL, Val: "ALL"},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.INTERSECT, Val: "INTERSECT"},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "d"},
+			{Name: token.ORDER, Val: "ORDER"},
+			{Name: token.BY, Val: "BY"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.LIMIT, Val: "LIMIT"},
+			{Name: token.INT, Val: "1"},
+		},
+		Expected: &parse.SqlQuery{
+			Set: &parse.SqlSetOperation{
+				Op:  "UNION",
+				All: true,
+				Left: &parse.SqlQuery{
+					Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}),
+					Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+				},
+				Right: &parse.SqlQuery{
+					Set: &parse.SqlSetOperation{
+						Op: "INTERSECT",
+						Left: &parse.SqlQuery{
+							Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}),
+							Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"c"}}),
+						},
+						Right: &parse.SqlQuery{
+							Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}),
+							Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+						},
+					},
+				},
+			},
+			OrderBy: parse.SqlOrderByRelation(
+				[]*parse.SqlSortItem{
+					{Expr: &parse.SqlIdentifier{Names: []string{"a"}}},
+				},
+			),
+			Limit: parse.SqlLimitRelation(1),
+		},
+	},
+	{
+		Name: "SELECT a FROM b EXCEPT DISTINCT SELECT a FROM c EXCEPT SELECT a FROM d",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.EXCEPT, Val: "EXCEPT"},
+			{Name: token.DISTINCT, Val: "DISTINCT"},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.EXCEPT, Val: "EXCEPT"},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "d"},
+		},
+		Expected: &parse.SqlQuery{
+			Set: &parse.SqlSetOperation{
+				Op: "EXCEPT",
+				Left: &parse.SqlQuery{
+					Set: &parse.SqlSetOperation{
+						Op: "EXCEPT",
+						Left: &parse.SqlQuery{
+							Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}),
+							Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+						},
+						Right: &parse.SqlQuery{
+							Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}),
+							Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"c"}}),
+						},
+					},
+				},
+				Right: &parse.SqlQuery{
+					Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}),
+					Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+				},
+			},
+		},
+	},
+	{
+		Name: "SELECT a UNION",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.UNION, Val: "UNION"},
+		},
+		Error: true,
+	},
+	{
+		Name: "INSERT INTO a.b SELECT c FROM d",
+		Input: []token.Token{
+			{Name: token.INSERT, Val: "INSERT"},
+			{Name: token.INTO, Val: "INTO"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "d"},
+		},
+		Expected: &parse.SqlQuery{
+			Insert: parse.SqlInsertRelation([]string{"a", "b"}),
+			Projection: parse.SqlSelectRelation(
+				[]parse.SqlExpr{
+					&parse.SqlIdentifier{Names: []string{"c"}},
+				},
+			),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+		},
+	},
+	{
+		Name: "CREATE OR REPLACE TABLE t (a BIGINT NOT NULL, b decimal(10, 2), c VARCHAR(20) NULL)",
+		Input: []token.Token{
+			{Name: token.CREATE, Val: "CREATE"},
+			{Name: token.OR, Val: "OR"},
+			{Name: token.REPLACE, Val: "REPLACE"},
+			{Name: token.TABLE, Val: "TABLE"},
+			{Name: token.IDENT, Val: "t"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.IDENT, Val: "BIGINT"},
+			{Name: token.NOT, Val: "NOT"},
+			{Name: token.NULL, Val: "NULL"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.IDENT, Val: "decimal"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.INT, Val: "10"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.INT, Val: "2"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.IDENT, Val: "VARCHAR"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.INT, Val: "20"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.NULL, Val: "NULL"},
+			{Name: token.RPAREN, Val: ")"},
+		},
+		Expected: &parse.SqlQuery{
+			Create: parse.SqlCreateRelation(
+				"TABLE",
+				[]string{"t"},
+				true,
+				[]*parse.SqlColumnDef{
+					{Name: "a", Type: &parse.SqlType{Name: "BIGINT"}, NotNull: true},
+					{Name: "b", Type: &parse.SqlType{Name: "DECIMAL", Params: []int{10, 2}}},
+					{Name: "c", Type: &parse.SqlType{Name: "VARCHAR", Params: []int{20}}},
+				},
+			),
+		},
+	},
+	{
+		Name: "CREATE TABLE t AS SELECT a FROM b UNION ALL SELECT a FROM c",
+		Input: []token.Token{
+			{Name: token.CREATE, Val: "CREATE"},
+			{Name: token.TABLE, Val: "TABLE"},
+			{Name: token.IDENT, Val: "t"},
+			{Name: token.AS, Val: "AS"},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.UNION, Val: "UNION"},
+			{Name: token.ALL, Val: "ALL"},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "c"},
+		},
+		Expected: &parse.SqlQuery{
+			Create: parse.SqlCreateRelation("TABLE", []string{"t"}, false, nil),
+			Set: &parse.SqlSetOperation{
+				Op:  "UNION",
+				All: true,
+				Left: &parse.SqlQuery{
+					Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}),
+					Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+				},
+				Right: &parse.SqlQuery{
+					Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}),
+					Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"c"}}),
+				},
+			},
+		},
+	},
+	{
+		Name: "DROP TABLE a DROP TABLE b",
+		Input: []token.Token{
+			{Name: token.DROP, Val: "DROP"},
+			{Name: token.TABLE, Val: "TABLE"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.DROP, Val: "DROP"},
+			{Name: token.TABLE, Val: "TABLE"},
+			{Name: token.IDENT, Val: "b"},
+		},
+		Error: true,
+	},
+	{
+		Name: "INSERT INTO a DROP TABLE b",
+		Input: []token.Token{
+			{Name: token.INSERT, Val: "INSERT"},
+			{Name: token.INTO, Val: "INTO"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.DROP, Val: "DROP"},
+			{Name: token.TABLE, Val: "TABLE"},
+			{Name: token.IDENT, Val: "b"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT a FROM t1 DROP TABLE t2",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "t1"},
+			{Name: token.DROP, Val: "DROP"},
+			{Name: token.TABLE, Val: "TABLE"},
+			{Name: token.IDENT, Val: "t2"},
+		},
+		Error: true,
+	},
+	{
+		Name: "DROP TABLE t1 UNION SELECT a FROM t2",
+		Input: []token.Token{
+			{Name: token.DROP, Val: "DROP"},
+			{Name: token.TABLE, Val: "TABLE"},
+			{Name: token.IDENT, Val: "t1"},
+			{Name: token.UNION, Val: "UNION"},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "t2"},
+		},
+		Error: true,
+	},
+	{
+		Name: "CREATE TABLE t3 (x INT) SELECT a FROM t1",
+		Input: []token.Token{
+			{Name: token.CREATE, Val: "CREATE"},
+			{Name: token.TABLE, Val: "TABLE"},
+			{Name: token.IDENT, Val: "t3"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "x"},
+			{Name: token.IDENT, Val: "INT"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "t1"},
+		},
+		Error: true,
+	},
+	{
+		Name: "DROP TABLE IF EXISTS a.b",
+		Input: []token.Token{
+			{Name: token.DROP, Val: "DROP"},
+			{Name: token.TABLE, Val: "TABLE"},
+			{Name: token.IF, Val: "IF"},
+			{Name: token.EXISTS, Val: "EXISTS"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.IDENT, Val: "b"},
+		},
+		Expected: &parse.SqlQuery{
+			Drop: parse.SqlDropRelation("TABLE", []string{"a", "b"}, true),
+		},
+	},
+	{
+		Name: "CREATE VIEW v (a BIGINT)",
+		Input: []token.Token{
+			{Name: token.CREATE, Val: "CREATE"},
+			{Name: token.VIEW, Val: "VIEW"},
+			{Name: token.IDENT, Val: "v"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.IDENT, Val: "BIGINT"},
+			{Name: token.RPAREN, Val: ")"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT CAST(a AS DATE) AS d, TRY_CAST(b + 1 AS DECIMAL(10, 2)) FROM c",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.CAST, Val: "CAST"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.AS, Val: "AS"},
+			{Name: token.IDENT, Val: "DATE"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.AS, Val: "AS"},
+			{Name: token.IDENT, Val: "d"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.TRY_CAST, Val: "TRY_CAST"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.ADD, Val: "+"},
+			{Name: token.INT, Val: "1"},
+			{Name: token.AS, Val: "AS"},
+			{Name: token.IDENT, Val: "DECIMAL"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.INT, Val: "10"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.INT, Val: "2"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "c"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlAlias{
+					Name: "d",
+					Input: &parse.SqlCast{
+						Expr: &parse.SqlIdentifier{Names: []string{"a"}},
+						Type: &parse.SqlType{Name: "DATE"},
+					},
+				},
+				&parse.SqlCast{
+					Expr: &parse.SqlBinaryExpr{
+						Left:  &parse.SqlIdentifier{Names: []string{"b"}},
+						Op:    "+",
+						Right: &parse.SqlIntLiteral{Value: 1},
+					},
+					Type: &parse.SqlType{Name: "DECIMAL", Params: []int{10, 2}},
+					Try:  true,
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"c"}}),
+		},
+	},
+	{
+		Name: "SELECT a * b::INT, '2024-01-01'::date x",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.MUL, Val: "*"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.DCOLON, Val: "::"},
+			{Name: token.IDENT, Val: "INT"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.STRING, Val: "2024-01-01"},
+			{Name: token.DCOLON, Val: "::"},
+			{Name: token.IDENT, Val: "date"},
+			{Name: token.IDENT, Val: "x"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlBinaryExpr{
+					Left: &parse.SqlIdentifier{Names: []string{"a"}},
+					Op:   "*",
+					Right: &parse.SqlCast{
+						Expr: &parse.SqlIdentifier{Names: []string{"b"}},
+						Type: &parse.SqlType{Name: "INT"},
+					},
+				},
+				&parse.SqlAlias{
+					Name: "x",
+					Input: &parse.SqlCast{
+						Expr: &parse.SqlStringLiteral{Value: "2024-01-01"},
+						Type: &parse.SqlType{Name: "DATE"},
+					},
+				},
+			}),
+		},
+	},
+	{
+		Name: "SELECT CAST(a DATE)",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.CAST, Val: "CAST"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.IDENT, Val: "DATE"},
+			{Name: token.RPAREN, Val: ")"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT CASE WHEN a > 10 THEN 'high' WHEN (a > 5) THEN 'mid' ELSE 'low' END AS bucket FROM b",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.CASE, Val: "CASE"},
+			{Name: token.WHEN, Val: "WHEN"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.GTR, Val: ">"},
+			{Name: token.INT, Val: "10"},
+			{Name: token.THEN, Val: "THEN"},
+			{Name: token.STRING, Val: "high"},
+			{Name: token.WHEN, Val: "WHEN"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.GTR, Val: ">"},
+			{Name: token.INT, Val: "5"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.THEN, Val: "THEN"},
+			{Name: token.STRING, Val: "mid"},
+			{Name: token.ELSE, Val: "ELSE"},
+			{Name: token.STRING, Val: "low"},
+			{Name: token.END, Val: "END"},
+			{Name: token.AS, Val: "AS"},
+			{Name: token.IDENT, Val: "bucket"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlAlias{
+					Name: "bucket",
+					Input: &parse.SqlCase{
+						Whens: []*parse.SqlWhen{
+							{
+								Cond: &parse.SqlBinaryExpr{
+									Left:  &parse.SqlIdentifier{Names: []string{"a"}},
+									Op:    ">",
+									Right: &parse.SqlIntLiteral{Value: 10},
+								},
+								Result: &parse.SqlStringLiteral{Value: "high"},
+							},
+							{
+								Cond: &parse.SqlBinaryExpr{
+									Left:  &parse.SqlIdentifier{Names: []string{"a"}},
+									Op:    ">",
+									Right: &parse.SqlIntLiteral{Value: 5},
+								},
+								Result: &parse.SqlStringLiteral{Value: "mid"},
+							},
+						},
+						Else: &parse.SqlStringLiteral{Value: "low"},
+					},
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+		},
+	},
+	{
+		Name: "SELECT CASE WHEN a THEN b",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.CASE, Val: "CASE"},
+			{Name: token.WHEN, Val: "WHEN"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.THEN, Val: "THEN"},
+			{Name: token.IDENT, Val: "b"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT CASE a WHEN 1 THEN 'one' WHEN 2 THEN 'two' END FROM b",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.CASE, Val: "CASE"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.WHEN, Val: "WHEN"},
+			{Name: token.INT, Val: "1"},
+			{Name: token.THEN, Val: "THEN"},
+			{Name: token.STRING, Val: "one"},
+			{Name: token.WHEN, Val: "WHEN"},
+			{Name: token.INT, Val: "2"},
+			{Name: token.THEN, Val: "THEN"},
+			{Name: token.STRING, Val: "two"},
+			{Name: token.END, Val: "END"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlCase{
+					Operand: &parse.SqlIdentifier{Names: []string{"a"}},
+					Whens: []*parse.SqlWhen{
+						{Cond: &parse.SqlIntLiteral{Value: 1}, Result: &parse.SqlStringLiteral{Value: "one"}},
+						{Cond: &parse.SqlIntLiteral{Value: 2}, Result: &parse.SqlStringLiteral{Value: "two"}},
+					},
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+		},
+	},
+	{
+		Name: "SELECT a FROM b WHERE a IN (1, 2) AND c NOT IN ('x')",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.WHERE, Val: "WHERE"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.IN, Val: "IN"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.INT, Val: "1"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.INT, Val: "2"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.AND, Val: "AND"},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.NOT, Val: "NOT"},
+			{Name: token.IN, Val: "IN"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.STRING, Val: "x"},
+			{Name: token.RPAREN, Val: ")"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}),
+			Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+			Filter: parse.SqlWhereRelation(
+				&parse.SqlBinaryExpr{
+					Left: &parse.SqlInList{
+						Expr: &parse.SqlIdentifier{Names: []string{"a"}},
+						List: []parse.SqlExpr{&parse.SqlIntLiteral{Value: 1}, &parse.SqlIntLiteral{Value: 2}},
+					},
+					Op: "AND",
+					Right: &parse.SqlInList{
+						Expr: &parse.SqlIdentifier{Names: []string{"c"}},
+						List: []parse.SqlExpr{&parse.SqlStringLiteral{Value: "x"}},
+						Not:  true,
+					},
+				},
+			),
+		},
+	},
+	{
+		Name: "SELECT a FROM b WHERE (a, c) IN ((1, 'x'), (2, 'y'))",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.WHERE, Val: "WHERE"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.IN, Val: "IN"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.INT, Val: "1"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.STRING, Val: "x"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.INT, Val: "2"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.STRING, Val: "y"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.RPAREN, Val: ")"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}),
+			Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+			Filter: parse.SqlWhereRelation(
+				&parse.SqlInList{
+					Expr: &parse.SqlTuple{Exprs: []parse.SqlExpr{
+						&parse.SqlIdentifier{Names: []string{"a"}},
+						&parse.SqlIdentifier{Names: []string{"c"}},
+					}},
+					List: []parse.SqlExpr{
+						&parse.SqlTuple{Exprs: []parse.SqlExpr{&parse.SqlIntLiteral{Value: 1}, &parse.SqlStringLiteral{Value: "x"}}},
+						&parse.SqlTuple{Exprs: []parse.SqlExpr{&parse.SqlIntLiteral{Value: 2}, &parse.SqlStringLiteral{Value: "y"}}},
+					},
+				},
+			),
+		},
+	},
+	{
+		Name: "SELECT a FROM b WHERE a IN (1",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.WHERE, Val: "WHERE"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.IN, Val: "IN"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.INT, Val: "1"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT a, (SELECT c FROM d) FROM b",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "d"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlIdentifier{Names: []string{"a"}},
+				&parse.SqlQuery{
+					Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"c"}}}),
+					Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+		},
+	},
+	{
+		Name: "SELECT a FROM b WHERE EXISTS (SELECT c FROM d WHERE d.c = b.a) AND a NOT IN (SELECT c FROM d)",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.WHERE, Val: "WHERE"},
+			{Name: token.EXISTS, Val: "EXISTS"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "d"},
+			{Name: token.WHERE, Val: "WHERE"},
+			{Name: token.IDENT, Val: "d"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.EQL, Val: "="},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.AND, Val: "AND"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.NOT, Val: "NOT"},
+			{Name: token.IN, Val: "IN"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "d"},
+			{Name: token.RPAREN, Val: ")"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}),
+			Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+			Filter: parse.SqlWhereRelation(
+				&parse.SqlBinaryExpr{
+					Left: &parse.SqlExists{
+						Query: &parse.SqlQuery{
+							Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"c"}}}),
+							Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+							Filter: parse.SqlWhereRelation(
+								&parse.SqlBinaryExpr{
+									Left:  &parse.SqlIdentifier{Names: []string{"d", "c"}},
+									Op:    "=",
+									Right: &parse.SqlIdentifier{Names: []string{"b", "a"}},
+								},
+							),
+						},
+					},
+					Op: "AND",
+					Right: &parse.SqlInList{
+						Expr: &parse.SqlIdentifier{Names: []string{"a"}},
+						Query: &parse.SqlQuery{
+							Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"c"}}}),
+							Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+						},
+						Not: true,
+					},
+				},
+			),
+		},
+	},
+	{
+		Name: "SELECT a FROM b WHERE NOT EXISTS (SELECT c FROM d) OR a > SOME (SELECT c FROM d)",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.WHERE, Val: "WHERE"},
+			{Name: token.NOT, Val: "NOT"},
+			{Name: token.EXISTS, Val: "EXISTS"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "d"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.OR, Val: "OR"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.GTR, Val: ">"},
+			{Name: token.SOME, Val: "SOME"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "d"},
+			{Name: token.RPAREN, Val: ")"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}),
+			Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+			Filter: parse.SqlWhereRelation(
+				&parse.SqlBinaryExpr{
+					Left: &parse.SqlExists{
+						Query: &parse.SqlQuery{
+							Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"c"}}}),
+							Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+						},
+						Not: true,
+					},
+					Op: "OR",
+					Right: &parse.SqlSetComparison{
+						Left:       &parse.SqlIdentifier{Names: []string{"a"}},
+						Op:         ">",
+						Quantifier: "ANY",
+						Query: &parse.SqlQuery{
+							Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"c"}}}),
+							Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+						},
+					},
+				},
+			),
+		},
+	},
+	{
+		Name: "SELECT a FROM b WHERE a = ALL (1, 2)",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.WHERE, Val: "WHERE"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.EQL, Val: "="},
+			{Name: token.ALL, Val: "ALL"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.INT, Val: "1"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.INT, Val: "2"},
+			{Name: token.RPAREN, Val: ")"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT [1, a + 2], STRUCT(a, 'x'), ROW(), MAP('k', [a]) FROM b",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.LBRACK, Val: "["},
+			{Name: token.INT, Val: "1"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.ADD, Val: "+"},
+			{Name: token.INT, Val: "2"},
+			{Name: token.RBRACK, Val: "]"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.STRUCT, Val: "STRUCT"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.STRING, Val: "x"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.ROW, Val: "ROW"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.MAP, Val: "MAP"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.STRING, Val: "k"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.LBRACK, Val: "["},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.RBRACK, Val: "]"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlArray{Elems: []parse.SqlExpr{
+					&parse.SqlIntLiteral{Value: 1},
+					&parse.SqlBinaryExpr{
+						Left:  &parse.SqlIdentifier{Names: []string{"a"}},
+						Op:    "+",
+						Right: &parse.SqlIntLiteral{Value: 2},
+					},
+				}},
+				&parse.SqlStruct{Fields: []parse.SqlExpr{
+					&parse.SqlIdentifier{Names: []string{"a"}},
+					&parse.SqlStringLiteral{Value: "x"},
+				}},
+				&parse.SqlStruct{},
+				&parse.SqlMap{
+					Keys: []parse.SqlExpr{&parse.SqlStringLiteral{Value: "k"}},
+					Values: []parse.SqlExpr{
+						&parse.SqlArray{Elems: []parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}},
+					},
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+		},
+	},
+	{
+		Name: "SELECT MAP('k', 1, 'v') FROM b",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.MAP, Val: "MAP"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.STRING, Val: "k"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.INT, Val: "1"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.STRING, Val: "v"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT [1, 2 FROM b",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.LBRACK, Val: "["},
+			{Name: token.INT, Val: "1"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.INT, Val: "2"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "b"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT a.b[1]['k'] + 1 FROM c",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.LBRACK, Val: "["},
+			{Name: token.INT, Val: "1"},
+			{Name: token.RBRACK, Val: "]"},
+			{Name: token.LBRACK, Val: "["},
+			{Name: token.STRING, Val: "k"},
+			{Name: token.RBRACK, Val: "]"},
+			{Name: token.ADD, Val: "+"},
+			{Name: token.INT, Val: "1"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "c"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlBinaryExpr{
+					Left: &parse.SqlSubscript{
+						Expr: &parse.SqlSubscript{
+							Expr:  &parse.SqlIdentifier{Names: []string{"a", "b"}},
+							Index: &parse.SqlIntLiteral{Value: 1},
+						},
+						Index: &parse.SqlStringLiteral{Value: "k"},
+					},
+					Op:    "+",
+					Right: &parse.SqlIntLiteral{Value: 1},
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"c"}}),
+		},
+	},
+	{
+		Name: "SELECT STRUCT(a, b).a, c[1].d FROM e",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.STRUCT, Val: "STRUCT"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.LBRACK, Val: "["},
+			{Name: token.INT, Val: "1"},
+			{Name: token.RBRACK, Val: "]"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.IDENT, Val: "d"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "e"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlFieldAccess{
+					Expr: &parse.SqlStruct{
+						Fields: []parse.SqlExpr{
+							&parse.SqlIdentifier{Names: []string{"a"}},
+							&parse.SqlIdentifier{Names: []string{"b"}},
+						},
+					},
+					Name: "a",
+				},
+				&parse.SqlFieldAccess{
+					Expr: &parse.SqlSubscript{
+						Expr:  &parse.SqlIdentifier{Names: []string{"c"}},
+						Index: &parse.SqlIntLiteral{Value: 1},
+					},
+					Name: "d",
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"e"}}),
+		},
+	},
+	{
+		Name: "SELECT STRUCT(a). FROM c",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.STRUCT, Val: "STRUCT"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.PERIOD, Val: "."},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "c"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT a[1 FROM c",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.LBRACK, Val: "["},
+			{Name: token.INT, Val: "1"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "c"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT sum(b) OVER (RANGE BETWEEN 1 FOLLOWING AND 1 PRECEDING) FROM t",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "sum"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.OVER, Val: "OVER"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.RANGE, Val: "RANGE"},
+			{Name: token.BETWEEN, Val: "BETWEEN"},
+			{Name: token.INT, Val: "1"},
+			{Name: token.IDENT, Val: "FOLLOWING"},
+			{Name: token.AND, Val: "AND"},
+			{Name: token.INT, Val: "1"},
+			{Name: token.IDENT, Val: "PRECEDING"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "t"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT sum(b) OVER (ROWS BETWEEN UNBOUNDED FOLLOWING AND UNBOUNDED FOLLOWING) FROM t",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "sum"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.OVER, Val: "OVER"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.ROWS, Val: "ROWS"},
+			{Name: token.BETWEEN, Val: "BETWEEN"},
+			{Name: token.IDENT, Val: "UNBOUNDED"},
+			{Name: token.IDENT, Val: "FOLLOWING"},
+			{Name: token.AND, Val: "AND"},
+			{Name: token.IDENT, Val: "UNBOUNDED"},
+			{Name: token.IDENT, Val: "FOLLOWING"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "t"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT sum(b) OVER (ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED PRECEDING) FROM t",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "sum"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.OVER, Val: "OVER"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.ROWS, Val: "ROWS"},
+			{Name: token.BETWEEN, Val: "BETWEEN"},
+			{Name: token.IDENT, Val: "UNBOUNDED"},
+			{Name: token.IDENT, Val: "PRECEDING"},
+			{Name: token.AND, Val: "AND"},
+			{Name: token.IDENT, Val: "UNBOUNDED"},
+			{Name: token.IDENT, Val: "PRECEDING"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "t"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT sum(b) OVER (ROWS 1 FOLLOWING) FROM t",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "sum"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.OVER, Val: "OVER"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.ROWS, Val: "ROWS"},
+			{Name: token.INT, Val: "1"},
+			{Name: token.IDENT, Val: "FOLLOWING"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "t"},
+		},
+		Error: true,
+	},
+	{
+		Name: "SELECT a, sum(b) OVER (PARTITION BY a ORDER BY c DESC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS total FROM t",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.COMMA, Val: ","},
+			{Name: token.IDENT, Val: "sum"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "b"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.OVER, Val: "OVER"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.PARTITION, Val: "PARTITION"},
+			{Name: token.BY, Val: "BY"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.ORDER, Val: "ORDER"},
+			{Name: token.BY, Val: "BY"},
+			{Name: token.IDENT, Val: "c"},
+			{Name: token.DESC, Val: "DESC"},
+			{Name: token.ROWS, Val: "ROWS"},
+			{Name: token.BETWEEN, Val: "BETWEEN"},
+			{Name: token.IDENT, Val: "UNBOUNDED"},
+			{Name: token.IDENT, Val: "PRECEDING"},
+			{Name: token.AND, Val: "AND"},
+			{Name: token.CURRENT, Val: "CURRENT"},
+			{Name: token.ROW, Val: "ROW"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.AS, Val: "AS"},
+			{Name: token.IDENT, Val: "total"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "t"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlIdentifier{Names: []string{"a"}},
+				&parse.SqlAlias{
+					Name: "total",
+					Input: &parse.SqlFunctionExpr{
+						Name: "sum",
+						Args: []parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"b"}}},
+						Over: &parse.SqlWindow{
+							PartitionBy: []parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}},
+							OrderBy: []*parse.SqlSortItem{
+								{Expr: &parse.SqlIdentifier{Names: []string{"c"}}, Direction: "DESC"},
+							},
+							Frame: &parse.SqlWindowFrame{
+								Units: "ROWS",
+								Start: &parse.SqlFrameBound{Kind: "UNBOUNDED PRECEDING"},
+								End:   &parse.SqlFrameBound{Kind: "CURRENT ROW"},
+							},
+						},
+					},
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"t"}}),
+		},
+	},
+	{
+		Name: "SELECT (rank() OVER (ORDER BY a RANGE 2 PRECEDING)) + 1 FROM t",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.IDENT, Val: "rank"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.OVER, Val: "OVER"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.ORDER, Val: "ORDER"},
+			{Name: token.BY, Val: "BY"},
+			{Name: token.IDENT, Val: "a"},
+			{Name: token.RANGE, Val: "RANGE"},
+			{Name: token.INT, Val: "2"},
+			{Name: token.IDENT, Val: "PRECEDING"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.ADD, Val: "+"},
+			{Name: token.INT, Val: "1"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "t"},
+		},
+		Expected: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlBinaryExpr{
+					Left: &parse.SqlFunctionExpr{
+						Name: "rank",
+						Over: &parse.SqlWindow{
+							OrderBy: []*parse.SqlSortItem{
+								{Expr: &parse.SqlIdentifier{Names: []string{"a"}}},
+							},
+							Frame: &parse.SqlWindowFrame{
+								Units: "RANGE",
+								Start: &parse.SqlFrameBound{Kind: "PRECEDING", Offset: 2},
+							},
+						},
+					},
+					Op:    "+",
+					Right: &parse.SqlIntLiteral{Value: 1},
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"t"}}),
+		},
+	},
+	{
+		Name: "SELECT rank() OVER (ROWS BETWEEN 1 PRECEDING) FROM t",
+		Input: []token.Token{
+			{Name: token.SELECT, Val: "SELECT"},
+			{Name: token.IDENT, Val: "rank"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.OVER, Val: "OVER"},
+			{Name: token.LPAREN, Val: "("},
+			{Name: token.ROWS, Val: "ROWS"},
+			{Name: token.BETWEEN, Val: "BETWEEN"},
+			{Name: token.INT, Val: "1"},
+			{Name: token.IDENT, Val: "PRECEDING"},
+			{Name: token.RPAREN, Val: ")"},
+			{Name: token.FROM, Val: "FROM"},
+			{Name: token.IDENT, Val: "t"},
+		},
+		Error: true,
+	},
 }
 
 func TestQueryParser(t *testing.T) {
@@ -4,20 +4,48 @@ import "fmt"
 
 type SqlQuery struct {
 	Alias      string
+	Set        *SqlSetOperation
 	Read       *sqlFromRelation
 	Projection *sqlSelectRelation
 	Filter     *sqlWhereRelation
+	OrderBy    *sqlOrderByRelation
+	Limit      *sqlLimitRelation
+	Offset     *sqlOffsetRelation
+	Insert     *sqlInsertRelation
+	Create     *sqlCreateRelation
+	Drop       *sqlDropRelation
 }
 
 // Children implements SqlExpr.
 func (q *SqlQuery) Children() []SqlNode {
 	children := make([]SqlNode, 0)
+	if q.Set != nil {
+		children = append(children, q.Set)
+	}
 	if q.Read != nil {
 		children = append(children, q.Read)
 	}
 	if q.Projection != nil {
 		children = append(children, q.Projection)
 	}
+	if q.OrderBy != nil {
+		children = append(children, q.OrderBy)
+	}
+	if q.Limit != nil {
+		children = append(children, q.Limit)
+	}
+	if q.Offset != nil {
+		children = append(children, q.Offset)
+	}
+	if q.Insert != nil {
+		children = append(children, q.Insert)
+	}
+	if q.Create != nil {
+		children = append(children, q.Create)
+	}
+	if q.Drop != nil {
+		children = append(children, q.Drop)
+	}
 	return children
 }
 
@@ -25,6 +53,26 @@ func (q *SqlQuery) String() string {
 	return "TODO"
 }
 
+// SqlSetOperation combines the results of two queries. Op is one of UNION,
+// INTERSECT or EXCEPT, and All is set if duplicate records are retained.
+type SqlSetOperation struct {
+	Op          string
+	All         bool
+	Left, Right *SqlQuery
+}
+
+func (s *SqlSetOperation) Children() []SqlNode {
+	return []SqlNode{s.Left, s.Right}
+}
+
+func (s *SqlSetOperation) String() string {
+	op := s.Op
+	if s.All {
+		op += " ALL"
+	}
+	return fmt.Sprintf("%s %s %s", s.Left, op, s.Right)
+}
+
 // func NewQueryBuilder() *SqlQ
 
 type SqlQueryBuilder struct {
@@ -58,6 +106,60 @@ func (bldr *SqlQueryBuilder) Where(rel *sqlWhereRelation) error {
 	return nil
 }
 
+func (bldr *SqlQueryBuilder) OrderBy(rel *sqlOrderByRelation) error {
+	if bldr.query.OrderBy != nil {
+		return fmt.Errorf("parse: query cannot have more than one ORDER BY")
+	}
+
+	bldr.query.OrderBy = rel
+	return nil
+}
+
+func (bldr *SqlQueryBuilder) Limit(rel *sqlLimitRelation) error {
+	if bldr.query.Limit != nil {
+		return fmt.Errorf("parse: query cannot have more than one LIMIT")
+	}
+
+	bldr.query.Limit = rel
+	return nil
+}
+
+func (bldr *SqlQueryBuilder) Offset(rel *sqlOffsetRelation) error {
+	if bldr.query.Offset != nil {
+		return fmt.Errorf("parse: query cannot have more than one OFFSET")
+	}
+
+	bldr.query.Offset = rel
+	return nil
+}
+
+func (bldr *SqlQueryBuilder) Insert(rel *sqlInsertRelation) error {
+	if bldr.query.Insert != nil || bldr.query.Create != nil || bldr.query.Drop != nil {
+		return fmt.Errorf("parse: query cannot have more than one INSERT, CREATE or DROP")
+	}
+
+	bldr.query.Insert = rel
+	return nil
+}
+
+func (bldr *SqlQueryBuilder) Create(rel *sqlCreateRelation) error {
+	if bldr.query.Insert != nil || bldr.query.Create != nil || bldr.query.Drop != nil {
+		return fmt.Errorf("parse: query cannot have more than one INSERT, CREATE or DROP")
+	}
+
+	bldr.query.Create = rel
+	return nil
+}
+
+func (bldr *SqlQueryBuilder) Drop(rel *sqlDropRelation) error {
+	if bldr.query.Insert != nil || bldr.query.Create != nil || bldr.query.Drop != nil {
+		return fmt.Errorf("parse: query cannot have more than one INSERT, CREATE or DROP")
+	}
+
+	bldr.query.Drop = rel
+	return nil
+}
+
 func (bldr *SqlQueryBuilder) Query() *SqlQuery {
 	query := bldr.query
 	bldr.query = SqlQuery{}
@@ -65,3 +167,4 @@ func (bldr *SqlQueryBuilder) Query() *SqlQuery {
 }
 
 var _ SqlExpr = (*SqlQuery)(nil)
+var _ SqlExpr = (*SqlSetOperation)(nil)
@@ -83,6 +83,195 @@ func (r *sqlWhereRelation) String() string {
 	return fmt.Sprintf("%s\n\t%s", r.Name(), r.Expr.String())
 }
 
+func SqlOrderByRelation(items []*SqlSortItem) *sqlOrderByRelation {
+	return &sqlOrderByRelation{Items: items}
+}
+
+type sqlOrderByRelation struct {
+	Items []*SqlSortItem
+}
+
+func (r *sqlOrderByRelation) Children() []SqlNode {
+	children := make([]SqlNode, len(r.Items))
+	for i, item := range r.Items {
+		children[i] = item
+	}
+	return children
+}
+
+func (*sqlOrderByRelation) Name() string {
+	return "ORDER BY"
+}
+
+func (r *sqlOrderByRelation) String() string {
+	s := make([]string, 0, len(r.Items))
+	for _, item := range r.Items {
+		s = append(s, item.String())
+	}
+
+	return fmt.Sprintf("%s\n\t%s", r.Name(), strings.Join(s, ",\n\t"))
+}
+
+func SqlLimitRelation(count int) *sqlLimitRelation {
+	return &sqlLimitRelation{Count: count}
+}
+
+type sqlLimitRelation struct {
+	Count int
+}
+
+func (*sqlLimitRelation) Children() []SqlNode {
+	return nil
+}
+
+func (*sqlLimitRelation) Name() string {
+	return "LIMIT"
+}
+
+func (r *sqlLimitRelation) String() string {
+	return fmt.Sprintf("%s %d", r.Name(), r.Count)
+}
+
+func SqlOffsetRelation(offset int) *sqlOffsetRelation {
+	return &sqlOffsetRelation{Offset: offset}
+}
+
+type sqlOffsetRelation struct {
+	Offset int
+}
+
+func (*sqlOffsetRelation) Children() []SqlNode {
+	return nil
+}
+
+func (*sqlOffsetRelation) Name() string {
+	return "OFFSET"
+}
+
+func (r *sqlOffsetRelation) String() string {
+	return fmt.Sprintf("%s %d", r.Name(), r.Offset)
+}
+
+func SqlInsertRelation(table []string) *sqlInsertRelation {
+	return &sqlInsertRelation{Table: table}
+}
+
+type sqlInsertRelation struct {
+	Table []string
+}
+
+func (*sqlInsertRelation) Children() []SqlNode {
+	return nil
+}
+
+func (*sqlInsertRelation) Name() string {
+	return "INSERT INTO"
+}
+
+func (r *sqlInsertRelation) String() string {
+	return fmt.Sprintf("%s %s", r.Name(), strings.Join(r.Table, "."))
+}
+
+// SqlCreateRelation creates a TABLE or VIEW with the provided name. Columns
+// define the schema of a table, and are nil if it is created from the
+// results of the query.
+func SqlCreateRelation(object string, name []string, orReplace bool, columns []*SqlColumnDef) *sqlCreateRelation {
+	return &sqlCreateRelation{Object: object, Identifier: name, OrReplace: orReplace, Columns: columns}
+}
+
+type sqlCreateRelation struct {
+	Object     string
+	Identifier []string
+	OrReplace  bool
+	Columns    []*SqlColumnDef
+}
+
+func (r *sqlCreateRelation) Children() []SqlNode {
+	children := make([]SqlNode, len(r.Columns))
+	for i, col := range r.Columns {
+		children[i] = col
+	}
+	return children
+}
+
+func (r *sqlCreateRelation) Name() string {
+	if r.OrReplace {
+		return "CREATE OR REPLACE " + r.Object
+	}
+	return "CREATE " + r.Object
+}
+
+func (r *sqlCreateRelation) String() string {
+	s := make([]string, 0, len(r.Columns))
+	for _, col := range r.Columns {
+		s = append(s, col.String())
+	}
+
+	return fmt.Sprintf("%s %s\n\t%s", r.Name(), strings.Join(r.Identifier, "."), strings.Join(s, ",\n\t"))
+}
+
+func SqlDropRelation(object string, name []string, ifExists bool) *sqlDropRelation {
+	return &sqlDropRelation{Object: object, Identifier: name, IfExists: ifExists}
+}
+
+type sqlDropRelation struct {
+	Object     string
+	Identifier []string
+	IfExists   bool
+}
+
+func (*sqlDropRelation) Children() []SqlNode {
+	return nil
+}
+
+func (r *sqlDropRelation) Name() string {
+	if r.IfExists {
+		return "DROP " + r.Object + " IF EXISTS"
+	}
+	return "DROP " + r.Object
+}
+
+func (r *sqlDropRelation) String() string {
+	return fmt.Sprintf("%s %s", r.Name(), strings.Join(r.Identifier, "."))
+}
+
+// SqlJoin combines two table expressions in a FROM clause. Type is one of
+// INNER, LEFT, RIGHT, FULL or CROSS. Unless the join is a CROSS join, either
+// On holds the join condition or Using lists the columns that must be equal
+// in both tables.
+type SqlJoin struct {
+	Left, Right SqlExpr
+	Type        string
+	On          SqlExpr
+	Using       []string
+}
+
+func (j *SqlJoin) Children() []SqlNode {
+	children := []SqlNode{j.Left, j.Right}
+	if j.On != nil {
+		children = append(children, j.On)
+	}
+	return children
+}
+
+func (j *SqlJoin) String() string {
+	s := fmt.Sprintf("%s %s JOIN %s", j.Left, j.Type, j.Right)
+	if j.On != nil {
+		s += fmt.Sprintf(" ON %s", j.On)
+	}
+	if len(j.Using) > 0 {
+		s += fmt.Sprintf(" USING (%s)", strings.Join(j.Using, ", "))
+	}
+	return s
+}
+
 var _ SqlRelation = (*sqlSelectRelation)(nil)
 var _ SqlRelation = (*sqlFromRelation)(nil)
 var _ SqlRelation = (*sqlWhereRelation)(nil)
+var _ SqlRelation = (*sqlOrderByRelation)(nil)
+var _ SqlRelation = (*sqlLimitRelation)(nil)
+var _ SqlRelation = (*sqlOffsetRelation)(nil)
+var _ SqlRelation = (*sqlInsertRelation)(nil)
+var _ SqlRelation = (*sqlCreateRelation)(nil)
+var _ SqlRelation = (*sqlDropRelation)(nil)
+var _ SqlExpr = (*SqlJoin)(nil)
@@ -2,34 +2,58 @@ package plan
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/joellubi/bonobo"
 	"github.com/joellubi/bonobo/engine"
 	"github.com/joellubi/bonobo/sql/parse"
 	"github.com/substrait-io/substrait-go/v3/extensions"
 )
 
 func CreateLogicalExpr(expr parse.SqlExpr) (engine.Expr, error) {
+	return createExpr(expr, nil)
+}
+
+// createExpr plans expr against the input of the query sc. Subqueries in expr
+// may refer to the tables of sc and its enclosing queries.
+func createExpr(expr parse.SqlExpr, sc *scope) (engine.Expr, error) {
 	switch e := expr.(type) {
 	case *parse.SqlIdentifier:
-		if len(e.Names) != 1 {
-			return nil, fmt.Errorf("unimplemented: multi-part column identifier: %s", e.Names)
-		}
-
-		var ident engine.Expr
-		ident = engine.NewColumnExpr(e.Names[0])
+		ident := createIdentifier(e.Names, sc)
 		if e.Alias != "" {
 			ident = engine.NewAliasExpr(ident, e.Alias)
 		}
 
 		return ident, nil
 	case *parse.SqlIntLiteral:
-		return engine.NewLiteralExpr(e.Value), nil
+		return engine.NewLiteral(e.Value)
+	case *parse.SqlStringLiteral:
+		return engine.NewLiteral(e.Value)
+	case *parse.SqlCast:
+		input, err := createExpr(e.Expr, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		// The input may be null, so the result of any cast may be null
+		typ, err := CreateType(e.Type, true)
+		if err != nil {
+			return nil, err
+		}
+
+		failure := engine.CastFailureThrowException
+		if e.Try {
+			failure = engine.CastFailureReturnNull
+		}
+
+		return engine.NewCastExpr(input, typ, failure), nil
 	case *parse.SqlBinaryExpr:
-		left, err := CreateLogicalExpr(e.Left)
+		left, err := createExpr(e.Left, sc)
 		if err != nil {
 			return nil, err
 		}
-		right, err := CreateLogicalExpr(e.Right)
+		right, err := createExpr(e.Right, sc)
 		if err != nil {
 			return nil, err
 		}
@@ -40,8 +64,118 @@ func CreateLogicalExpr(expr parse.SqlExpr) (engine.Expr, error) {
 		}
 
 		return engine.NewFunctionExpr(functionID.URI, functionID.Name, left, right), nil
+	case *parse.SqlCase:
+		return createCase(e, sc)
+	case *parse.SqlInList:
+		in, err := createInList(e, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.Not {
+			return engine.NewFunctionExpr(Not.URI, Not.Name, in), nil
+		}
+
+		return in, nil
+	case *parse.SqlQuery:
+		rel, err := createPlan(e, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		return engine.NewScalarSubqueryExpr(rel), nil
+	case *parse.SqlExists:
+		rel, err := createPlan(e.Query, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		var exists engine.Expr = engine.NewSetPredicateSubqueryExpr(engine.PredicateOpExists, rel)
+		if e.Not {
+			exists = engine.NewFunctionExpr(Not.URI, Not.Name, exists)
+		}
+
+		return exists, nil
+	case *parse.SqlSetComparison:
+		return createSetComparison(e, sc)
+	case *parse.SqlSubscript:
+		input, err := createExpr(e.Expr, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		// Whether the subscript selects a list element or a map value depends
+		// on the type of the input, which is resolved by the engine
+		var key *engine.Literal
+		switch index := e.Index.(type) {
+		case *parse.SqlIntLiteral:
+			key, err = engine.NewLiteral(index.Value)
+		case *parse.SqlStringLiteral:
+			key, err = engine.NewLiteral(index.Value)
+		default:
+			return nil, fmt.Errorf("plan: subscript must be an integer or string literal, found %s", index)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return engine.NewFieldPathExpr(input, engine.SubscriptSegment(key)), nil
+	case *parse.SqlFieldAccess:
+		input, err := createExpr(e.Expr, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		return engine.NewFieldPathExpr(input, engine.NamedStructFieldSegment(e.Name)), nil
+	case *parse.SqlArray:
+		if len(e.Elems) == 0 {
+			return nil, fmt.Errorf("plan: empty arrays are not supported")
+		}
+
+		values, err := createLogicalExprs(e.Elems, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		return engine.NewListExpr(values...), nil
+	case *parse.SqlStruct:
+		fields, err := createLogicalExprs(e.Fields, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		return engine.NewStructExpr(fields...), nil
+	case *parse.SqlMap:
+		if len(e.Keys) == 0 {
+			return nil, fmt.Errorf("plan: empty maps are not supported")
+		}
+
+		entries := make([]engine.MapEntry, len(e.Keys))
+		for i := range e.Keys {
+			key, err := createExpr(e.Keys[i], sc)
+			if err != nil {
+				return nil, err
+			}
+			value, err := createExpr(e.Values[i], sc)
+			if err != nil {
+				return nil, err
+			}
+			entries[i] = engine.MapEntry{Key: key, Value: value}
+		}
+
+		return engine.NewMapExpr(entries...), nil
+	case *parse.SqlFunctionExpr:
+		if e.Over != nil {
+			return createWindowFunction(e, sc)
+		}
+
+		if _, err := resolveWindowFunctionID(e.Name); err == nil {
+			return nil, fmt.Errorf("plan: function %s is only supported as a window function", e.Name)
+		}
+
+		return nil, fmt.Errorf("plan: unrecognized function: %s", e.Name)
 	case *parse.SqlAlias:
-		input, err := CreateLogicalExpr(e.Input)
+		input, err := createExpr(e.Input, sc)
 		if err != nil {
 			return nil, err
 		}
@@ -52,64 +186,893 @@ func CreateLogicalExpr(expr parse.SqlExpr) (engine.Expr, error) {
 	}
 }
 
+// createIdentifier plans a reference to a column. All names but the last
+// identify the table, as in t.col or schema.table.col, unless they refer to
+// a field of a struct column, which the engine resolves against the schema.
+// Names qualified by a table of an enclosing query are outer references.
+func createIdentifier(names []string, sc *scope) engine.Expr {
+	n := len(names)
+	if n == 1 {
+		return engine.NewColumnExpr(names[0])
+	}
+
+	for i := n - 1; i > 0; i-- {
+		qualifier := strings.Join(names[:i], ".")
+		outer, stepsOut, ok := sc.resolveOuter(qualifier)
+		if !ok {
+			continue
+		}
+
+		var ref engine.Expr = engine.NewOuterReferenceExpr(outer, stepsOut, engine.NewQualifiedColumnExpr(qualifier, names[i]))
+		if i < n-1 {
+			segments := make([]engine.PathSegment, 0, n-i-1)
+			for _, name := range names[i+1:] {
+				segments = append(segments, engine.NamedStructFieldSegment(name))
+			}
+			ref = engine.NewFieldPathExpr(ref, segments...)
+		}
+		return ref
+	}
+
+	return engine.NewQualifiedColumnExpr(strings.Join(names[:n-1], "."), names[n-1])
+}
+
+// createCase plans a CASE expression. A simple CASE whose values are all
+// literals is planned as a switch, and any other CASE as an if-then with a
+// condition for each value.
+func createCase(expr *parse.SqlCase, sc *scope) (engine.Expr, error) {
+	var (
+		operand engine.Expr
+		err     error
+	)
+	if expr.Operand != nil {
+		operand, err = createExpr(expr.Operand, sc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var els engine.Expr
+	if expr.Else != nil {
+		els, err = createExpr(expr.Else, sc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	conds := make([]engine.Expr, len(expr.Whens))
+	results := make([]engine.Expr, len(expr.Whens))
+	for i, when := range expr.Whens {
+		conds[i], err = createExpr(when.Cond, sc)
+		if err != nil {
+			return nil, err
+		}
+		results[i], err = createExpr(when.Result, sc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if operand != nil {
+		if cases, ok := switchCases(conds, results); ok {
+			return engine.NewSwitchExpr(operand, cases, els), nil
+		}
+	}
+
+	clauses := make([]engine.IfClause, len(conds))
+	for i, cond := range conds {
+		if operand != nil {
+			cond = engine.NewFunctionExpr(Equal.URI, Equal.Name, operand, cond)
+		}
+		clauses[i] = engine.IfClause{If: cond, Then: results[i]}
+	}
+
+	return engine.NewIfThenExpr(clauses, els), nil
+}
+
+// createInList plans an IN list, comparing either a single value or a tuple
+// of values to each item of the list.
+func createInList(expr *parse.SqlInList, sc *scope) (engine.Expr, error) {
+	if expr.Query != nil {
+		return createInSubquery(expr, sc)
+	}
+
+	tuple, ok := expr.Expr.(*parse.SqlTuple)
+	if !ok {
+		value, err := createExpr(expr.Expr, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		options, err := createLogicalExprs(expr.List, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		return engine.NewInListExpr(value, options...), nil
+	}
+
+	values, err := createLogicalExprs(tuple.Exprs, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([][]engine.Expr, len(expr.List))
+	for i, item := range expr.List {
+		option, ok := item.(*parse.SqlTuple)
+		if !ok || len(option.Exprs) != len(values) {
+			return nil, fmt.Errorf("plan: IN list item %s must be a list of %d values", item, len(values))
+		}
+
+		options[i], err = createLogicalExprs(option.Exprs, sc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return engine.NewMultiInListExpr(values, options...), nil
+}
+
+// createInSubquery plans an IN list whose items are the rows of a subquery.
+func createInSubquery(expr *parse.SqlInList, sc *scope) (engine.Expr, error) {
+	needles := []parse.SqlExpr{expr.Expr}
+	if tuple, ok := expr.Expr.(*parse.SqlTuple); ok {
+		needles = tuple.Exprs
+	}
+
+	values, err := createLogicalExprs(needles, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	haystack, err := createPlan(expr.Query, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.NewInSubqueryExpr(values, haystack), nil
+}
+
+// createSetComparison plans a comparison of a value with ANY or ALL of the
+// rows of a subquery.
+func createSetComparison(expr *parse.SqlSetComparison, sc *scope) (engine.Expr, error) {
+	var op engine.ComparisonOp
+	switch expr.Op {
+	case "=":
+		op = engine.ComparisonOpEq
+	case "!=":
+		op = engine.ComparisonOpNe
+	case "<":
+		op = engine.ComparisonOpLt
+	case ">":
+		op = engine.ComparisonOpGt
+	case "<=":
+		op = engine.ComparisonOpLe
+	case ">=":
+		op = engine.ComparisonOpGe
+	default:
+		return nil, fmt.Errorf("plan: unrecognized comparison for subquery: %s", expr.Op)
+	}
+
+	var reduction engine.ReductionOp
+	switch expr.Quantifier {
+	case "ANY":
+		reduction = engine.ReductionOpAny
+	case "ALL":
+		reduction = engine.ReductionOpAll
+	default:
+		return nil, fmt.Errorf("plan: unrecognized quantifier for subquery: %s", expr.Quantifier)
+	}
+
+	left, err := createExpr(expr.Left, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := createPlan(expr.Query, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.NewSetComparisonSubqueryExpr(left, op, reduction, right), nil
+}
+
+func createLogicalExprs(exprs []parse.SqlExpr, sc *scope) ([]engine.Expr, error) {
+	result := make([]engine.Expr, len(exprs))
+	for i, expr := range exprs {
+		var err error
+		result[i], err = createExpr(expr, sc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// switchCases pairs each value with its result if all values are literals.
+func switchCases(values, results []engine.Expr) ([]engine.SwitchCase, bool) {
+	cases := make([]engine.SwitchCase, len(values))
+	for i, value := range values {
+		lit, ok := value.(*engine.Literal)
+		if !ok {
+			return nil, false
+		}
+		cases[i] = engine.SwitchCase{Value: lit, Then: results[i]}
+	}
+
+	return cases, true
+}
+
 func CreateLogicalPlan(query *parse.SqlQuery) (engine.Relation, error) {
+	return createPlan(query, nil)
+}
+
+// createPlan plans query, which is a subquery of outer if outer is not nil.
+func createPlan(query *parse.SqlQuery, outer *scope) (engine.Relation, error) {
 	var (
 		plan engine.Relation
 		err  error
 	)
 
-	if query.Read != nil {
-		switch t := query.Read.Table.(type) {
-		case *parse.SqlIdentifier:
-			table := engine.NewNamedTable(t.Names, nil)
-			plan = engine.NewReadOperation(table)
-		case *parse.SqlQuery:
-			plan, err = CreateLogicalPlan(t)
-			if err != nil {
-				return nil, err
-			}
-		default:
-			return nil, fmt.Errorf("plan: unrecognized SqlExpr type for Read operation: %T", t)
+	if query.Drop != nil {
+		return createDrop(query.Drop.Object, query.Drop.Identifier, query.Drop.IfExists)
+	}
+
+	if query.Create != nil && query.Create.Columns != nil {
+		return createTable(query.Create.Identifier, query.Create.OrReplace, query.Create.Columns)
+	}
+
+	if query.Set != nil {
+		plan, err = createSetOperation(query.Set, outer)
+		if err != nil {
+			return nil, err
+		}
+	} else if query.Read != nil {
+		plan, err = createTableRelation(query.Read.Table)
+		if err != nil {
+			return nil, err
 		}
 	} else {
 		table := engine.NewVirtualTable(nil)
 		plan = engine.NewReadOperation(table)
 	}
 
+	sc := &scope{outer: outer, input: plan}
+	if query.Read != nil {
+		sc.tables = tableQualifiers(query.Read.Table)
+	}
+
 	if query.Filter != nil {
-		expr, err := CreateLogicalExpr(query.Filter.Expr)
+		expr, err := createExpr(query.Filter.Expr, sc)
 		if err != nil {
 			return nil, fmt.Errorf("parse: failed to plan SQL query: %w", err)
 		}
 		plan = engine.NewSelectionOperation(plan, expr)
+		sc.input = plan
+	}
+
+	// An ORDER BY that refers to columns of the input which are not in the
+	// SELECT list sorts the input before it is projected.
+	sortInput := query.OrderBy != nil && query.Projection != nil && query.Set == nil &&
+		sortsByInputColumn(query.OrderBy.Items, query.Projection.Exprs)
+	if sortInput {
+		fields := make([]engine.SortField, len(query.OrderBy.Items))
+		for i, item := range query.OrderBy.Items {
+			f, err := createInputSortField(item, query.Projection.Exprs, sc)
+			if err != nil {
+				return nil, fmt.Errorf("parse: failed to plan SQL query: %w", err)
+			}
+			fields[i] = f
+		}
+		plan = engine.NewSortOperation(plan, fields)
+		sc.input = plan
 	}
 
 	if query.Projection != nil {
 		exprs := make([]engine.Expr, len(query.Projection.Exprs))
 		for i, expr := range query.Projection.Exprs {
-			e, err := CreateLogicalExpr(expr)
+			e, err := createExpr(expr, sc)
 			if err != nil {
 				return nil, fmt.Errorf("parse: failed to plan SQL query: %w", err)
 			}
 			exprs[i] = e
 		}
 		plan = engine.NewProjectionOperation(plan, exprs)
+		sc.input = plan
+	}
+
+	if query.OrderBy != nil && !sortInput {
+		var selectList []parse.SqlExpr
+		if query.Projection != nil {
+			selectList = query.Projection.Exprs
+		}
+		if query.Set != nil {
+			selectList = primarySelectList(query.Set)
+		}
+
+		fields := make([]engine.SortField, len(query.OrderBy.Items))
+		for i, item := range query.OrderBy.Items {
+			f, err := createSortField(item, selectList, sc)
+			if err != nil {
+				return nil, fmt.Errorf("parse: failed to plan SQL query: %w", err)
+			}
+			fields[i] = f
+		}
+		plan = engine.NewSortOperation(plan, fields)
+	}
+
+	if query.Limit != nil || query.Offset != nil {
+		var offset, count int64 = 0, -1
+		if query.Offset != nil {
+			offset = int64(query.Offset.Offset)
+		}
+		if query.Limit != nil {
+			count = int64(query.Limit.Count)
+		}
+		plan = engine.NewFetchOperation(plan, offset, count)
+	}
+
+	if query.Insert != nil || query.Create != nil {
+		if query.Set == nil && query.Read == nil && query.Projection == nil {
+			return nil, fmt.Errorf("plan: expected query to provide records to write")
+		}
+	}
+
+	if query.Insert != nil {
+		table := engine.NewNamedTable(query.Insert.Table, nil)
+		plan = engine.NewWriteOperation(plan, table, engine.WriteOpInsert, engine.WriteOutputModeNoOutput)
+	}
+
+	if query.Create != nil {
+		op := engine.DdlOpCreate
+		if query.Create.OrReplace {
+			op = engine.DdlOpCreateOrReplace
+		}
+
+		switch query.Create.Object {
+		case "TABLE":
+			if query.Create.OrReplace {
+				return nil, fmt.Errorf("plan: CREATE OR REPLACE TABLE ... AS is not supported")
+			}
+			table := engine.NewNamedTable(query.Create.Identifier, nil)
+			plan = engine.NewWriteOperation(plan, table, engine.WriteOpCTAS, engine.WriteOutputModeNoOutput)
+		case "VIEW":
+			plan = engine.NewViewDdlOperation(query.Create.Identifier, op, plan)
+		default:
+			return nil, fmt.Errorf("plan: unrecognized object type for CREATE: %s", query.Create.Object)
+		}
 	}
 
 	return plan, nil
 }
 
+// scope is a query being planned, whose tables may be referenced by the
+// subqueries within it.
+type scope struct {
+	outer *scope
+	// tables are the qualifiers of the tables in the FROM clause
+	tables []string
+	// input is the relation that the expressions of the query are planned against
+	input engine.Relation
+}
+
+// resolveOuter finds the enclosing query with a table identified by qualifier,
+// returning its input and the number of queries between them. Tables of the
+// current query take precedence over those of enclosing queries.
+func (sc *scope) resolveOuter(qualifier string) (engine.Relation, int, bool) {
+	if sc == nil || sc.hasTable(qualifier) {
+		return nil, 0, false
+	}
+
+	stepsOut := 1
+	for outer := sc.outer; outer != nil; outer = outer.outer {
+		if outer.hasTable(qualifier) {
+			return outer.input, stepsOut, true
+		}
+		stepsOut++
+	}
+
+	return nil, 0, false
+}
+
+// hasTable reports whether qualifier identifies a table of the query, either
+// by its full name or by a suffix of it such as the table name alone.
+func (sc *scope) hasTable(qualifier string) bool {
+	for _, table := range sc.tables {
+		if table == qualifier || strings.HasSuffix(table, "."+qualifier) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tableQualifiers lists the qualifiers that columns of the tables in a FROM
+// clause may be referenced by.
+func tableQualifiers(table parse.SqlExpr) []string {
+	switch t := table.(type) {
+	case *parse.SqlIdentifier:
+		if t.Alias != "" {
+			return []string{t.Alias}
+		}
+		return []string{strings.Join(t.Names, ".")}
+	case *parse.SqlQuery:
+		if t.Alias != "" {
+			return []string{t.Alias}
+		}
+	case *parse.SqlJoin:
+		return append(tableQualifiers(t.Left), tableQualifiers(t.Right)...)
+	}
+
+	return nil
+}
+
+// createTable plans a CREATE TABLE statement with column definitions.
+// Columns are nullable unless they are declared NOT NULL.
+func createTable(identifier []string, orReplace bool, columns []*parse.SqlColumnDef) (engine.Relation, error) {
+	fields := make([]bonobo.Field, len(columns))
+	for i, col := range columns {
+		typ, err := CreateType(col.Type, !col.NotNull)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = bonobo.Field{Name: col.Name, Type: typ}
+	}
+
+	op := engine.DdlOpCreate
+	if orReplace {
+		op = engine.DdlOpCreateOrReplace
+	}
+
+	return engine.NewTableDdlOperation(identifier, op, bonobo.NewSchema(fields)), nil
+}
+
+func createDrop(object string, identifier []string, ifExists bool) (engine.Relation, error) {
+	op := engine.DdlOpDrop
+	if ifExists {
+		op = engine.DdlOpDropIfExist
+	}
+
+	switch object {
+	case "TABLE":
+		return engine.NewTableDdlOperation(identifier, op, nil), nil
+	case "VIEW":
+		return engine.NewViewDdlOperation(identifier, op, nil), nil
+	default:
+		return nil, fmt.Errorf("plan: unrecognized object type for DROP: %s", object)
+	}
+}
+
+// createTableRelation plans a table expression from a FROM clause. Aliased
+// tables and subqueries are wrapped in a TableAlias so that their columns may
+// be referenced by qualified identifiers.
+func createTableRelation(table parse.SqlExpr) (engine.Relation, error) {
+	var (
+		rel   engine.Relation
+		alias string
+		err   error
+	)
+
+	switch t := table.(type) {
+	case *parse.SqlIdentifier:
+		rel = engine.NewReadOperation(engine.NewNamedTable(t.Names, nil))
+		alias = t.Alias
+	case *parse.SqlQuery:
+		rel, err = CreateLogicalPlan(t)
+		if err != nil {
+			return nil, err
+		}
+		alias = t.Alias
+	case *parse.SqlJoin:
+		return createJoin(t)
+	default:
+		return nil, fmt.Errorf("plan: unrecognized SqlExpr type for Read operation: %T", t)
+	}
+
+	if alias != "" {
+		rel = engine.NewTableAliasOperation(rel, alias)
+	}
+
+	return rel, nil
+}
+
+func createJoin(join *parse.SqlJoin) (engine.Relation, error) {
+	left, err := createTableRelation(join.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := createTableRelation(join.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	if join.Type == "CROSS" {
+		return engine.NewCrossOperation(left, right), nil
+	}
+
+	var joinType engine.JoinType
+	switch join.Type {
+	case "INNER":
+		joinType = engine.JoinTypeInner
+	case "LEFT":
+		joinType = engine.JoinTypeLeft
+	case "RIGHT":
+		joinType = engine.JoinTypeRight
+	case "FULL":
+		joinType = engine.JoinTypeOuter
+	default:
+		return nil, fmt.Errorf("plan: unrecognized join type: %s", join.Type)
+	}
+
+	switch {
+	case join.On != nil:
+		condition, err := CreateLogicalExpr(join.On)
+		if err != nil {
+			return nil, err
+		}
+
+		return engine.NewJoinOperation(left, right, joinType, condition, nil), nil
+	case len(join.Using) > 0:
+		return engine.NewJoinUsingOperation(left, right, joinType, join.Using), nil
+	default:
+		return nil, fmt.Errorf("plan: %s JOIN requires ON or USING", join.Type)
+	}
+}
+
+// createSetOperation plans a set operation between queries. A chain of the
+// same operation, such as a UNION ALL b UNION ALL c, is planned as a single
+// Set with an input for each query.
+func createSetOperation(set *parse.SqlSetOperation, outer *scope) (engine.Relation, error) {
+	var op engine.SetOp
+	switch {
+	case set.Op == "UNION" && set.All:
+		op = engine.SetOpUnionAll
+	case set.Op == "UNION":
+		op = engine.SetOpUnionDistinct
+	case set.Op == "INTERSECT" && set.All:
+		op = engine.SetOpIntersectionMultisetAll
+	case set.Op == "INTERSECT":
+		op = engine.SetOpIntersectionMultiset
+	case set.Op == "EXCEPT" && set.All:
+		op = engine.SetOpMinusPrimaryAll
+	case set.Op == "EXCEPT":
+		op = engine.SetOpMinusPrimary
+	default:
+		return nil, fmt.Errorf("plan: unrecognized set operation: %s", set.Op)
+	}
+
+	queries := []*parse.SqlQuery{set.Right}
+	left := set.Left
+	for isSameSetOperation(left, set) {
+		queries = append(queries, left.Set.Right)
+		left = left.Set.Left
+	}
+	queries = append(queries, left)
+	slices.Reverse(queries)
+
+	inputs := make([]engine.Relation, len(queries))
+	for i, query := range queries {
+		input, err := createPlan(query, outer)
+		if err != nil {
+			return nil, err
+		}
+		inputs[i] = input
+	}
+
+	return engine.NewSetOperation(inputs, op), nil
+}
+
+// isSameSetOperation reports whether query only applies the same operation
+// as set, so that its inputs may be combined with those of set.
+func isSameSetOperation(query *parse.SqlQuery, set *parse.SqlSetOperation) bool {
+	return query.Set != nil &&
+		query.Set.Op == set.Op &&
+		query.Set.All == set.All &&
+		query.Alias == "" &&
+		query.OrderBy == nil &&
+		query.Limit == nil &&
+		query.Offset == nil
+}
+
+// primarySelectList finds the SELECT list of the leftmost query in a set
+// operation, which determines the names of the output fields.
+func primarySelectList(set *parse.SqlSetOperation) []parse.SqlExpr {
+	query := set.Left
+	for query.Set != nil {
+		query = query.Set.Left
+	}
+
+	if query.Projection == nil {
+		return nil
+	}
+	return query.Projection.Exprs
+}
+
+// createSortField plans an ORDER BY item against the output of the SELECT list.
+// Integer literals refer to the position of an expression in the SELECT list,
+// starting from 1, and identifiers may refer to its aliases.
+func createSortField(item *parse.SqlSortItem, selectList []parse.SqlExpr, sc *scope) (engine.SortField, error) {
+	var (
+		expr engine.Expr
+		err  error
+	)
+
+	if ordinal, ok := item.Expr.(*parse.SqlIntLiteral); ok {
+		if selectList != nil && (ordinal.Value < 1 || ordinal.Value > len(selectList)) {
+			return engine.SortField{}, fmt.Errorf("ORDER BY position %d is not in select list", ordinal.Value)
+		}
+		expr = engine.NewColumnIndexExpr(ordinal.Value - 1)
+	} else {
+		expr, err = createExpr(item.Expr, sc)
+		if err != nil {
+			return engine.SortField{}, err
+		}
+	}
+
+	return engine.NewSortField(expr, sortDirection(item)), nil
+}
+
+// createInputSortField plans an ORDER BY item against the input of the SELECT
+// list, for queries that sort by columns that are not selected. Positions and
+// aliases are replaced by the expressions of the SELECT list they refer to.
+func createInputSortField(item *parse.SqlSortItem, selectList []parse.SqlExpr, sc *scope) (engine.SortField, error) {
+	key := item.Expr
+	switch e := key.(type) {
+	case *parse.SqlIntLiteral:
+		if e.Value < 1 || e.Value > len(selectList) {
+			return engine.SortField{}, fmt.Errorf("ORDER BY position %d is not in select list", e.Value)
+		}
+		key = selectList[e.Value-1]
+	case *parse.SqlIdentifier:
+		if len(e.Names) == 1 {
+			for _, expr := range selectList {
+				if name, aliased := selectOutputName(expr); aliased && name == e.Names[0] {
+					key = expr
+					break
+				}
+			}
+		}
+	}
+
+	// The alias only names the output of the SELECT list
+	switch e := key.(type) {
+	case *parse.SqlAlias:
+		key = e.Input
+	case *parse.SqlIdentifier:
+		key = &parse.SqlIdentifier{Names: e.Names}
+	}
+
+	expr, err := createExpr(key, sc)
+	if err != nil {
+		return engine.SortField{}, err
+	}
+
+	return engine.NewSortField(expr, sortDirection(item)), nil
+}
+
+// sortDirection is the direction of an ORDER BY item. Unless specified, nulls
+// are considered larger than any other value.
+func sortDirection(item *parse.SqlSortItem) engine.SortDirection {
+	switch {
+	case item.Direction == "DESC" && item.Nulls == "LAST":
+		return engine.SortDescNullsLast
+	case item.Direction == "DESC":
+		return engine.SortDescNullsFirst
+	case item.Nulls == "FIRST":
+		return engine.SortAscNullsFirst
+	default:
+		return engine.SortAscNullsLast
+	}
+}
+
+// sortsByInputColumn reports whether any ORDER BY item refers to a column
+// that is not an output of the SELECT list. Columns are matched by name, since
+// the schema of the input is not known until the plan is bound to a catalog.
+func sortsByInputColumn(items []*parse.SqlSortItem, selectList []parse.SqlExpr) bool {
+	outputs := make(map[string]bool, len(selectList))
+	for _, expr := range selectList {
+		if name, _ := selectOutputName(expr); name != "" {
+			outputs[name] = true
+		}
+	}
+
+	var visit func(node parse.SqlNode) bool
+	visit = func(node parse.SqlNode) bool {
+		switch n := node.(type) {
+		case *parse.SqlQuery:
+			// Columns of subqueries are resolved against their own input
+			return false
+		case *parse.SqlIdentifier:
+			return !slices.ContainsFunc(n.Names, func(name string) bool { return outputs[name] })
+		}
+		return slices.ContainsFunc(node.Children(), visit)
+	}
+
+	for _, item := range items {
+		if _, ok := item.Expr.(*parse.SqlIntLiteral); ok {
+			continue
+		}
+		if visit(item.Expr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selectOutputName is the name of the output of an expression in a SELECT
+// list, if it is known before planning, and whether it is an alias.
+func selectOutputName(expr parse.SqlExpr) (string, bool) {
+	switch e := expr.(type) {
+	case *parse.SqlAlias:
+		return e.Name, true
+	case *parse.SqlIdentifier:
+		if e.Alias != "" {
+			return e.Alias, true
+		}
+		return e.Names[len(e.Names)-1], false
+	}
+
+	return "", false
+}
+
+// createWindowFunction plans a call to a function with an OVER clause. A
+// frame without an end extends to the current row.
+func createWindowFunction(expr *parse.SqlFunctionExpr, sc *scope) (engine.Expr, error) {
+	functionID, err := resolveWindowFunctionID(expr.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []engine.Expr
+	if len(expr.Args) > 0 {
+		args, err = createLogicalExprs(expr.Args, sc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fn := engine.NewWindowFunctionExpr(functionID.URI, functionID.Name, args...)
+
+	if len(expr.Over.PartitionBy) > 0 {
+		partitions, err := createLogicalExprs(expr.Over.PartitionBy, sc)
+		if err != nil {
+			return nil, err
+		}
+		fn = fn.WithPartitions(partitions...)
+	}
+
+	if len(expr.Over.OrderBy) > 0 {
+		// Unlike in the ORDER BY of a query, an integer in OVER is a constant
+		// rather than the position of a column
+		sorts := make([]engine.SortField, len(expr.Over.OrderBy))
+		for i, item := range expr.Over.OrderBy {
+			key, err := createExpr(item.Expr, sc)
+			if err != nil {
+				return nil, err
+			}
+			sorts[i] = engine.NewSortField(key, sortDirection(item))
+		}
+		fn = fn.WithSorts(sorts...)
+	}
+
+	if frame := expr.Over.Frame; frame != nil {
+		typ := engine.BoundsTypeRows
+		if frame.Units == "RANGE" {
+			typ = engine.BoundsTypeRange
+		}
+
+		// The parser has already rejected frames that start after they end
+		end := frame.End
+		if end == nil {
+			end = &parse.SqlFrameBound{Kind: "CURRENT ROW"}
+		}
+
+		fn = fn.WithBounds(typ, createWindowBound(frame.Start), createWindowBound(end))
+	}
+
+	return fn, nil
+}
+
+func createWindowBound(bound *parse.SqlFrameBound) engine.WindowBound {
+	switch bound.Kind {
+	case "PRECEDING":
+		return engine.PrecedingBound(int64(bound.Offset))
+	case "FOLLOWING":
+		return engine.FollowingBound(int64(bound.Offset))
+	case "CURRENT ROW":
+		return engine.CurrentRowBound()
+	default:
+		return engine.UnboundedBound()
+	}
+}
+
+// resolveWindowFunctionID resolves the functions that may be called with an
+// OVER clause.
+func resolveWindowFunctionID(name string) (extensions.ID, error) {
+	name = strings.ToLower(name)
+	switch name {
+	case "row_number", "rank", "dense_rank", "percent_rank", "cume_dist", "ntile",
+		"first_value", "last_value", "nth_value", "lead", "lag",
+		"sum", "avg", "min", "max":
+		return extensions.ID{URI: FunctionsArithmeticURI, Name: name}, nil
+	default:
+		return extensions.ID{}, fmt.Errorf("cannot resolve window function: %s", name)
+	}
+}
+
 // TODO: Might need to know args to pick function if operator is overloaded
 func resolveFunctionID(op string) (extensions.ID, error) {
 	switch op {
 	case "+":
 		return Add, nil
+	case "=":
+		return Equal, nil
+	case "!=":
+		return NotEqual, nil
+	case "<":
+		return LessThan, nil
+	case ">":
+		return GreaterThan, nil
+	case "<=":
+		return LessThanOrEqual, nil
+	case ">=":
+		return GreaterThanOrEqual, nil
+	case "AND":
+		return And, nil
+	case "OR":
+		return Or, nil
 	default:
 		return extensions.ID{}, fmt.Errorf("cannot resolve function for operator: %s", op)
 	}
 }
 
+// FunctionsArithmeticURI is the simple extension declaring the arithmetic
+// functions, which include the window functions.
+const FunctionsArithmeticURI = "https://github.com/substrait-io/substrait/blob/main/extensions/functions_arithmetic.yaml"
+
 var Add = extensions.ID{
-	URI:  "https://github.com/substrait-io/substrait/blob/main/extensions/functions_arithmetic.yaml",
+	URI:  FunctionsArithmeticURI,
 	Name: "add",
 }
+
+var (
+	Equal = extensions.ID{
+		URI:  "https://github.com/substrait-io/substrait/blob/main/extensions/functions_comparison.yaml",
+		Name: "equal",
+	}
+	NotEqual = extensions.ID{
+		URI:  "https://github.com/substrait-io/substrait/blob/main/extensions/functions_comparison.yaml",
+		Name: "not_equal",
+	}
+	LessThan = extensions.ID{
+		URI:  "https://github.com/substrait-io/substrait/blob/main/extensions/functions_comparison.yaml",
+		Name: "lt",
+	}
+	GreaterThan = extensions.ID{
+		URI:  "https://github.com/substrait-io/substrait/blob/main/extensions/functions_comparison.yaml",
+		Name: "gt",
+	}
+	LessThanOrEqual = extensions.ID{
+		URI:  "https://github.com/substrait-io/substrait/blob/main/extensions/functions_comparison.yaml",
+		Name: "lte",
+	}
+	GreaterThanOrEqual = extensions.ID{
+		URI:  "https://github.com/substrait-io/substrait/blob/main/extensions/functions_comparison.yaml",
+		Name: "gte",
+	}
+	And = extensions.ID{
+		URI:  "https://github.com/substrait-io/substrait/blob/main/extensions/functions_boolean.yaml",
+		Name: "and",
+	}
+	Or = extensions.ID{
+		URI:  "https://github.com/substrait-io/substrait/blob/main/extensions/functions_boolean.yaml",
+		Name: "or",
+	}
+	Not = extensions.ID{
+		URI:  "https://github.com/substrait-io/substrait/blob/main/extensions/functions_boolean.yaml",
+		Name: "not",
+	}
+)
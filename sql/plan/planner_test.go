@@ -3,6 +3,7 @@ package plan_test
 import (
 	"testing"
 
+	"github.com/joellubi/bonobo"
 	"github.com/joellubi/bonobo/df"
 	"github.com/joellubi/bonobo/engine"
 	"github.com/joellubi/bonobo/sql/parse"
@@ -105,6 +106,613 @@ var testcases = []struct {
 			Select(df.Col("a")).
 			LogicalPlan(),
 	},
+	{
+		Name: "select_from_named_table_limit",
+		Input: &parse.SqlQuery{
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+			Projection: parse.SqlSelectRelation(
+				[]parse.SqlExpr{
+					&parse.SqlIdentifier{Names: []string{"a"}},
+				},
+			),
+			Limit: parse.SqlLimitRelation(10),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Select(df.Col("a")).
+			Limit(0, 10).
+			LogicalPlan(),
+	},
+	{
+		Name: "select_from_named_table_offset",
+		Input: &parse.SqlQuery{
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+			Projection: parse.SqlSelectRelation(
+				[]parse.SqlExpr{
+					&parse.SqlIdentifier{Names: []string{"a"}},
+				},
+			),
+			Offset: parse.SqlOffsetRelation(5),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Select(df.Col("a")).
+			Limit(5, -1).
+			LogicalPlan(),
+	},
+	{
+		Name: "select_from_named_table_order_by",
+		Input: &parse.SqlQuery{
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"c"}}),
+			Projection: parse.SqlSelectRelation(
+				[]parse.SqlExpr{
+					&parse.SqlIdentifier{Names: []string{"a"}},
+					&parse.SqlIdentifier{Names: []string{"b"}, Alias: "d"},
+				},
+			),
+			OrderBy: parse.SqlOrderByRelation(
+				[]*parse.SqlSortItem{
+					{Expr: &parse.SqlIntLiteral{Value: 2}, Direction: "DESC"},
+					{Expr: &parse.SqlIdentifier{Names: []string{"d"}}, Nulls: "FIRST"},
+					{Expr: &parse.SqlIdentifier{Names: []string{"a"}}, Direction: "DESC", Nulls: "LAST"},
+				},
+			),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"c"}, nil)).
+			Select(
+				df.Col("a"),
+				df.As(df.Col("b"), "d"),
+			).
+			Sort(
+				engine.NewSortField(df.ColIdx(1), engine.SortDescNullsFirst),
+				engine.NewSortField(df.Col("d"), engine.SortAscNullsFirst),
+				engine.NewSortField(df.Col("a"), engine.SortDescNullsLast),
+			).
+			LogicalPlan(),
+	},
+	{
+		Name: "select_from_aliased_join_on",
+		Input: &parse.SqlQuery{
+			Read: parse.SqlFromRelation(
+				&parse.SqlJoin{
+					Left:  &parse.SqlIdentifier{Names: []string{"a"}, Alias: "x"},
+					Right: &parse.SqlIdentifier{Names: []string{"s", "b"}},
+					Type:  "LEFT",
+					On: &parse.SqlBinaryExpr{
+						Left:  &parse.SqlIdentifier{Names: []string{"x", "id"}},
+						Op:    "=",
+						Right: &parse.SqlIdentifier{Names: []string{"b", "id"}},
+					},
+				},
+			),
+			Projection: parse.SqlSelectRelation(
+				[]parse.SqlExpr{
+					&parse.SqlIdentifier{Names: []string{"x", "c"}},
+				},
+			),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"a"}, nil)).
+			Alias("x").
+			Join(
+				df.QueryContext().Read(engine.NewNamedTable([]string{"s", "b"}, nil)),
+				engine.JoinTypeLeft,
+				engine.NewFunctionExpr(plan.Equal.URI, plan.Equal.Name, df.QCol("x", "id"), df.QCol("b", "id")),
+				nil,
+			).
+			Select(df.QCol("x", "c")).
+			LogicalPlan(),
+	},
+	{
+		Name: "select_from_join_using",
+		Input: &parse.SqlQuery{
+			Read: parse.SqlFromRelation(
+				&parse.SqlJoin{
+					Left:  &parse.SqlIdentifier{Names: []string{"a"}},
+					Right: &parse.SqlIdentifier{Names: []string{"b"}, Alias: "y"},
+					Type:  "FULL",
+					Using: []string{"c", "d"},
+				},
+			),
+			Projection: parse.SqlSelectRelation(
+				[]parse.SqlExpr{
+					&parse.SqlIdentifier{Names: []string{"c"}},
+				},
+			),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"a"}, nil)).
+			JoinUsing(
+				df.QueryContext().Read(engine.NewNamedTable([]string{"b"}, nil)).Alias("y"),
+				engine.JoinTypeOuter,
+				"c", "d",
+			).
+			Select(df.Col("c")).
+			LogicalPlan(),
+	},
+	{
+		Name: "select_from_cross_join",
+		Input: &parse.SqlQuery{
+			Read: parse.SqlFromRelation(
+				&parse.SqlJoin{
+					Left:  &parse.SqlIdentifier{Names: []string{"a"}},
+					Right: &parse.SqlIdentifier{Names: []string{"b"}},
+					Type:  "CROSS",
+				},
+			),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"a"}, nil)).
+			CrossJoin(df.QueryContext().Read(engine.NewNamedTable([]string{"b"}, nil))).
+			LogicalPlan(),
+	},
+	{
+		Name: "union_all_flattened",
+		Input: &parse.SqlQuery{
+			Set: &parse.SqlSetOperation{
+				Op:  "UNION",
+				All: true,
+				Left: &parse.SqlQuery{
+					Set: &parse.SqlSetOperation{
+						Op:    "UNION",
+						All:   true,
+						Left:  &parse.SqlQuery{Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"a"}})},
+						Right: &parse.SqlQuery{Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}})},
+					},
+				},
+				Right: &parse.SqlQuery{Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"c"}})},
+			},
+			Limit: parse.SqlLimitRelation(10),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"a"}, nil)).
+			Union(
+				df.QueryContext().Read(engine.NewNamedTable([]string{"b"}, nil)),
+				df.QueryContext().Read(engine.NewNamedTable([]string{"c"}, nil)),
+			).
+			Limit(0, 10).
+			LogicalPlan(),
+	},
+	{
+		Name: "union_distinct_except_all",
+		Input: &parse.SqlQuery{
+			Set: &parse.SqlSetOperation{
+				Op: "UNION",
+				Left: &parse.SqlQuery{
+					Set: &parse.SqlSetOperation{
+						Op:    "EXCEPT",
+						All:   true,
+						Left:  &parse.SqlQuery{Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"a"}})},
+						Right: &parse.SqlQuery{Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}})},
+					},
+				},
+				Right: &parse.SqlQuery{Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"c"}})},
+			},
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"a"}, nil)).
+			SetOperation(engine.SetOpMinusPrimaryAll, df.QueryContext().Read(engine.NewNamedTable([]string{"b"}, nil))).
+			SetOperation(engine.SetOpUnionDistinct, df.QueryContext().Read(engine.NewNamedTable([]string{"c"}, nil))).
+			LogicalPlan(),
+	},
+	{
+		Name: "insert_into_select",
+		Input: &parse.SqlQuery{
+			Insert: parse.SqlInsertRelation([]string{"a"}),
+			Read:   parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+			Projection: parse.SqlSelectRelation(
+				[]parse.SqlExpr{
+					&parse.SqlIdentifier{Names: []string{"c"}},
+				},
+			),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Select(df.Col("c")).
+			Write(engine.NewNamedTable([]string{"a"}, nil), engine.WriteOpInsert, engine.WriteOutputModeNoOutput).
+			LogicalPlan(),
+	},
+	{
+		Name: "create_table_as_select",
+		Input: &parse.SqlQuery{
+			Create: parse.SqlCreateRelation("TABLE", []string{"a"}, false, nil),
+			Read:   parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Write(engine.NewNamedTable([]string{"a"}, nil), engine.WriteOpCTAS, engine.WriteOutputModeNoOutput).
+			LogicalPlan(),
+	},
+	{
+		Name: "create_table_columns",
+		Input: &parse.SqlQuery{
+			Create: parse.SqlCreateRelation(
+				"TABLE",
+				[]string{"a"},
+				false,
+				[]*parse.SqlColumnDef{
+					{Name: "b", Type: &parse.SqlType{Name: "BIGINT"}, NotNull: true},
+					{Name: "c", Type: &parse.SqlType{Name: "DECIMAL", Params: []int{10, 2}}},
+				},
+			),
+		},
+		Expected: engine.NewTableDdlOperation(
+			[]string{"a"},
+			engine.DdlOpCreate,
+			bonobo.NewSchema([]bonobo.Field{
+				{Name: "b", Type: bonobo.Types.Int64Type(false)},
+				{Name: "c", Type: bonobo.Types.DecimalType(10, 2, true)},
+			}),
+		),
+	},
+	{
+		Name:     "drop_view",
+		Input:    &parse.SqlQuery{Drop: parse.SqlDropRelation("VIEW", []string{"a"}, false)},
+		Expected: engine.NewViewDdlOperation([]string{"a"}, engine.DdlOpDrop, nil),
+	},
+	{
+		Name: "cast_and_try_cast",
+		Input: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlCast{
+					Expr: &parse.SqlIdentifier{Names: []string{"a"}},
+					Type: &parse.SqlType{Name: "DATE"},
+				},
+				&parse.SqlCast{
+					Expr: &parse.SqlStringLiteral{Value: "1.5"},
+					Type: &parse.SqlType{Name: "DECIMAL", Params: []int{10, 2}},
+					Try:  true,
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Select(
+				df.Cast(df.Col("a"), bonobo.Types.DateType(true), engine.CastFailureThrowException),
+				df.Cast(df.Lit("1.5"), bonobo.Types.DecimalType(10, 2, true), engine.CastFailureReturnNull),
+			).
+			LogicalPlan(),
+	},
+	{
+		Name: "searched_case",
+		Input: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlCase{
+					Whens: []*parse.SqlWhen{
+						{
+							Cond:   &parse.SqlIdentifier{Names: []string{"a"}},
+							Result: &parse.SqlStringLiteral{Value: "yes"},
+						},
+					},
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Select(
+				df.IfThen([]engine.IfClause{{If: df.Col("a"), Then: df.Lit("yes")}}, nil),
+			).
+			LogicalPlan(),
+	},
+	{
+		Name: "simple_case_literals",
+		Input: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlCase{
+					Operand: &parse.SqlIdentifier{Names: []string{"a"}},
+					Whens: []*parse.SqlWhen{
+						{Cond: &parse.SqlIntLiteral{Value: 1}, Result: &parse.SqlStringLiteral{Value: "one"}},
+					},
+					Else: &parse.SqlStringLiteral{Value: "other"},
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Select(
+				df.Switch(
+					df.Col("a"),
+					[]engine.SwitchCase{{Value: df.Lit(1), Then: df.Lit("one")}},
+					df.Lit("other"),
+				),
+			).
+			LogicalPlan(),
+	},
+	{
+		Name: "simple_case_expression_values",
+		Input: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlCase{
+					Operand: &parse.SqlIdentifier{Names: []string{"a"}},
+					Whens: []*parse.SqlWhen{
+						{Cond: &parse.SqlIdentifier{Names: []string{"c"}}, Result: &parse.SqlStringLiteral{Value: "same"}},
+					},
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Select(
+				df.IfThen(
+					[]engine.IfClause{
+						{
+							If:   engine.NewFunctionExpr(plan.Equal.URI, plan.Equal.Name, df.Col("a"), df.Col("c")),
+							Then: df.Lit("same"),
+						},
+					},
+					nil,
+				),
+			).
+			LogicalPlan(),
+	},
+	{
+		Name: "in_list_and_not_in_list",
+		Input: &parse.SqlQuery{
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+			Filter: parse.SqlWhereRelation(
+				&parse.SqlBinaryExpr{
+					Left: &parse.SqlInList{
+						Expr: &parse.SqlIdentifier{Names: []string{"a"}},
+						List: []parse.SqlExpr{&parse.SqlIntLiteral{Value: 1}, &parse.SqlIntLiteral{Value: 2}},
+					},
+					Op: "AND",
+					Right: &parse.SqlInList{
+						Expr: &parse.SqlIdentifier{Names: []string{"c"}},
+						List: []parse.SqlExpr{&parse.SqlStringLiteral{Value: "x"}},
+						Not:  true,
+					},
+				},
+			),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Filter(
+				engine.NewFunctionExpr(
+					plan.And.URI,
+					plan.And.Name,
+					df.In(df.Col("a"), df.Lit(1), df.Lit(2)),
+					engine.NewFunctionExpr(plan.Not.URI, plan.Not.Name, df.In(df.Col("c"), df.Lit("x"))),
+				),
+			).
+			LogicalPlan(),
+	},
+	{
+		Name: "multi_in_list",
+		Input: &parse.SqlQuery{
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+			Filter: parse.SqlWhereRelation(
+				&parse.SqlInList{
+					Expr: &parse.SqlTuple{Exprs: []parse.SqlExpr{
+						&parse.SqlIdentifier{Names: []string{"a"}},
+						&parse.SqlIdentifier{Names: []string{"c"}},
+					}},
+					List: []parse.SqlExpr{
+						&parse.SqlTuple{Exprs: []parse.SqlExpr{&parse.SqlIntLiteral{Value: 1}, &parse.SqlStringLiteral{Value: "x"}}},
+					},
+				},
+			),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Filter(
+				df.MultiIn(
+					[]engine.Expr{df.Col("a"), df.Col("c")},
+					[]engine.Expr{df.Lit(1), df.Lit("x")},
+				),
+			).
+			LogicalPlan(),
+	},
+	{
+		Name: "correlated_exists",
+		Input: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}),
+			Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}, Alias: "t"}),
+			Filter: parse.SqlWhereRelation(
+				&parse.SqlExists{
+					Query: &parse.SqlQuery{
+						Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"c"}}}),
+						Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+						Filter: parse.SqlWhereRelation(
+							&parse.SqlBinaryExpr{
+								Left:  &parse.SqlIdentifier{Names: []string{"d", "c"}},
+								Op:    "=",
+								Right: &parse.SqlIdentifier{Names: []string{"t", "a"}},
+							},
+						),
+					},
+				},
+			),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Alias("t").
+			Filter(
+				df.Exists(
+					df.QueryContext().
+						Read(engine.NewNamedTable([]string{"d"}, nil)).
+						Filter(
+							engine.NewFunctionExpr(
+								plan.Equal.URI, plan.Equal.Name,
+								df.QCol("d", "c"),
+								df.Outer(
+									df.QueryContext().Read(engine.NewNamedTable([]string{"b"}, nil)).Alias("t").LogicalPlan(),
+									1,
+									df.QCol("t", "a"),
+								),
+							),
+						).
+						Select(df.Col("c")),
+				),
+			).
+			Select(df.Col("a")).
+			LogicalPlan(),
+	},
+	{
+		Name: "scalar_and_set_comparison_subqueries",
+		Input: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlQuery{
+					Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"c"}}}),
+					Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+			Filter: parse.SqlWhereRelation(
+				&parse.SqlSetComparison{
+					Left:       &parse.SqlIdentifier{Names: []string{"a"}},
+					Op:         "<=",
+					Quantifier: "ALL",
+					Query: &parse.SqlQuery{
+						Projection: parse.SqlSelectRelation([]parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"c"}}}),
+						Read:       parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+					},
+				},
+			),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Filter(
+				engine.NewSetComparisonSubqueryExpr(
+					df.Col("a"),
+					engine.ComparisonOpLe,
+					engine.ReductionOpAll,
+					df.QueryContext().Read(engine.NewNamedTable([]string{"d"}, nil)).Select(df.Col("c")).LogicalPlan(),
+				),
+			).
+			Select(
+				df.Scalar(df.QueryContext().Read(engine.NewNamedTable([]string{"d"}, nil)).Select(df.Col("c"))),
+			).
+			LogicalPlan(),
+	},
+	{
+		Name: "nested_constructors",
+		Input: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlArray{Elems: []parse.SqlExpr{&parse.SqlIntLiteral{Value: 1}, &parse.SqlIdentifier{Names: []string{"a"}}}},
+				&parse.SqlStruct{Fields: []parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}, &parse.SqlStringLiteral{Value: "x"}}},
+				&parse.SqlMap{
+					Keys:   []parse.SqlExpr{&parse.SqlStringLiteral{Value: "k"}},
+					Values: []parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}},
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Select(
+				df.List(df.Lit(1), df.Col("a")),
+				df.Struct(df.Col("a"), df.Lit("x")),
+				df.Map(engine.MapEntry{Key: df.Lit("k"), Value: df.Col("a")}),
+			).
+			LogicalPlan(),
+	},
+	{
+		Name: "subscripts_and_nested_outer_reference",
+		Input: &parse.SqlQuery{
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}, Alias: "t"}),
+			Filter: parse.SqlWhereRelation(
+				&parse.SqlExists{
+					Query: &parse.SqlQuery{
+						Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+							&parse.SqlSubscript{
+								Expr:  &parse.SqlIdentifier{Names: []string{"a", "c"}},
+								Index: &parse.SqlIntLiteral{Value: 2},
+							},
+							&parse.SqlSubscript{
+								Expr:  &parse.SqlIdentifier{Names: []string{"t", "m", "x"}},
+								Index: &parse.SqlStringLiteral{Value: "k"},
+							},
+						}),
+						Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"d"}}),
+					},
+				},
+			),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Alias("t").
+			Filter(
+				df.Exists(
+					df.QueryContext().
+						Read(engine.NewNamedTable([]string{"d"}, nil)).
+						Select(
+							df.Path(df.QCol("a", "c"), engine.SubscriptSegment(df.Lit(2))),
+							df.Path(
+								df.Outer(
+									df.QueryContext().Read(engine.NewNamedTable([]string{"b"}, nil)).Alias("t").LogicalPlan(),
+									1,
+									df.QCol("t", "m"),
+								),
+								engine.NamedStructFieldSegment("x"),
+								engine.SubscriptSegment(df.Lit("k")),
+							),
+						),
+				),
+			).
+			LogicalPlan(),
+	},
+	{
+		Name: "window_functions",
+		Input: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlFunctionExpr{
+					Name: "RANK",
+					Over: &parse.SqlWindow{
+						PartitionBy: []parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}},
+						OrderBy:     []*parse.SqlSortItem{{Expr: &parse.SqlIdentifier{Names: []string{"c"}}, Direction: "DESC"}},
+					},
+				},
+				&parse.SqlFunctionExpr{
+					Name: "sum",
+					Args: []parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"c"}}},
+					Over: &parse.SqlWindow{
+						OrderBy: []*parse.SqlSortItem{{Expr: &parse.SqlIdentifier{Names: []string{"a"}}}},
+						Frame: &parse.SqlWindowFrame{
+							Units: "ROWS",
+							Start: &parse.SqlFrameBound{Kind: "PRECEDING", Offset: 2},
+						},
+					},
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Select(
+				df.Rank().
+					WithPartitions(df.Col("a")).
+					WithSorts(engine.NewSortField(df.Col("c"), engine.SortDescNullsFirst)),
+				engine.NewWindowFunctionExpr(plan.FunctionsArithmeticURI, "sum", df.Col("c")).
+					WithSorts(engine.NewSortField(df.Col("a"), engine.SortAscNullsLast)).
+					WithBounds(engine.BoundsTypeRows, engine.PrecedingBound(2), engine.CurrentRowBound()),
+			).
+			LogicalPlan(),
+	},
+	{
+		Name: "window_order_by_constant",
+		Input: &parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlFunctionExpr{
+					Name: "rank",
+					Over: &parse.SqlWindow{
+						OrderBy: []*parse.SqlSortItem{{Expr: &parse.SqlIntLiteral{Value: 1}}},
+					},
+				},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+		},
+		Expected: df.QueryContext().
+			Read(engine.NewNamedTable([]string{"b"}, nil)).
+			Select(
+				df.Rank().WithSorts(engine.NewSortField(df.Lit(1), engine.SortAscNullsLast)),
+			).
+			LogicalPlan(),
+	},
 }
 
 func TestPlanner(t *testing.T) {
@@ -116,3 +724,39 @@ func TestPlanner(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateType(t *testing.T) {
+	for _, tc := range []struct {
+		Input    *parse.SqlType
+		Expected bonobo.Type
+	}{
+		{Input: &parse.SqlType{Name: "VARCHAR", Params: []int{20}}, Expected: bonobo.Types.VarCharType(20, true)},
+		{Input: &parse.SqlType{Name: "VARCHAR"}, Expected: bonobo.Types.StringType(true)},
+		{Input: &parse.SqlType{Name: "TEXT"}, Expected: bonobo.Types.StringType(true)},
+		{Input: &parse.SqlType{Name: "VARCHAR", Params: []int{0}}},
+		{Input: &parse.SqlType{Name: "VARCHAR", Params: []int{20, 2}}},
+	} {
+		typ, err := plan.CreateType(tc.Input, true)
+		if tc.Expected == nil {
+			require.Error(t, err, tc.Input.String())
+			continue
+		}
+		require.NoError(t, err, tc.Input.String())
+		require.Equal(t, tc.Expected, typ)
+	}
+}
+
+func TestPlannerFunctionWithoutOver(t *testing.T) {
+	for name, msg := range map[string]string{
+		"rank":  "function rank is only supported as a window function",
+		"upper": "unrecognized function: upper",
+	} {
+		_, err := plan.CreateLogicalPlan(&parse.SqlQuery{
+			Projection: parse.SqlSelectRelation([]parse.SqlExpr{
+				&parse.SqlFunctionExpr{Name: name, Args: []parse.SqlExpr{&parse.SqlIdentifier{Names: []string{"a"}}}},
+			}),
+			Read: parse.SqlFromRelation(&parse.SqlIdentifier{Names: []string{"b"}}),
+		})
+		require.ErrorContains(t, err, msg)
+	}
+}
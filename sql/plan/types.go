@@ -0,0 +1,62 @@
+package plan
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/joellubi/bonobo"
+	"github.com/joellubi/bonobo/sql/parse"
+)
+
+// CreateType resolves the name of a SQL data type to the equivalent type.
+func CreateType(typ *parse.SqlType, nullable bool) (bonobo.Type, error) {
+	expectParams := func(max int) error {
+		if len(typ.Params) > max {
+			return fmt.Errorf("plan: type %s accepts at most %d parameters, found %d", typ.Name, max, len(typ.Params))
+		}
+		return nil
+	}
+
+	switch typ.Name {
+	case "BOOLEAN", "BOOL":
+		return bonobo.Types.BooleanType(nullable), expectParams(0)
+	case "TINYINT":
+		return bonobo.Types.Int8Type(nullable), expectParams(0)
+	case "SMALLINT":
+		return bonobo.Types.Int16Type(nullable), expectParams(0)
+	case "INT", "INTEGER":
+		return bonobo.Types.Int32Type(nullable), expectParams(0)
+	case "BIGINT":
+		return bonobo.Types.Int64Type(nullable), expectParams(0)
+	case "REAL", "FLOAT":
+		return bonobo.Types.FloatType(nullable), expectParams(0)
+	case "DOUBLE":
+		return bonobo.Types.DoubleType(nullable), expectParams(0)
+	case "DATE":
+		return bonobo.Types.DateType(nullable), expectParams(0)
+	case "VARCHAR":
+		if len(typ.Params) == 0 {
+			return bonobo.Types.StringType(nullable), nil
+		}
+		if length := typ.Params[0]; length < 1 || length > math.MaxInt32 {
+			return nil, fmt.Errorf("plan: invalid %s", typ)
+		}
+		return bonobo.Types.VarCharType(int32(typ.Params[0]), nullable), expectParams(1)
+	case "TEXT", "STRING":
+		return bonobo.Types.StringType(nullable), expectParams(0)
+	case "DECIMAL", "NUMERIC":
+		precision, scale := 38, 0
+		if len(typ.Params) > 0 {
+			precision = typ.Params[0]
+		}
+		if len(typ.Params) > 1 {
+			scale = typ.Params[1]
+		}
+		if precision < 1 || precision > 38 || scale > precision {
+			return nil, fmt.Errorf("plan: invalid %s", typ)
+		}
+		return bonobo.Types.DecimalType(int32(precision), int32(scale), nullable), expectParams(2)
+	default:
+		return nil, fmt.Errorf("plan: unsupported type: %s", typ)
+	}
+}
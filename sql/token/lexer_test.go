@@ -192,6 +192,199 @@ var testcases = []struct {
 			{Name: token.EOF, Pos: 27},
 		},
 	},
+	{
+		Name:  "select_limit_offset",
+		Input: "SELECT a FROM b LIMIT 10 OFFSET 5",
+		Expected: []token.Token{
+			{Name: token.SELECT, Val: "SELECT", Pos: 0},
+			{Name: token.IDENT, Val: "a", Pos: 7},
+			{Name: token.FROM, Val: "FROM", Pos: 9},
+			{Name: token.IDENT, Val: "b", Pos: 14},
+			{Name: token.LIMIT, Val: "LIMIT", Pos: 16},
+			{Name: token.INT, Val: "10", Pos: 22},
+			{Name: token.OFFSET, Val: "OFFSET", Pos: 25},
+			{Name: token.INT, Val: "5", Pos: 32},
+			{Name: token.EOF, Pos: 33},
+		},
+	},
+	{
+		Name:  "select_order_by",
+		Input: "SELECT a FROM b ORDER BY a DESC NULLS last",
+		Expected: []token.Token{
+			{Name: token.SELECT, Val: "SELECT", Pos: 0},
+			{Name: token.IDENT, Val: "a", Pos: 7},
+			{Name: token.FROM, Val: "FROM", Pos: 9},
+			{Name: token.IDENT, Val: "b", Pos: 14},
+			{Name: token.ORDER, Val: "ORDER", Pos: 16},
+			{Name: token.BY, Val: "BY", Pos: 22},
+			{Name: token.IDENT, Val: "a", Pos: 25},
+			{Name: token.DESC, Val: "DESC", Pos: 27},
+			{Name: token.NULLS, Val: "NULLS", Pos: 32},
+			{Name: token.IDENT, Val: "last", Pos: 38},
+			{Name: token.EOF, Pos: 42},
+		},
+	},
+	{
+		Name:  "join_keywords",
+		Input: "FROM a t left outer JOIN b ON t.c = b.c",
+		Expected: []token.Token{
+			{Name: token.FROM, Val: "FROM", Pos: 0},
+			{Name: token.IDENT, Val: "a", Pos: 5},
+			{Name: token.IDENT, Val: "t", Pos: 7},
+			{Name: token.LEFT, Val: "left", Pos: 9},
+			{Name: token.OUTER, Val: "outer", Pos: 14},
+			{Name: token.JOIN, Val: "JOIN", Pos: 20},
+			{Name: token.IDENT, Val: "b", Pos: 25},
+			{Name: token.ON, Val: "ON", Pos: 27},
+			{Name: token.IDENT, Val: "t", Pos: 30},
+			{Name: token.PERIOD, Val: ".", Pos: 31},
+			{Name: token.IDENT, Val: "c", Pos: 32},
+			{Name: token.EQL, Val: "=", Pos: 34},
+			{Name: token.IDENT, Val: "b", Pos: 36},
+			{Name: token.PERIOD, Val: ".", Pos: 37},
+			{Name: token.IDENT, Val: "c", Pos: 38},
+			{Name: token.EOF, Pos: 39},
+		},
+	},
+	{
+		Name:  "set_operation_keywords",
+		Input: "SELECT a FROM b union all SELECT a FROM c",
+		Expected: []token.Token{
+			{Name: token.SELECT, Val: "SELECT", Pos: 0},
+			{Name: token.IDENT, Val: "a", Pos: 7},
+			{Name: token.FROM, Val: "FROM", Pos: 9},
+			{Name: token.IDENT, Val: "b", Pos: 14},
+			{Name: token.UNION, Val: "union", Pos: 16},
+			{Name: token.ALL, Val: "all", Pos: 22},
+			{Name: token.SELECT, Val: "SELECT", Pos: 26},
+			{Name: token.IDENT, Val: "a", Pos: 33},
+			{Name: token.FROM, Val: "FROM", Pos: 35},
+			{Name: token.IDENT, Val: "c", Pos: 40},
+			{Name: token.EOF, Pos: 41},
+		},
+	},
+	{
+		Name:  "cast_keywords_and_double_colon",
+		Input: "SELECT try_cast(a AS DATE), b::decimal(10, 2) FROM t",
+		Expected: []token.Token{
+			{Name: token.SELECT, Val: "SELECT", Pos: 0},
+			{Name: token.TRY_CAST, Val: "try_cast", Pos: 7},
+			{Name: token.LPAREN, Val: "(", Pos: 15},
+			{Name: token.IDENT, Val: "a", Pos: 16},
+			{Name: token.AS, Val: "AS", Pos: 18},
+			{Name: token.IDENT, Val: "DATE", Pos: 21},
+			{Name: token.RPAREN, Val: ")", Pos: 25},
+			{Name: token.COMMA, Val: ",", Pos: 26},
+			{Name: token.IDENT, Val: "b", Pos: 28},
+			{Name: token.DCOLON, Val: "::", Pos: 29},
+			{Name: token.IDENT, Val: "decimal", Pos: 31},
+			{Name: token.LPAREN, Val: "(", Pos: 38},
+			{Name: token.INT, Val: "10", Pos: 39},
+			{Name: token.COMMA, Val: ",", Pos: 41},
+			{Name: token.INT, Val: "2", Pos: 43},
+			{Name: token.RPAREN, Val: ")", Pos: 44},
+			{Name: token.FROM, Val: "FROM", Pos: 46},
+			{Name: token.IDENT, Val: "t", Pos: 51},
+			{Name: token.EOF, Pos: 52},
+		},
+	},
+	{
+		Name:  "case_keywords",
+		Input: "case when a then b else c end",
+		Expected: []token.Token{
+			{Name: token.CASE, Val: "case", Pos: 0},
+			{Name: token.WHEN, Val: "when", Pos: 5},
+			{Name: token.IDENT, Val: "a", Pos: 10},
+			{Name: token.THEN, Val: "then", Pos: 12},
+			{Name: token.IDENT, Val: "b", Pos: 17},
+			{Name: token.ELSE, Val: "else", Pos: 19},
+			{Name: token.IDENT, Val: "c", Pos: 24},
+			{Name: token.END, Val: "end", Pos: 26},
+			{Name: token.EOF, Pos: 29},
+		},
+	},
+	{
+		Name:  "in_and_not_in",
+		Input: "a in (1, 2) and b NOT IN ('x')",
+		Expected: []token.Token{
+			{Name: token.IDENT, Val: "a", Pos: 0},
+			{Name: token.IN, Val: "in", Pos: 2},
+			{Name: token.LPAREN, Val: "(", Pos: 5},
+			{Name: token.INT, Val: "1", Pos: 6},
+			{Name: token.COMMA, Val: ",", Pos: 7},
+			{Name: token.INT, Val: "2", Pos: 9},
+			{Name: token.RPAREN, Val: ")", Pos: 10},
+			{Name: token.AND, Val: "and", Pos: 12},
+			{Name: token.IDENT, Val: "b", Pos: 16},
+			{Name: token.NOT, Val: "NOT", Pos: 18},
+			{Name: token.IN, Val: "IN", Pos: 22},
+			{Name: token.LPAREN, Val: "(", Pos: 25},
+			{Name: token.STRING, Val: "x", Pos: 27},
+			{Name: token.RPAREN, Val: ")", Pos: 29},
+			{Name: token.EOF, Pos: 30},
+		},
+	},
+	{
+		Name:  "any_some_all",
+		Input: "a > any b < SOME c = ALL",
+		Expected: []token.Token{
+			{Name: token.IDENT, Val: "a", Pos: 0},
+			{Name: token.GTR, Val: ">", Pos: 2},
+			{Name: token.ANY, Val: "any", Pos: 4},
+			{Name: token.IDENT, Val: "b", Pos: 8},
+			{Name: token.LSS, Val: "<", Pos: 10},
+			{Name: token.SOME, Val: "SOME", Pos: 12},
+			{Name: token.IDENT, Val: "c", Pos: 17},
+			{Name: token.EQL, Val: "=", Pos: 19},
+			{Name: token.ALL, Val: "ALL", Pos: 21},
+			{Name: token.EOF, Pos: 24},
+		},
+	},
+	{
+		Name:  "nested_constructors",
+		Input: "[1, 2] row(a) Map('k', 1)",
+		Expected: []token.Token{
+			{Name: token.LBRACK, Val: "[", Pos: 0},
+			{Name: token.INT, Val: "1", Pos: 1},
+			{Name: token.COMMA, Val: ",", Pos: 2},
+			{Name: token.INT, Val: "2", Pos: 4},
+			{Name: token.RBRACK, Val: "]", Pos: 5},
+			{Name: token.ROW, Val: "row", Pos: 7},
+			{Name: token.LPAREN, Val: "(", Pos: 10},
+			{Name: token.IDENT, Val: "a", Pos: 11},
+			{Name: token.RPAREN, Val: ")", Pos: 12},
+			{Name: token.MAP, Val: "Map", Pos: 14},
+			{Name: token.LPAREN, Val: "(", Pos: 17},
+			{Name: token.STRING, Val: "k", Pos: 19},
+			{Name: token.COMMA, Val: ",", Pos: 21},
+			{Name: token.INT, Val: "1", Pos: 23},
+			{Name: token.RPAREN, Val: ")", Pos: 24},
+			{Name: token.EOF, Pos: 25},
+		},
+	},
+	{
+		Name:  "window_function",
+		Input: "rank() over (Partition by a rows between 1 preceding and current row)",
+		Expected: []token.Token{
+			{Name: token.IDENT, Val: "rank", Pos: 0},
+			{Name: token.LPAREN, Val: "(", Pos: 4},
+			{Name: token.RPAREN, Val: ")", Pos: 5},
+			{Name: token.OVER, Val: "over", Pos: 7},
+			{Name: token.LPAREN, Val: "(", Pos: 12},
+			{Name: token.PARTITION, Val: "Partition", Pos: 13},
+			{Name: token.BY, Val: "by", Pos: 23},
+			{Name: token.IDENT, Val: "a", Pos: 26},
+			{Name: token.ROWS, Val: "rows", Pos: 28},
+			{Name: token.BETWEEN, Val: "between", Pos: 33},
+			{Name: token.INT, Val: "1", Pos: 41},
+			{Name: token.IDENT, Val: "preceding", Pos: 43},
+			{Name: token.AND, Val: "and", Pos: 53},
+			{Name: token.CURRENT, Val: "current", Pos: 57},
+			{Name: token.ROW, Val: "row", Pos: 65},
+			{Name: token.RPAREN, Val: ")", Pos: 68},
+			{Name: token.EOF, Pos: 69},
+		},
+	},
 }
 
 func TestLexer(t *testing.T) {
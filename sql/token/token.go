@@ -19,12 +19,20 @@ func (tok Token) IsKeyword() bool  { return keyword_beg < tok.Name && tok.Name <
 // Precedence implements Token.
 func (tok *Token) Precedence() int {
 	switch tok.Name {
-	case LSS, GTR, EQL, LEQ, GEQ:
+	case OR:
+		return 10
+	case AND:
+		return 20
+	case LSS, GTR, EQL, NEQ, LEQ, GEQ, IN, NOT:
 		return 40
 	case ADD, SUB:
 		return 50
 	case MUL, QUO:
 		return 60
+	case DCOLON:
+		return 70
+	case LBRACK, PERIOD:
+		return 80
 	}
 	return LowestPrec
 }
@@ -81,6 +89,7 @@ const (
 	RBRACE    // }
 	SEMICOLON // ;
 	COLON     // :
+	DCOLON    // ::
 	operator_end
 
 	keyword_beg
@@ -92,6 +101,56 @@ const (
 	AND
 	OR
 	NOT
+	IN
+	LIMIT
+	OFFSET
+	ORDER
+	BY
+	ASC
+	DESC
+	NULLS
+	JOIN
+	INNER
+	LEFT
+	RIGHT
+	FULL
+	OUTER
+	CROSS
+	ON
+	USING
+	UNION
+	INTERSECT
+	EXCEPT
+	ALL
+	ANY
+	SOME
+	DISTINCT
+	INSERT
+	INTO
+	CREATE
+	REPLACE
+	TABLE
+	VIEW
+	DROP
+	IF
+	EXISTS
+	NULL
+	CAST
+	TRY_CAST
+	CASE
+	WHEN
+	THEN
+	ELSE
+	END
+	STRUCT
+	ROW
+	MAP
+	OVER
+	PARTITION
+	ROWS
+	RANGE
+	BETWEEN
+	CURRENT
 	keyword_end
 )
 
@@ -134,14 +193,65 @@ var tokens = [...]string{
 	RBRACE:    "}",
 	SEMICOLON: ";",
 	COLON:     ":",
-
-	SELECT: "SELECT",
-	FROM:   "FROM",
-	WHERE:  "WHERE",
-	AS:     "AS",
-	AND:    "AND",
-	OR:     "OR",
-	NOT:    "NOT",
+	DCOLON:    "::",
+
+	SELECT:    "SELECT",
+	FROM:      "FROM",
+	WHERE:     "WHERE",
+	AS:        "AS",
+	AND:       "AND",
+	OR:        "OR",
+	NOT:       "NOT",
+	IN:        "IN",
+	LIMIT:     "LIMIT",
+	OFFSET:    "OFFSET",
+	ORDER:     "ORDER",
+	BY:        "BY",
+	ASC:       "ASC",
+	DESC:      "DESC",
+	NULLS:     "NULLS",
+	JOIN:      "JOIN",
+	INNER:     "INNER",
+	LEFT:      "LEFT",
+	RIGHT:     "RIGHT",
+	FULL:      "FULL",
+	OUTER:     "OUTER",
+	CROSS:     "CROSS",
+	ON:        "ON",
+	USING:     "USING",
+	UNION:     "UNION",
+	INTERSECT: "INTERSECT",
+	EXCEPT:    "EXCEPT",
+	ALL:       "ALL",
+	ANY:       "ANY",
+	SOME:      "SOME",
+	DISTINCT:  "DISTINCT",
+	INSERT:    "INSERT",
+	INTO:      "INTO",
+	CREATE:    "CREATE",
+	REPLACE:   "REPLACE",
+	TABLE:     "TABLE",
+	VIEW:      "VIEW",
+	DROP:      "DROP",
+	IF:        "IF",
+	EXISTS:    "EXISTS",
+	NULL:      "NULL",
+	CAST:      "CAST",
+	TRY_CAST:  "TRY_CAST",
+	CASE:      "CASE",
+	WHEN:      "WHEN",
+	THEN:      "THEN",
+	ELSE:      "ELSE",
+	END:       "END",
+	STRUCT:    "STRUCT",
+	ROW:       "ROW",
+	MAP:       "MAP",
+	OVER:      "OVER",
+	PARTITION: "PARTITION",
+	ROWS:      "ROWS",
+	RANGE:     "RANGE",
+	BETWEEN:   "BETWEEN",
+	CURRENT:   "CURRENT",
 }
 
 func (tok TokenName) String() string {
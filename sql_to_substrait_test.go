@@ -14,6 +14,7 @@ import (
 	"github.com/joellubi/bonobo/sql"
 
 	"github.com/stretchr/testify/require"
+	"github.com/substrait-io/substrait-go/v3/types"
 )
 
 var updateSQL = flag.Bool("update-sql", false, "update sql golden files")
@@ -38,6 +39,36 @@ func (*sqlTestCatalog) Schema(identifier []string) (*bonobo.Schema, error) {
 				{Name: "col5", Type: bonobo.Types.DateType(false)},
 			},
 		)
+	case "test_db.main.table2":
+		schema = bonobo.NewSchema(
+			[]bonobo.Field{
+				{Name: "col3", Type: bonobo.Types.Int64Type(false)},
+				{Name: "label", Type: bonobo.Types.StringType(true)},
+			},
+		)
+	case "test_db.main.events":
+		payload := &types.StructType{
+			Nullability: types.NullabilityNullable,
+			Types: []types.Type{
+				&types.StructType{
+					Nullability: types.NullabilityRequired,
+					Types:       []types.Type{bonobo.Types.StringType(false)},
+				},
+				&types.ListType{Nullability: types.NullabilityRequired, Type: bonobo.Types.StringType(false)},
+			},
+		}
+		attrs := &types.MapType{
+			Nullability: types.NullabilityRequired,
+			Key:         bonobo.Types.StringType(false),
+			Value:       bonobo.Types.StringType(false),
+		}
+		schema = bonobo.NewSchema(
+			[]bonobo.Field{
+				{Name: "id", Type: bonobo.Types.Int64Type(false)},
+				{Name: "payload", Type: payload, NestedNames: []string{"user", "name", "tags"}},
+				{Name: "attrs", Type: attrs},
+			},
+		)
 	default:
 		err = fmt.Errorf("table not found: %s", fqTableName)
 	}
@@ -87,6 +118,126 @@ var sqltestcases = []struct {
 		Name:  "alias_addition_expr",
 		Query: "SELECT 1 + 2 AS three",
 	},
+	{
+		Name:  "read_project_limit_offset",
+		Query: "SELECT col1, col2 FROM test_db.main.table1 LIMIT 10 OFFSET 5",
+	},
+	{
+		Name:  "read_project_order_by",
+		Query: "SELECT col1, col3 AS third FROM test_db.main.table1 ORDER BY 1 DESC, third NULLS FIRST LIMIT 10",
+	},
+	{
+		Name:  "read_order_by_unselected_column",
+		Query: "SELECT col1, col3 AS third FROM test_db.main.table1 ORDER BY col2 DESC, third",
+	},
+	{
+		Name:  "join_on_aliases",
+		Query: "SELECT t1.col2, t2.label FROM test_db.main.table1 t1 JOIN test_db.main.table2 AS t2 ON t1.col3 = t2.col3 AND t1.col1",
+	},
+	{
+		Name:  "left_join_using",
+		Query: "SELECT col2, label FROM test_db.main.table1 LEFT OUTER JOIN test_db.main.table2 USING (col3) WHERE col1",
+	},
+	{
+		Name:  "right_join_using",
+		Query: "SELECT col3, col2, label FROM test_db.main.table1 RIGHT JOIN test_db.main.table2 USING (col3)",
+	},
+	{
+		Name:  "join_using_qualified_columns",
+		Query: "SELECT t1.col3, t2.col3, t2.label FROM test_db.main.table1 t1 LEFT JOIN test_db.main.table2 t2 USING (col3) ORDER BY t1.col3",
+	},
+	{
+		Name:  "cross_join_comma_list",
+		Query: "SELECT table1.col2, t2.label FROM test_db.main.table1, test_db.main.table2 t2",
+	},
+	{
+		Name:  "full_join_subquery",
+		Query: "SELECT d.col3 FROM (SELECT col3 FROM test_db.main.table1) AS f FULL JOIN test_db.main.table2 d ON f.col3 = d.col3 ORDER BY d.col3",
+	},
+	{
+		Name:  "union_all_order_by_limit",
+		Query: "SELECT col3, col2 FROM test_db.main.table1 WHERE col1 UNION ALL SELECT col3, label FROM test_db.main.table2 UNION ALL SELECT col3, col2 FROM test_db.main.table1 ORDER BY col3 LIMIT 5",
+	},
+	{
+		Name:  "except_intersect",
+		Query: "SELECT col3 FROM test_db.main.table1 EXCEPT SELECT col3 FROM test_db.main.table2 INTERSECT SELECT col3 FROM test_db.main.table1",
+	},
+	{
+		Name:  "insert_into_select",
+		Query: "INSERT INTO test_db.main.table2 SELECT col3, col2 FROM test_db.main.table1 WHERE col1",
+	},
+	{
+		Name:  "create_table_as_select",
+		Query: "CREATE TABLE test_db.main.table3 AS SELECT col3, col2 AS label FROM test_db.main.table1",
+	},
+	{
+		Name:  "create_table",
+		Query: "CREATE TABLE test_db.main.table3 (id BIGINT NOT NULL, label VARCHAR(64), amount DECIMAL(12, 2))",
+	},
+	{
+		Name:  "create_view",
+		Query: "CREATE OR REPLACE VIEW test_db.main.view1 AS SELECT col3 FROM test_db.main.table1",
+	},
+	{
+		Name:  "drop_table",
+		Query: "DROP TABLE IF EXISTS test_db.main.table3",
+	},
+	{
+		Name:  "cast_string_to_date_and_decimal",
+		Query: "SELECT CAST(col2 AS DATE) AS d, TRY_CAST(col2 AS DECIMAL(10, 2)), col3::INT FROM test_db.main.table1 WHERE col5 = '2024-01-01'::date",
+	},
+	{
+		Name:  "case_when_buckets",
+		Query: "SELECT CASE WHEN col3 > 100 THEN 'high' WHEN col3 > 10 THEN 'mid' ELSE 'low' END AS bucket, CASE WHEN col1 THEN col3 END FROM test_db.main.table1",
+	},
+	{
+		Name:  "simple_case_switch",
+		Query: "SELECT CASE col3 WHEN 1 THEN 'one' WHEN 2 THEN 'two' ELSE 'many' END AS label FROM test_db.main.table1",
+	},
+	{
+		Name:  "filter_in_list",
+		Query: "SELECT col1 FROM test_db.main.table1 WHERE col3 IN (1, 2, 3) AND col2 NOT IN ('a', 'b')",
+	},
+	{
+		Name:  "filter_multi_in_list",
+		Query: "SELECT col1 FROM test_db.main.table1 WHERE (col3, col2) IN ((1, 'a'), (2, 'b'))",
+	},
+	{
+		Name:  "filter_correlated_exists",
+		Query: "SELECT col1 FROM test_db.main.table1 t WHERE EXISTS (SELECT col3 FROM test_db.main.table2 u WHERE u.col3 = t.col3)",
+	},
+	{
+		Name:  "filter_in_subquery",
+		Query: "SELECT col1 FROM test_db.main.table1 WHERE col3 NOT IN (SELECT col3 FROM test_db.main.table2)",
+	},
+	{
+		Name:  "filter_set_comparison_subquery",
+		Query: "SELECT col1 FROM test_db.main.table1 WHERE col3 > ALL (SELECT col3 FROM test_db.main.table2)",
+	},
+	{
+		Name:  "project_scalar_subquery",
+		Query: "SELECT col1, (SELECT label FROM test_db.main.table2 u WHERE u.col3 = t.col3) FROM test_db.main.table1 t",
+	},
+	{
+		Name:  "project_nested_constructors",
+		Query: "SELECT [col3, 1], STRUCT(col1, col2), ROW(col5), MAP('a', col3, 'b', 2) FROM test_db.main.table1",
+	},
+	{
+		Name:  "project_field_paths",
+		Query: "SELECT payload.user.name, e.payload.tags[1], attrs['source'] FROM test_db.main.events e WHERE attrs['kind'] = 'click'",
+	},
+	{
+		Name:  "project_subscript_nested_constructor",
+		Query: "SELECT [col3, 2][2], MAP('a', col1)['a'] FROM test_db.main.table1",
+	},
+	{
+		Name:  "project_map_integer_key_struct_field",
+		Query: "SELECT MAP(1, col2)[1], STRUCT(col1, col2).col2, [STRUCT(col3)][1].col3 FROM test_db.main.table1",
+	},
+	{
+		Name:  "project_window_functions",
+		Query: "SELECT col2, SUM(col3) OVER (PARTITION BY col2 ORDER BY col5 ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS running_total, rank() OVER (ORDER BY col3 DESC) FROM test_db.main.table1",
+	},
 }
 
 func TestSqlToSubstrait(t *testing.T) {
@@ -122,3 +273,28 @@ func TestSqlToSubstrait(t *testing.T) {
 		})
 	}
 }
+
+func TestSqlAmbiguousColumn(t *testing.T) {
+	plan, err := sql.Parse("SELECT col3 FROM test_db.main.table1 JOIN test_db.main.table2 ON table1.col3 = table2.col3")
+	require.NoError(t, err)
+
+	engine.SetCatalogForPlan(plan, &sqlTestCatalog{})
+
+	_, err = engine.FormatPlan(plan)
+	require.ErrorContains(t, err, "ambiguous reference to field: col3")
+}
+
+func TestSqlInsertSchemaMismatch(t *testing.T) {
+	for query, msg := range map[string]string{
+		"INSERT INTO test_db.main.table2 SELECT col3 FROM test_db.main.table1":       "cannot write 1 fields to table test_db.main.table2 with 2 fields",
+		"INSERT INTO test_db.main.table2 SELECT col3, col1 FROM test_db.main.table1": "cannot write field col1 of type boolean to field label of type string?",
+	} {
+		plan, err := sql.Parse(query)
+		require.NoError(t, err)
+
+		engine.SetCatalogForPlan(plan, &sqlTestCatalog{})
+
+		_, err = engine.FormatPlan(plan)
+		require.ErrorContains(t, err, msg, query)
+	}
+}
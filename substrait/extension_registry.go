@@ -13,13 +13,23 @@ type ExtensionRegistry struct {
 }
 
 func (reg *ExtensionRegistry) RegisterFunction(uri, signature string) uint32 {
+	return reg.register(Extension{URI: uri, Name: signature, Kind: ExtensionKindFunction})
+}
+
+// RegisterType registers the type named name in the extension at uri, and
+// returns the anchor that the plan declares it with.
+func (reg *ExtensionRegistry) RegisterType(uri, name string) uint32 {
+	return reg.register(Extension{URI: uri, Name: name, Kind: ExtensionKindType})
+}
+
+func (reg *ExtensionRegistry) register(ext Extension) uint32 {
 	index := slices.IndexFunc(reg.extensions, func(e Extension) bool {
-		return functionUniqueIdentifier(e.URI, e.Name) == functionUniqueIdentifier(uri, signature)
+		return e.Kind == ext.Kind && extensionUniqueIdentifier(e.URI, e.Name) == extensionUniqueIdentifier(ext.URI, ext.Name)
 	})
 
 	// If not found, add the new extension and set index to its offset
 	if index < 0 {
-		reg.extensions = append(reg.extensions, Extension{URI: uri, Name: signature, Kind: ExtensionKindFunction})
+		reg.extensions = append(reg.extensions, ext)
 		index = len(reg.extensions) - 1
 	}
 
@@ -74,17 +84,29 @@ func (reg *ExtensionRegistry) ToProto() ([]*extensions.SimpleExtensionURI, []*ex
 
 	protoExt := make([]*extensions.SimpleExtensionDeclaration, len(extDecls))
 	for i, ext := range extDecls {
-		if ext.Kind != ExtensionKindFunction {
-			return nil, nil, fmt.Errorf("serialization unimplemented: ExtensionKind %d", ext.Kind)
-		}
-		protoExt[i] = &extensions.SimpleExtensionDeclaration{
-			MappingType: &extensions.SimpleExtensionDeclaration_ExtensionFunction_{
-				ExtensionFunction: &extensions.SimpleExtensionDeclaration_ExtensionFunction{
-					ExtensionUriReference: ext.Reference,
-					FunctionAnchor:        ext.Anchor,
-					Name:                  ext.Name,
+		switch ext.Kind {
+		case ExtensionKindFunction:
+			protoExt[i] = &extensions.SimpleExtensionDeclaration{
+				MappingType: &extensions.SimpleExtensionDeclaration_ExtensionFunction_{
+					ExtensionFunction: &extensions.SimpleExtensionDeclaration_ExtensionFunction{
+						ExtensionUriReference: ext.Reference,
+						FunctionAnchor:        ext.Anchor,
+						Name:                  ext.Name,
+					},
 				},
-			},
+			}
+		case ExtensionKindType:
+			protoExt[i] = &extensions.SimpleExtensionDeclaration{
+				MappingType: &extensions.SimpleExtensionDeclaration_ExtensionType_{
+					ExtensionType: &extensions.SimpleExtensionDeclaration_ExtensionType{
+						ExtensionUriReference: ext.Reference,
+						TypeAnchor:            ext.Anchor,
+						Name:                  ext.Name,
+					},
+				},
+			}
+		default:
+			return nil, nil, fmt.Errorf("serialization unimplemented: ExtensionKind %d", ext.Kind)
 		}
 	}
 
@@ -115,7 +137,7 @@ func NewExtensionRegistryFromProto(plan *proto.Plan) (ExtensionRegistry, error)
 			if !found {
 				return reg, fmt.Errorf("unable to resolve extension URI reference for %s", t)
 			}
-			return reg, fmt.Errorf("cannot register ExtensionType %s/%s: unimplemented", URI, name)
+			reg.RegisterType(URI, name)
 		case *extensions.SimpleExtensionDeclaration_ExtensionTypeVariation_:
 			name := t.ExtensionTypeVariation.Name
 			URI, found := uriByRef[t.ExtensionTypeVariation.ExtensionUriReference]
@@ -156,6 +178,6 @@ type Extension struct {
 	Kind      ExtensionKind
 }
 
-func functionUniqueIdentifier(uri, signature string) string {
-	return fmt.Sprintf("%s/%s", uri, signature)
+func extensionUniqueIdentifier(uri, name string) string {
+	return fmt.Sprintf("%s/%s", uri, name)
 }
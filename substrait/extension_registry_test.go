@@ -6,6 +6,7 @@ import (
 	"github.com/joellubi/bonobo/substrait"
 
 	"github.com/stretchr/testify/require"
+	"github.com/substrait-io/substrait-go/v3/proto"
 )
 
 func TestExtensionRegistry(t *testing.T) {
@@ -90,3 +91,24 @@ func TestExtensionRegistry(t *testing.T) {
 	require.Equal(t, expectedURIs, URIs)
 	require.Equal(t, expectedExtensions, extensions)
 }
+
+func TestExtensionRegistryTypes(t *testing.T) {
+	var reg substrait.ExtensionRegistry
+
+	require.Equal(t, uint32(1), reg.RegisterFunction("example.com/point.yaml", "distance:point_point"))
+	require.Equal(t, uint32(2), reg.RegisterType("example.com/point.yaml", "point"))
+	require.Equal(t, uint32(2), reg.RegisterType("example.com/point.yaml", "point"))
+	// Types and functions with the same name are different extensions
+	require.Equal(t, uint32(3), reg.RegisterFunction("example.com/point.yaml", "point"))
+
+	URIs, decls, err := reg.ToProto()
+	require.NoError(t, err)
+
+	roundTrip, err := substrait.NewExtensionRegistryFromProto(&proto.Plan{ExtensionUris: URIs, Extensions: decls})
+	require.NoError(t, err)
+
+	ext, uri, err := roundTrip.GetExtensionByReference(2)
+	require.NoError(t, err)
+	require.Equal(t, "example.com/point.yaml", uri)
+	require.Equal(t, substrait.ExtensionDeclaration{Reference: 1, Anchor: 2, Name: "point", Kind: substrait.ExtensionKindType}, ext)
+}
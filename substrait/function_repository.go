@@ -40,6 +40,43 @@ type FunctionImplementation interface {
 	ReturnType(inputs ...bonobo.Type) (typ bonobo.Type, err error)
 }
 
+// AggregateFunctionImplementation is a FunctionImplementation that combines
+// many input records into a single value.
+type AggregateFunctionImplementation interface {
+	FunctionImplementation
+	IntermediateType(inputs ...bonobo.Type) (typ bonobo.Type, err error)
+	Decomposability() Decomposability
+	Ordered() bool
+	MaxSet() int
+}
+
+// Decomposability describes whether an aggregate function can be computed
+// in multiple phases, passing intermediate results between them.
+type Decomposability string
+
+const (
+	DecomposableNone Decomposability = Decomposability(extensions.DecomposeNone)
+	DecomposableOne  Decomposability = Decomposability(extensions.DecomposeOne)
+	DecomposableMany Decomposability = Decomposability(extensions.DecomposeMany)
+)
+
+// WindowFunctionImplementation is an AggregateFunctionImplementation that is
+// computed over the window of records around each input record, rather than
+// combining all of them into a single value.
+type WindowFunctionImplementation interface {
+	AggregateFunctionImplementation
+	WindowType() WindowType
+}
+
+// WindowType describes whether a window function can be computed as records
+// stream through it, or needs all the records of a partition at once.
+type WindowType string
+
+const (
+	WindowTypeStreaming WindowType = WindowType(extensions.StreamingWindow)
+	WindowTypePartition WindowType = WindowType(extensions.PartitionWindow)
+)
+
 type FunctionDeclaration interface {
 	Implementations() ([]FunctionImplementation, error)
 }
@@ -103,15 +140,40 @@ func RegisterImplementationsFromURI(repo *functionRepository, uri string, getter
 		return err
 	}
 
-	impls, err := ReadScalarFunctionImplementations(r, uri)
+	defer r.Close()
+
+	simpleExtensions, err := readSimpleExtensionFile(r)
 	if err != nil {
 		return err
 	}
 
-	for _, impl := range impls {
+	scalarImpls, err := scalarFunctionVariants(simpleExtensions, uri)
+	if err != nil {
+		return err
+	}
+
+	for _, impl := range scalarImpls {
 		repo.RegisterImplementation(uri, impl.Name(), &variantFunctionImplementation{variant: impl})
 	}
 
+	aggregateImpls, err := aggregateFunctionVariants(simpleExtensions, uri)
+	if err != nil {
+		return err
+	}
+
+	for _, impl := range aggregateImpls {
+		repo.RegisterImplementation(uri, impl.Name(), &aggregateVariantFunctionImplementation{variant: impl})
+	}
+
+	windowImpls, err := windowFunctionVariants(simpleExtensions, uri)
+	if err != nil {
+		return err
+	}
+
+	for _, impl := range windowImpls {
+		repo.RegisterImplementation(uri, impl.Name(), newWindowVariantFunctionImplementation(impl))
+	}
+
 	return nil
 }
 
@@ -169,7 +231,117 @@ func (impl *variantFunctionImplementation) Signature() string {
 	return impl.variant.CompoundName()
 }
 
+// aggregateFunctionVariant is the part of a simple extension variant shared by
+// aggregate and window functions.
+type aggregateFunctionVariant interface {
+	extensions.FunctionVariant
+	Nullability() extensions.NullabilityHandling
+	Intermediate() (types.FuncDefArgType, error)
+	Decomposability() extensions.DecomposeType
+	Ordered() bool
+	MaxSet() int
+}
+
+type aggregateVariantFunctionImplementation struct {
+	variant aggregateFunctionVariant
+}
+
+// Name implements AggregateFunctionImplementation.
+func (impl *aggregateVariantFunctionImplementation) Name() string {
+	return impl.variant.Name()
+}
+
+// ReturnType implements AggregateFunctionImplementation.
+func (impl *aggregateVariantFunctionImplementation) ReturnType(inputs ...bonobo.Type) (typ bonobo.Type, err error) {
+	types := make([]types.Type, len(inputs))
+	for i, arg := range inputs {
+		types[i] = arg
+	}
+
+	return impl.variant.ResolveType(types)
+}
+
+// Signature implements AggregateFunctionImplementation.
+func (impl *aggregateVariantFunctionImplementation) Signature() string {
+	return impl.variant.CompoundName()
+}
+
+// IntermediateType implements AggregateFunctionImplementation.
+func (impl *aggregateVariantFunctionImplementation) IntermediateType(inputs ...bonobo.Type) (typ bonobo.Type, err error) {
+	intermediate, err := impl.variant.Intermediate()
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]types.Type, len(inputs))
+	for i, arg := range inputs {
+		types[i] = arg
+	}
+
+	return extensions.EvaluateTypeExpression(impl.variant.Nullability(), intermediate, impl.variant.Args(), impl.variant.Variadic(), types)
+}
+
+// Decomposability implements AggregateFunctionImplementation.
+func (impl *aggregateVariantFunctionImplementation) Decomposability() Decomposability {
+	return Decomposability(impl.variant.Decomposability())
+}
+
+// Ordered implements AggregateFunctionImplementation.
+func (impl *aggregateVariantFunctionImplementation) Ordered() bool {
+	return impl.variant.Ordered()
+}
+
+// MaxSet implements AggregateFunctionImplementation.
+func (impl *aggregateVariantFunctionImplementation) MaxSet() int {
+	return impl.variant.MaxSet()
+}
+
+type windowVariantFunctionImplementation struct {
+	aggregateVariantFunctionImplementation
+
+	windowType WindowType
+}
+
+func newWindowVariantFunctionImplementation(variant *extensions.WindowFunctionVariant) *windowVariantFunctionImplementation {
+	return &windowVariantFunctionImplementation{
+		aggregateVariantFunctionImplementation: aggregateVariantFunctionImplementation{variant: variant},
+		windowType:                             WindowType(variant.WindowType()),
+	}
+}
+
+// WindowType implements WindowFunctionImplementation.
+func (impl *windowVariantFunctionImplementation) WindowType() WindowType {
+	return impl.windowType
+}
+
 func ReadScalarFunctionImplementations(r io.Reader, uri string) ([]*extensions.ScalarFunctionVariant, error) {
+	simpleExtensions, err := readSimpleExtensionFile(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return scalarFunctionVariants(simpleExtensions, uri)
+}
+
+func ReadAggregateFunctionImplementations(r io.Reader, uri string) ([]*extensions.AggregateFunctionVariant, error) {
+	simpleExtensions, err := readSimpleExtensionFile(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregateFunctionVariants(simpleExtensions, uri)
+}
+
+func ReadWindowFunctionImplementations(r io.Reader, uri string) ([]*extensions.WindowFunctionVariant, error) {
+	simpleExtensions, err := readSimpleExtensionFile(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return windowFunctionVariants(simpleExtensions, uri)
+}
+
+func readSimpleExtensionFile(r io.Reader) (*extensions.SimpleExtensionFile, error) {
 	var (
 		buf              bytes.Buffer
 		simpleExtensions extensions.SimpleExtensionFile
@@ -183,20 +355,30 @@ func ReadScalarFunctionImplementations(r io.Reader, uri string) ([]*extensions.S
 		return nil, err
 	}
 
-	// implementations := make([]FunctionImplementation, 0)
-	variants := make([]*extensions.ScalarFunctionVariant, 0)
-	for _, scalarFunc := range simpleExtensions.ScalarFunctions {
+	return &simpleExtensions, nil
+}
+
+func scalarFunctionVariants(simpleExtensions *extensions.SimpleExtensionFile, uri string) ([]*extensions.ScalarFunctionVariant, error) {
+	return functionVariants(simpleExtensions.ScalarFunctions, uri, (*extensions.ScalarFunction).GetVariants)
+}
+
+func aggregateFunctionVariants(simpleExtensions *extensions.SimpleExtensionFile, uri string) ([]*extensions.AggregateFunctionVariant, error) {
+	return functionVariants(simpleExtensions.AggregateFunctions, uri, (*extensions.AggregateFunction).GetVariants)
+}
+
+func windowFunctionVariants(simpleExtensions *extensions.SimpleExtensionFile, uri string) ([]*extensions.WindowFunctionVariant, error) {
+	return functionVariants(simpleExtensions.WindowFunctions, uri, (*extensions.WindowFunction).GetVariants)
+}
+
+func functionVariants[F any, V any](funcs []F, uri string, getVariants func(*F, string) []V) ([]V, error) {
+	variants := make([]V, 0)
+	for _, fn := range funcs {
 		// TODO: Avoid using defaults package, potentially just use upstream Collection
-		if err := defaults.Set(&scalarFunc); err != nil {
+		if err := defaults.Set(&fn); err != nil {
 			return nil, err
 		}
 
-		variants = append(variants, scalarFunc.GetVariants(uri)...)
-		// impls, err := scalarFunc.Implementations()
-		// if err != nil {
-		// 	return nil, err
-		// }
-		// implementations = append(implementations, impls...)
+		variants = append(variants, getVariants(&fn, uri)...)
 	}
 
 	return variants, nil
@@ -264,3 +446,5 @@ var _ FunctionRepository = (*functionRepository)(nil)
 var _ FunctionRepository = (*anonymousRepository)(nil)
 var _ FunctionImplementation = (*anonymousFunctionImplementation)(nil)
 var _ FunctionImplementation = (*variantFunctionImplementation)(nil)
+var _ AggregateFunctionImplementation = (*aggregateVariantFunctionImplementation)(nil)
+var _ WindowFunctionImplementation = (*windowVariantFunctionImplementation)(nil)
@@ -63,13 +63,95 @@ func TestGetLocalFunctionImplementations(t *testing.T) {
 	require.Equal(t, bonobo.Types.Int64Type(false), ret)
 }
 
+func TestGetLocalAggregateFunctionImplementations(t *testing.T) {
+	dir, err := os.Getwd()
+	require.NoError(t, err)
+
+	uri := "file://" + path.Join(dir, "testdata/extensions/functions.yaml")
+	repo := substrait.NewFunctionRepository()
+
+	require.NoError(t, substrait.RegisterImplementationsFromURI(repo, uri))
+
+	args := []bonobo.Type{bonobo.Types.Int64Type(false)}
+	impl, err := repo.GetImplementation(uri, "sum", args...)
+	require.NoError(t, err)
+
+	require.Equal(t, "sum:i64", impl.Signature())
+
+	aggImpl, ok := impl.(substrait.AggregateFunctionImplementation)
+	require.True(t, ok)
+
+	ret, err := aggImpl.ReturnType(args...)
+	require.NoError(t, err)
+	require.Equal(t, bonobo.Types.Int64Type(true), ret)
+
+	intermediate, err := aggImpl.IntermediateType(args...)
+	require.NoError(t, err)
+	require.Equal(t, bonobo.Types.Int64Type(true), intermediate)
+
+	require.Equal(t, substrait.DecomposableMany, aggImpl.Decomposability())
+	require.False(t, aggImpl.Ordered())
+}
+
+func TestReadAggregateFunctionImplementations(t *testing.T) {
+	f, err := os.Open("testdata/extensions/functions.yaml")
+	require.NoError(t, err)
+	defer f.Close()
+
+	variants, err := substrait.ReadAggregateFunctionImplementations(f, "https://example.com/functions.yaml")
+	require.NoError(t, err)
+	require.Len(t, variants, 1)
+
+	require.Equal(t, "sum:i64", variants[0].CompoundName())
+	require.Equal(t, "https://example.com/functions.yaml", variants[0].URI())
+}
+
+func TestGetLocalWindowFunctionImplementations(t *testing.T) {
+	dir, err := os.Getwd()
+	require.NoError(t, err)
+
+	uri := "file://" + path.Join(dir, "testdata/extensions/functions.yaml")
+	repo := substrait.NewFunctionRepository()
+
+	require.NoError(t, substrait.RegisterImplementationsFromURI(repo, uri))
+
+	args := []bonobo.Type{bonobo.Types.Int64Type(false)}
+	impl, err := repo.GetImplementation(uri, "lag", args...)
+	require.NoError(t, err)
+
+	require.Equal(t, "lag:i64", impl.Signature())
+
+	windowImpl, ok := impl.(substrait.WindowFunctionImplementation)
+	require.True(t, ok)
+
+	ret, err := windowImpl.ReturnType(args...)
+	require.NoError(t, err)
+	require.Equal(t, bonobo.Types.Int64Type(true), ret)
+
+	require.Equal(t, substrait.WindowTypePartition, windowImpl.WindowType())
+	require.Equal(t, substrait.DecomposableNone, windowImpl.Decomposability())
+}
+
+func TestReadWindowFunctionImplementations(t *testing.T) {
+	f, err := os.Open("testdata/extensions/functions.yaml")
+	require.NoError(t, err)
+	defer f.Close()
+
+	variants, err := substrait.ReadWindowFunctionImplementations(f, "https://example.com/functions.yaml")
+	require.NoError(t, err)
+	require.Len(t, variants, 1)
+
+	require.Equal(t, "lag:i64", variants[0].CompoundName())
+}
+
 func TestGetDefaultFunctionImplementations(t *testing.T) {
 	uri := "https://github.com/substrait-io/substrait/blob/main/extensions/functions_arithmetic.yaml"
 	repo := substrait.NewFunctionRepository()
 
 	require.NoError(t, substrait.RegisterImplementationsFromURI(repo, uri))
 
-	assert.Len(t, repo.FunctionsForURI(uri), 31)
+	// 31 scalar functions, 12 aggregate functions and 11 window functions
+	assert.Len(t, repo.FunctionsForURI(uri), 31+12+11)
 
 	// TODO: assertions on contents
 }